@@ -4,12 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/TBuckholz5/workouttracker/internal/config"
+	analyticsApi "github.com/TBuckholz5/workouttracker/internal/domains/analytics/api/v1"
+	analyticsRepo "github.com/TBuckholz5/workouttracker/internal/domains/analytics/repository"
+	analyticsServ "github.com/TBuckholz5/workouttracker/internal/domains/analytics/service"
 	exerciseApi "github.com/TBuckholz5/workouttracker/internal/domains/exercise/api/v1"
+	exercisePolicy "github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
 	exerciseRepo "github.com/TBuckholz5/workouttracker/internal/domains/exercise/repository"
 	exerciseServ "github.com/TBuckholz5/workouttracker/internal/domains/exercise/service"
+	measurementsApi "github.com/TBuckholz5/workouttracker/internal/domains/measurements/api/v1"
+	measurementsRepo "github.com/TBuckholz5/workouttracker/internal/domains/measurements/repository"
+	measurementsServ "github.com/TBuckholz5/workouttracker/internal/domains/measurements/service"
+	routineApi "github.com/TBuckholz5/workouttracker/internal/domains/routine/api/v1"
+	routineRepo "github.com/TBuckholz5/workouttracker/internal/domains/routine/repository"
+	routineServ "github.com/TBuckholz5/workouttracker/internal/domains/routine/service"
 	userApi "github.com/TBuckholz5/workouttracker/internal/domains/user/api/v1"
 	userRepo "github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
 	userServ "github.com/TBuckholz5/workouttracker/internal/domains/user/service"
@@ -20,11 +32,18 @@ import (
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware"
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/logging"
+	metricsMiddleware "github.com/TBuckholz5/workouttracker/internal/routing/middleware/metrics"
 	"github.com/TBuckholz5/workouttracker/internal/util/hash"
 	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
+	"github.com/TBuckholz5/workouttracker/internal/util/mailer"
+	"github.com/TBuckholz5/workouttracker/internal/util/metrics"
+	"github.com/TBuckholz5/workouttracker/internal/util/oidc"
+	"github.com/TBuckholz5/workouttracker/internal/util/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
@@ -33,6 +52,11 @@ func main() {
 	if err != nil {
 		log.Fatal("Cannot load config:", err)
 	}
+	appLogger, err := logger.New(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(appLogger)
 
 	// Connect to database.
 	pool, err := pgxpool.New(context.Background(), fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
@@ -41,6 +65,7 @@ func main() {
 		log.Fatal(err)
 	}
 	defer pool.Close()
+	prometheus.MustRegister(metrics.NewPoolCollector(pool))
 
 	// Run migration.
 	db := stdlib.OpenDBFromPool(pool)
@@ -50,26 +75,42 @@ func main() {
 	}
 
 	// Define dependencies.
-	jwtService := jwt.NewJwtService([]byte(config.JWTSecret))
-	authMiddleware := auth.NewAuthMiddleware(jwtService)
-	loggingMiddleware := logging.NewLoggingMiddleware()
+	jwtKeys, err := newJwtKeySet(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtService := jwt.NewJwtServiceWithKeys(jwtKeys)
+
+	userMailer, err := newMailer(config)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	userRepository := userRepo.NewRepository(pool)
-	userService := userServ.NewService(userRepository, hash.NewBcryptHasher(), jwtService)
+	userService := userServ.NewService(userRepository, hash.NewBcryptHasher(), jwtService, userMailer, config.AppURL, newOIDCProviders(config))
 	userHandler := userApi.NewHandler(userService)
+	authMiddleware := auth.NewAuthMiddleware(jwtService, userService, userService)
+	// Account management needs a login; personal access tokens are refused.
+	sessionAuth := []middleware.Middleware{auth.NewSessionOnlyMiddleware(), authMiddleware}
 
 	// Register routes.
 	mux := http.NewServeMux()
 
+	routing.RegisterRoute(routing.Config{
+		Mux:     mux,
+		Handler: jwt.JWKSHandler(jwtKeys),
+		Route:   "/.well-known/jwks.json",
+		Method:  "GET",
+	})
+
 	apiMux := routing.RegisterRouterGroup(routing.Config{
 		Mux:        mux,
 		GroupRoute: "/api/v1/",
 	})
 
 	userMux := routing.RegisterRouterGroup(routing.Config{
-		Mux:         apiMux,
-		Middlewares: []middleware.Middleware{loggingMiddleware},
-		GroupRoute:  "/user/",
+		Mux:        apiMux,
+		GroupRoute: "/user/",
 	})
 	routing.RegisterRoute(routing.Config{
 		Mux:     userMux,
@@ -83,13 +124,145 @@ func main() {
 		Route:   "/login",
 		Method:  "POST",
 	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.LoginTwoFactor),
+		Route:   "/login/2fa",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.StartOIDCLogin),
+		Route:   "/oidc/{provider}/start",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.OIDCCallback),
+		Route:   "/oidc/{provider}/callback",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.Refresh),
+		Route:   "/refresh",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.Logout),
+		Middlewares: sessionAuth,
+		Route:       "/logout",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.LogoutAll),
+		Middlewares: sessionAuth,
+		Route:       "/logout-all",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.VerifyEmail),
+		Route:   "/verify-email",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.ResendVerification),
+		Middlewares: sessionAuth,
+		Route:       "/verify-email/resend",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.ForgotPassword),
+		Route:   "/forgot-password",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     userMux,
+		Handler: http.HandlerFunc(userHandler.ResetPassword),
+		Route:   "/reset-password",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.ChangePassword),
+		Middlewares: sessionAuth,
+		Route:       "/change-password",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.EnrollTOTP),
+		Middlewares: sessionAuth,
+		Route:       "/2fa/enroll",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.ConfirmTOTP),
+		Middlewares: sessionAuth,
+		Route:       "/2fa/confirm",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.DisableTOTP),
+		Middlewares: sessionAuth,
+		Route:       "/2fa/disable",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.CreateAccessToken),
+		Middlewares: sessionAuth,
+		Route:       "/tokens",
+		Method:      "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.ListAccessTokens),
+		Middlewares: sessionAuth,
+		Route:       "/tokens",
+		Method:      "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.RevokeAccessToken),
+		Middlewares: sessionAuth,
+		Route:       "/tokens/{id}",
+		Method:      "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.GetProfile),
+		Middlewares: sessionAuth,
+		Route:       "/profile",
+		Method:      "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:         userMux,
+		Handler:     http.HandlerFunc(userHandler.UpdateProfile),
+		Middlewares: sessionAuth,
+		Route:       "/profile",
+		Method:      "PATCH",
+	})
+
+	imageStorage, err := newStorage(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mediaSigner := storage.NewURLSigner([]byte(config.MediaURLSecret), "/api/v1/media/", config.MediaURLTTL)
 
 	exerciseRepository := exerciseRepo.NewRepository(pool)
-	exerciseService := exerciseServ.NewService(exerciseRepository)
-	exerciseHandler := exerciseApi.NewHandler(exerciseService)
+	exerciseService := exerciseServ.NewService(exerciseRepository, imageStorage)
+	exerciseHandler := exerciseApi.NewHandler(exerciseService, mediaSigner)
 	exerciseMux := routing.RegisterRouterGroup(routing.Config{
 		Mux:         apiMux,
-		Middlewares: []middleware.Middleware{loggingMiddleware, authMiddleware},
+		Middlewares: []middleware.Middleware{auth.NewScopeMiddleware("exercises"), authMiddleware},
 		GroupRoute:  "/exercise/",
 	})
 	routing.RegisterRoute(routing.Config{
@@ -104,13 +277,55 @@ func main() {
 		Route:   "/getForUser",
 		Method:  "GET",
 	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     exerciseMux,
+		Handler: http.HandlerFunc(exerciseHandler.GetExercise),
+		Route:   "/{id}",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     exerciseMux,
+		Handler: http.HandlerFunc(exerciseHandler.UpdateExercise),
+		Route:   "/{id}",
+		Method:  "PATCH",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     exerciseMux,
+		Handler: http.HandlerFunc(exerciseHandler.DeleteExercise),
+		Route:   "/{id}",
+		Method:  "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     exerciseMux,
+		Handler: http.HandlerFunc(exerciseHandler.MergeExercise),
+		Route:   "/{id}/merge",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     exerciseMux,
+		Handler: http.HandlerFunc(exerciseHandler.UploadImage),
+		Route:   "/{id}/image",
+		Method:  "POST",
+	})
+
+	// Media links are signed, so they are served without the auth middleware.
+	mediaMux := routing.RegisterRouterGroup(routing.Config{
+		Mux:        apiMux,
+		GroupRoute: "/media/",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     mediaMux,
+		Handler: http.HandlerFunc(exerciseHandler.ServeImage),
+		Route:   "/{key...}",
+		Method:  "GET",
+	})
 
 	workoutSessionRepository := workoutSessionRepo.NewRepository(pool)
-	workoutSessionService := workoutSessionServ.NewService(workoutSessionRepository)
-	workoutSessionHandler := workoutSessionApi.NewHandler(workoutSessionService)
+	workoutSessionService := workoutSessionServ.NewService(workoutSessionRepository, exercisePolicy.NewPolicy(exerciseRepository))
+	workoutSessionHandler := workoutSessionApi.NewHandler(workoutSessionService, userService)
 	workoutSessionMux := routing.RegisterRouterGroup(routing.Config{
 		Mux:         apiMux,
-		Middlewares: []middleware.Middleware{loggingMiddleware, authMiddleware},
+		Middlewares: []middleware.Middleware{auth.NewScopeMiddleware("sessions"), authMiddleware},
 		GroupRoute:  "/workoutsession/",
 	})
 	routing.RegisterRoute(routing.Config{
@@ -119,10 +334,328 @@ func main() {
 		Route:   "/create",
 		Method:  "POST",
 	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.Start),
+		Route:   "/start",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.GetActive),
+		Route:   "/active",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ActiveSession(workoutSessionHandler.AddWorkout)),
+		Route:   "/active/workouts",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ActiveSession(workoutSessionHandler.AddSet)),
+		Route:   "/active/workouts/{workoutID}/sets",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ActiveSession(workoutSessionHandler.UpdateSet)),
+		Route:   "/active/workouts/{workoutID}/sets/{setID}",
+		Method:  "PATCH",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ActiveSession(workoutSessionHandler.DeleteSet)),
+		Route:   "/active/workouts/{workoutID}/sets/{setID}",
+		Method:  "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ActiveSession(workoutSessionHandler.Finish)),
+		Route:   "/active/finish",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.Finish),
+		Route:   "/{id}/finish",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.List),
+		Route:   "/history",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ListPersonalRecords),
+		Route:   "/records/{exerciseID}",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.GetByID),
+		Route:   "/{id}",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.UpdateSession),
+		Route:   "/{id}",
+		Method:  "PATCH",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.DeleteSession),
+		Route:   "/{id}",
+		Method:  "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.AddWorkout),
+		Route:   "/{id}/workouts",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.DeleteWorkout),
+		Route:   "/{id}/workouts/{workoutID}",
+		Method:  "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.AddSet),
+		Route:   "/{id}/workouts/{workoutID}/sets",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.ReorderSets),
+		Route:   "/{id}/workouts/{workoutID}/sets/order",
+		Method:  "PUT",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.UpdateSet),
+		Route:   "/{id}/workouts/{workoutID}/sets/{setID}",
+		Method:  "PATCH",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     workoutSessionMux,
+		Handler: http.HandlerFunc(workoutSessionHandler.DeleteSet),
+		Route:   "/{id}/workouts/{workoutID}/sets/{setID}",
+		Method:  "DELETE",
+	})
+
+	routineRepository := routineRepo.NewRepository(pool)
+	routineService := routineServ.NewService(routineRepository, workoutSessionService, exercisePolicy.NewPolicy(exerciseRepository))
+	routineHandler := routineApi.NewHandler(routineService, userService)
+	routineMux := routing.RegisterRouterGroup(routing.Config{
+		Mux:         apiMux,
+		Middlewares: []middleware.Middleware{auth.NewScopeMiddleware("routines"), authMiddleware},
+		GroupRoute:  "/routine/",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.Create),
+		Route:   "/create",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.CreateFromSession),
+		Route:   "/fromSession",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.List),
+		Route:   "/list",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.GetByID),
+		Route:   "/{id}",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.Update),
+		Route:   "/{id}",
+		Method:  "PUT",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.Delete),
+		Route:   "/{id}",
+		Method:  "DELETE",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     routineMux,
+		Handler: http.HandlerFunc(routineHandler.StartSession),
+		Route:   "/{id}/start",
+		Method:  "POST",
+	})
+
+	analyticsRepository := analyticsRepo.NewRepository(pool)
+	analyticsService := analyticsServ.NewService(analyticsRepository)
+	analyticsHandler := analyticsApi.NewHandler(analyticsService, userService)
+	analyticsMux := routing.RegisterRouterGroup(routing.Config{
+		Mux:         apiMux,
+		Middlewares: []middleware.Middleware{auth.NewScopeMiddleware("analytics"), authMiddleware},
+		GroupRoute:  "/analytics/",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     analyticsMux,
+		Handler: http.HandlerFunc(analyticsHandler.GetExerciseProgression),
+		Route:   "/exercise/{exerciseID}/progression",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     analyticsMux,
+		Handler: http.HandlerFunc(analyticsHandler.GetMuscleVolume),
+		Route:   "/muscle-volume",
+		Method:  "GET",
+	})
+
+	measurementsRepository := measurementsRepo.NewRepository(pool)
+	measurementsService := measurementsServ.NewService(measurementsRepository, userService)
+	measurementsHandler := measurementsApi.NewHandler(measurementsService)
+	measurementsMux := routing.RegisterRouterGroup(routing.Config{
+		Mux:         apiMux,
+		Middlewares: []middleware.Middleware{auth.NewScopeMiddleware("measurements"), authMiddleware},
+		GroupRoute:  "/measurements/",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     measurementsMux,
+		Handler: http.HandlerFunc(measurementsHandler.Create),
+		Route:   "/create",
+		Method:  "POST",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     measurementsMux,
+		Handler: http.HandlerFunc(measurementsHandler.List),
+		Route:   "/list",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     measurementsMux,
+		Handler: http.HandlerFunc(measurementsHandler.GetTrend),
+		Route:   "/trend",
+		Method:  "GET",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     measurementsMux,
+		Handler: http.HandlerFunc(measurementsHandler.Update),
+		Route:   "/{id}",
+		Method:  "PATCH",
+	})
+	routing.RegisterRoute(routing.Config{
+		Mux:     measurementsMux,
+		Handler: http.HandlerFunc(measurementsHandler.Delete),
+		Route:   "/{id}",
+		Method:  "DELETE",
+	})
+
+	metricsHandler := metrics.Handler(config.MetricsToken)
+	if config.MetricsPort == 0 {
+		routing.RegisterRoute(routing.Config{
+			Mux:     mux,
+			Handler: metricsHandler,
+			Route:   "/metrics",
+			Method:  "GET",
+		})
+	} else {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("GET /metrics", metricsHandler)
+		go func() {
+			log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.MetricsPort), adminMux))
+		}()
+	}
 
 	// Start server.
 	fmt.Println("Starting server on port", config.ServerPort)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", config.ServerPort), mux); err != nil {
+	// Metrics read the route pattern recorded for logging, so logging wraps them.
+	handler := logging.NewLoggingMiddleware(appLogger).Wrap(
+		metricsMiddleware.NewMetricsMiddleware(prometheus.DefaultRegisterer).Wrap(mux))
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", config.ServerPort), handler); err != nil {
 		log.Fatal(err)
 	}
 }
+
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return storage.NewLocalStorage(cfg.StorageDir)
+	case "s3":
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mailer {
+	case "log":
+		return mailer.NewLogMailer(), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
+
+func newOIDCProviders(cfg *config.Config) map[string]userServ.OIDCProvider {
+	providers := make(map[string]userServ.OIDCProvider, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		providers[p.Name] = oidc.NewProvider(oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	return providers
+}
+
+func newJwtKeySet(cfg *config.Config) (*jwt.KeySet, error) {
+	var (
+		keys []*jwt.Key
+		err  error
+	)
+	switch {
+	case cfg.JWTKeysDir != "":
+		keys, err = jwt.LoadKeyDir(cfg.JWTKeysDir)
+	case len(cfg.JWTKeyFiles) > 0:
+		keys, err = jwt.LoadKeyFiles(cfg.JWTKeyFiles)
+	default:
+		return jwt.NewHMACKeySet([]byte(cfg.JWTSecret)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return jwt.NewKeySet(keys, jwt.Rotation{
+		ActiveKID:   cfg.JWTActiveKID,
+		RotatedAt:   cfg.JWTKeyRotatedAt,
+		GracePeriod: cfg.JWTKeyGracePeriod,
+	}, []byte(cfg.JWTSecret))
+}
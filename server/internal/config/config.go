@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -16,6 +18,66 @@ type Config struct {
 	DBHost     string
 	DBPassword string
 	SslMode    string
+
+	// Asymmetric signing keys; when neither a directory nor files are set,
+	// tokens are signed with HS256 using JWTSecret. JWTActiveKID is required
+	// with keys. Other keys stop verifying JWTKeyGracePeriod after
+	// JWTKeyRotatedAt, or never while it is unset.
+	JWTKeysDir        string
+	JWTKeyFiles       []string
+	JWTActiveKID      string
+	JWTKeyRotatedAt   time.Time
+	JWTKeyGracePeriod time.Duration
+
+	// Blob storage for uploaded images; StorageDriver is "local" or "s3".
+	StorageDriver  string
+	StorageDir     string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UseSSL       bool
+	MediaURLSecret string
+	MediaURLTTL    time.Duration
+
+	// Outgoing mail; Mailer is "log", "file" or "smtp". AppURL is the base
+	// of links in verification and password reset emails.
+	Mailer       string
+	MailDir      string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	AppURL       string
+
+	// OpenID Connect providers for social login, from OIDC_PROVIDERS.
+	OIDCProviders []OIDCProviderConfig
+
+	// LogFormat is "text" or "json"; LogLevel is "debug", "info", "warn" or
+	// "error".
+	LogFormat string
+	LogLevel  string
+
+	// Prometheus metrics are served at /metrics on the server port, or on
+	// MetricsPort when set. MetricsToken, when set, must be sent as a bearer
+	// token.
+	MetricsPort  int
+	MetricsToken string
+}
+
+// OIDCProviderConfig is read from OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL and _SCOPES. The redirect URL defaults to the
+// provider's callback under APP_URL. Providers must support OpenID discovery;
+// plain OAuth2 providers such as GitHub don't issue ID tokens.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func LoadConfig() (*Config, error) {
@@ -24,6 +86,19 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DATABASE_PORT", 5432)
 	viper.SetDefault("DATABASE_HOST", "localhost")
 	viper.SetDefault("DATABASE_SSLMODE", "disable")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_DIR", "uploads")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_USE_SSL", true)
+	viper.SetDefault("MEDIA_URL_TTL", "1h")
+	viper.SetDefault("JWT_KEY_GRACE_PERIOD", "24h")
+	viper.SetDefault("MAILER", "log")
+	viper.SetDefault("MAIL_DIR", "mail")
+	viper.SetDefault("MAIL_FROM", "Workout Tracker <no-reply@localhost>")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("APP_URL", "http://localhost:8080")
+	viper.SetDefault("LOG_FORMAT", "text")
+	viper.SetDefault("LOG_LEVEL", "info")
 
 	viper.AutomaticEnv()
 
@@ -49,15 +124,117 @@ func LoadConfig() (*Config, error) {
 	databasePassword := viper.GetString("DATABASE_PASSWORD")
 	databaseSslMode := viper.GetString("DATABASE_SSLMODE")
 
+	appURL := viper.GetString("APP_URL")
+	oidcProviders, err := loadOIDCProviders(appURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtKeysDir := viper.GetString("JWT_KEYS_DIR")
+	jwtKeyFiles := splitList(viper.GetString("JWT_KEY_FILES"))
+	var jwtKeyRotatedAt time.Time
+	if raw := viper.GetString("JWT_KEY_ROTATED_AT"); raw != "" {
+		if jwtKeyRotatedAt, err = time.Parse(time.RFC3339, raw); err != nil {
+			return nil, fmt.Errorf("JWT_KEY_ROTATED_AT must be an RFC 3339 time: %w", err)
+		}
+	}
+	mediaURLSecret, err := resolveMediaURLSecret(jwtSecret, jwtKeysDir != "" || len(jwtKeyFiles) > 0)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		ServerPort: serverPort,
 		ServerHost: serverHost,
 		JWTSecret:  jwtSecret,
+
+		JWTKeysDir:        jwtKeysDir,
+		JWTKeyFiles:       jwtKeyFiles,
+		JWTActiveKID:      viper.GetString("JWT_ACTIVE_KID"),
+		JWTKeyRotatedAt:   jwtKeyRotatedAt,
+		JWTKeyGracePeriod: viper.GetDuration("JWT_KEY_GRACE_PERIOD"),
+
 		DBUser:     databaseUser,
 		DBPort:     databasePort,
 		DBName:     databaseName,
 		DBHost:     databaseHost,
 		DBPassword: databasePassword,
 		SslMode:    databaseSslMode,
+
+		StorageDriver:  viper.GetString("STORAGE_DRIVER"),
+		StorageDir:     viper.GetString("STORAGE_DIR"),
+		S3Endpoint:     viper.GetString("S3_ENDPOINT"),
+		S3Region:       viper.GetString("S3_REGION"),
+		S3Bucket:       viper.GetString("S3_BUCKET"),
+		S3AccessKey:    viper.GetString("S3_ACCESS_KEY"),
+		S3SecretKey:    viper.GetString("S3_SECRET_KEY"),
+		S3UseSSL:       viper.GetBool("S3_USE_SSL"),
+		MediaURLSecret: mediaURLSecret,
+		MediaURLTTL:    viper.GetDuration("MEDIA_URL_TTL"),
+
+		Mailer:       viper.GetString("MAILER"),
+		MailDir:      viper.GetString("MAIL_DIR"),
+		MailFrom:     viper.GetString("MAIL_FROM"),
+		SMTPHost:     viper.GetString("SMTP_HOST"),
+		SMTPPort:     viper.GetInt("SMTP_PORT"),
+		SMTPUsername: viper.GetString("SMTP_USERNAME"),
+		SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		AppURL:       appURL,
+
+		OIDCProviders: oidcProviders,
+
+		LogFormat: viper.GetString("LOG_FORMAT"),
+		LogLevel:  viper.GetString("LOG_LEVEL"),
+
+		MetricsPort:  viper.GetInt("METRICS_PORT"),
+		MetricsToken: viper.GetString("METRICS_TOKEN"),
 	}, nil
 }
+
+// resolveMediaURLSecret falls back to JWT_SECRET only while it signs tokens.
+// With asymmetric keys it may be unset, and an empty HMAC key would let
+// anyone forge media links.
+func resolveMediaURLSecret(jwtSecret string, asymmetricKeys bool) (string, error) {
+	secret := viper.GetString("MEDIA_URL_SECRET")
+	if secret == "" && !asymmetricKeys {
+		secret = jwtSecret
+	}
+	if secret == "" {
+		return "", fmt.Errorf("MEDIA_URL_SECRET must be set when JWT_SECRET is empty or JWT signing keys are configured")
+	}
+	return secret, nil
+}
+
+func loadOIDCProviders(appURL string) ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+	for _, name := range splitList(viper.GetString("OIDC_PROVIDERS")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Scopes:       splitList(viper.GetString(prefix + "SCOPES")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("oidc provider %q needs %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = strings.TrimSuffix(appURL, "/") + "/api/v1/user/oidc/" + name + "/callback"
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig_MediaURLSecretFallsBackToJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "jwt-secret")
+
+	cfg, err := LoadConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt-secret", cfg.MediaURLSecret)
+}
+
+func TestLoadConfig_AsymmetricKeysRequireMediaURLSecret(t *testing.T) {
+	t.Setenv("JWT_KEY_FILES", "keys/2025.pem")
+
+	_, err := LoadConfig()
+	assert.Error(t, err)
+
+	// A leftover JWT_SECRET no longer signs anything, so it isn't reused.
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+
+	t.Setenv("MEDIA_URL_SECRET", "media-secret")
+	cfg, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "media-secret", cfg.MediaURLSecret)
+}
+
+func TestLoadConfig_EmptySecretsAreRejected(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("MEDIA_URL_SECRET", "")
+
+	_, err := LoadConfig()
+
+	assert.Error(t, err)
+}
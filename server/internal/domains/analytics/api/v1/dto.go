@@ -0,0 +1,14 @@
+package v1
+
+import "github.com/TBuckholz5/workouttracker/internal/domains/analytics/models"
+
+type ExerciseProgressionResponse struct {
+	ExerciseID int64                     `json:"exerciseID"`
+	Formula    string                    `json:"formula"`
+	Points     []models.ProgressionPoint `json:"points"`
+}
+
+type MuscleVolumeResponse struct {
+	Period  string                `json:"period"`
+	Periods []models.PeriodVolume `json:"periods"`
+}
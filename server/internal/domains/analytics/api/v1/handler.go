@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/analytics/service"
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/onerepmax"
+	"github.com/TBuckholz5/workouttracker/internal/util/timeparse"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+type Handler struct {
+	service     service.AnalyticsService
+	weightUnits units.Resolver
+}
+
+func NewHandler(s service.AnalyticsService, weightUnits units.Resolver) *Handler {
+	return &Handler{service: s, weightUnits: weightUnits}
+}
+
+func (h *Handler) GetExerciseProgression(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := strconv.ParseInt(r.PathValue("exerciseID"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("exerciseID"))
+		return
+	}
+	queryParams := r.URL.Query()
+	formula, err := onerepmax.ParseFormula(queryParams.Get("formula"))
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("formula"))
+		return
+	}
+	params := service.ExerciseProgressionParams{
+		UserID:     userID.(int64),
+		ExerciseID: exerciseID,
+		Formula:    formula,
+	}
+	if val := queryParams.Get("from"); val != "" {
+		from, err := timeparse.Parse(val, false)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("from"))
+			return
+		}
+		params.From = &from
+	}
+	if val := queryParams.Get("to"); val != "" {
+		to, err := timeparse.Parse(val, true)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("to"))
+			return
+		}
+		params.To = &to
+	}
+	unit, ok := h.weightUnit(w, r, params.UserID)
+	if !ok {
+		return
+	}
+	points, err := h.service.GetExerciseProgression(r.Context(), &params)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	progressionFromKilograms(points, unit)
+	if err := json.NewEncoder(w).Encode(ExerciseProgressionResponse{
+		ExerciseID: exerciseID,
+		Formula:    string(formula),
+		Points:     points,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetMuscleVolume(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	queryParams := r.URL.Query()
+	params := service.MuscleVolumeParams{
+		UserID:           userID.(int64),
+		Period:           service.PeriodWeek,
+		CountDropsets:    true,
+		CountFailureSets: true,
+	}
+	if val := queryParams.Get("period"); val != "" {
+		params.Period = service.Period(val)
+	}
+	if val := queryParams.Get("countDropsets"); val != "" {
+		countDropsets, err := strconv.ParseBool(val)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("countDropsets"))
+			return
+		}
+		params.CountDropsets = countDropsets
+	}
+	if val := queryParams.Get("countFailureSets"); val != "" {
+		countFailureSets, err := strconv.ParseBool(val)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("countFailureSets"))
+			return
+		}
+		params.CountFailureSets = countFailureSets
+	}
+	if val := queryParams.Get("from"); val != "" {
+		from, err := timeparse.Parse(val, false)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("from"))
+			return
+		}
+		params.From = &from
+	}
+	if val := queryParams.Get("to"); val != "" {
+		to, err := timeparse.Parse(val, true)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("to"))
+			return
+		}
+		params.To = &to
+	}
+	unit, ok := h.weightUnit(w, r, params.UserID)
+	if !ok {
+		return
+	}
+	periods, err := h.service.GetMuscleVolume(r.Context(), &params)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	volumesFromKilograms(periods, unit)
+	if err := json.NewEncoder(w).Encode(MuscleVolumeResponse{
+		Period:  string(params.Period),
+		Periods: periods,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/analytics/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+// weightUnit looks up the unit the user reads weights in. On failure it
+// writes the error response and returns false.
+func (h *Handler) weightUnit(w http.ResponseWriter, r *http.Request, userID int64) (units.WeightUnit, bool) {
+	unit, err := h.weightUnits.WeightUnit(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return "", false
+	}
+	return unit, true
+}
+
+// Analytics are computed in kilograms and converted for the response.
+
+func progressionFromKilograms(points []models.ProgressionPoint, unit units.WeightUnit) {
+	for i := range points {
+		points[i].TopSet.Weight = units.FromKilograms(points[i].TopSet.Weight, unit)
+		points[i].Estimated1RM = units.FromKilograms(points[i].Estimated1RM, unit)
+		points[i].TotalVolume = units.FromKilograms(points[i].TotalVolume, unit)
+	}
+}
+
+func volumesFromKilograms(periods []models.PeriodVolume, unit units.WeightUnit) {
+	for i := range periods {
+		muscles := periods[i].Muscles
+		for j := range muscles {
+			muscles[j].Tonnage = units.FromKilograms(muscles[j].Tonnage, unit)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type TopSet struct {
+	Weight float64 `json:"weight"`
+	Reps   int     `json:"reps"`
+}
+
+// ProgressionPoint summarises one session's work on a single exercise.
+type ProgressionPoint struct {
+	SessionID    int64     `json:"sessionID"`
+	Date         time.Time `json:"date"`
+	TopSet       TopSet    `json:"topSet"`
+	Estimated1RM float64   `json:"estimated1RM"`
+	TotalVolume  float64   `json:"totalVolume"`
+	TotalReps    int       `json:"totalReps"`
+}
+
+type MuscleVolume struct {
+	Muscle    string  `json:"muscle"`
+	HardSets  int     `json:"hardSets"`
+	TotalReps int     `json:"totalReps"`
+	Tonnage   float64 `json:"tonnage"`
+}
+
+// PeriodVolume groups muscle volumes for one ISO week or calendar month.
+type PeriodVolume struct {
+	Period      string         `json:"period"`
+	PeriodStart time.Time      `json:"periodStart"`
+	Muscles     []MuscleVolume `json:"muscles"`
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "time"
+
+type ExerciseSet struct {
+	SessionID   int64     `db:"session_id"`
+	SessionDate time.Time `db:"created_at"`
+	Weight      float64   `db:"weight"`
+	Reps        int       `db:"reps"`
+	SetType     string    `db:"set_type"`
+}
+
+type MuscleVolume struct {
+	PeriodStart time.Time `db:"period_start"`
+	Muscle      string    `db:"muscle"`
+	HardSets    int       `db:"hard_sets"`
+	TotalReps   int       `db:"total_reps"`
+	Tonnage     float64   `db:"tonnage"`
+}
@@ -0,0 +1,41 @@
+package repository
+
+const getExerciseSetsQuery = `SELECT s.id, s.created_at, COALESCE(ws.weight, 0), ws.reps, ws.set_type
+	FROM workout_sets ws
+	JOIN workouts w ON w.id = ws.workout_id
+	JOIN sessions s ON s.id = w.session_id
+	WHERE s.user_id = $1
+		AND w.exercise_id = $2
+		AND s.finished_at IS NOT NULL
+		AND ($3::timestamp IS NULL OR s.created_at >= $3)
+		AND ($4::timestamp IS NULL OR s.created_at < $4)
+	ORDER BY s.created_at, s.id, w.id, ws.set_order;`
+
+// getMuscleVolumeQuery buckets with date_trunc. Its 'week' unit starts on
+// Monday, so timestamps are shifted by the user's week_start preference
+// (0 for Sunday) to make weeks begin on that day instead. Users have no time
+// zone preference, so buckets follow the stored UTC timestamps. Set types
+// listed in $5 still add reps and tonnage but are not counted as hard sets.
+const getMuscleVolumeQuery = `WITH week AS (
+		SELECT ((8 - COALESCE((SELECT week_start FROM user_preferences WHERE user_id = $1), 1)) % 7)
+			* INTERVAL '1 day' AS shift
+	)
+	SELECT CASE WHEN $2 = 'week'
+			THEN date_trunc('week', s.created_at + week.shift) - week.shift
+			ELSE date_trunc($2, s.created_at)
+		END AS period_start,
+		COALESCE(NULLIF(e.target_muscle, ''), 'unspecified') AS muscle,
+		COUNT(*) FILTER (WHERE ws.reps > 0 AND ws.set_type::text <> ALL($5::text[])) AS hard_sets,
+		COALESCE(SUM(ws.reps), 0) AS total_reps,
+		COALESCE(SUM(ws.reps * COALESCE(ws.weight, 0)), 0) AS tonnage
+	FROM workout_sets ws
+	JOIN workouts w ON w.id = ws.workout_id
+	JOIN sessions s ON s.id = w.session_id
+	JOIN exercises e ON e.id = w.exercise_id
+	CROSS JOIN week
+	WHERE s.user_id = $1
+		AND s.finished_at IS NOT NULL
+		AND ($3::timestamp IS NULL OR s.created_at >= $3)
+		AND ($4::timestamp IS NULL OR s.created_at < $4)
+	GROUP BY period_start, muscle
+	ORDER BY period_start, muscle;`
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type GetExerciseSetsParams struct {
+	UserID     int64
+	ExerciseID int64
+	From       *time.Time
+	To         *time.Time
+}
+
+type GetMuscleVolumeParams struct {
+	UserID int64
+	// Bucket is a date_trunc unit, either "week" or "month". Weeks start on
+	// the user's preferred day.
+	Bucket string
+	From   *time.Time
+	To     *time.Time
+	// ExcludedSetTypes are set_type values that do not count as hard sets.
+	ExcludedSetTypes []string
+}
+
+type AnalyticsRepository interface {
+	GetExerciseSets(ctx context.Context, params *GetExerciseSetsParams) ([]*ExerciseSet, error)
+	GetMuscleVolume(ctx context.Context, params *GetMuscleVolumeParams) ([]*MuscleVolume, error)
+}
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		pool: pool,
+	}
+}
+
+// GetExerciseSets returns every set the user logged for an exercise in
+// finished sessions, oldest session first.
+func (r *Repository) GetExerciseSets(ctx context.Context, params *GetExerciseSetsParams) ([]*ExerciseSet, error) {
+	rows, err := r.pool.Query(ctx, getExerciseSetsQuery, params.UserID, params.ExerciseID, params.From, params.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise sets: %w", err)
+	}
+	defer rows.Close()
+
+	sets := make([]*ExerciseSet, 0)
+	for rows.Next() {
+		var set ExerciseSet
+		err := rows.Scan(
+			&set.SessionID,
+			&set.SessionDate,
+			&set.Weight,
+			&set.Reps,
+			&set.SetType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exercise set row: %w", err)
+		}
+		sets = append(sets, &set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get exercise sets: %w", err)
+	}
+	return sets, nil
+}
+
+func (r *Repository) GetMuscleVolume(ctx context.Context, params *GetMuscleVolumeParams) ([]*MuscleVolume, error) {
+	excluded := params.ExcludedSetTypes
+	if excluded == nil {
+		excluded = []string{}
+	}
+	rows, err := r.pool.Query(ctx, getMuscleVolumeQuery, params.UserID, params.Bucket, params.From, params.To, excluded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muscle volume: %w", err)
+	}
+	defer rows.Close()
+
+	volumes := make([]*MuscleVolume, 0)
+	for rows.Next() {
+		var volume MuscleVolume
+		err := rows.Scan(
+			&volume.PeriodStart,
+			&volume.Muscle,
+			&volume.HardSets,
+			&volume.TotalReps,
+			&volume.Tonnage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan muscle volume row: %w", err)
+		}
+		volumes = append(volumes, &volume)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get muscle volume: %w", err)
+	}
+	return volumes, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/onerepmax"
+)
+
+type ExerciseProgressionParams struct {
+	UserID     int64
+	ExerciseID int64
+	From       *time.Time
+	To         *time.Time
+	Formula    onerepmax.Formula
+}
+
+type Period string
+
+const (
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+type MuscleVolumeParams struct {
+	UserID int64
+	Period Period
+	From   *time.Time
+	To     *time.Time
+	// CountDropsets and CountFailureSets control whether those set types
+	// count towards hard sets. Their reps and tonnage are always included.
+	CountDropsets    bool
+	CountFailureSets bool
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/analytics/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/analytics/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/onerepmax"
+)
+
+var ErrInvalidPeriod = apperror.Validation("invalid_period", "period must be week or month",
+	apperror.Field("period", "invalid", "must be week or month"))
+
+type AnalyticsService interface {
+	GetExerciseProgression(reqContext context.Context, params *ExerciseProgressionParams) ([]models.ProgressionPoint, error)
+	GetMuscleVolume(reqContext context.Context, params *MuscleVolumeParams) ([]models.PeriodVolume, error)
+}
+
+type Service struct {
+	repo repository.AnalyticsRepository
+}
+
+func NewService(r repository.AnalyticsRepository) *Service {
+	return &Service{
+		repo: r,
+	}
+}
+
+func (s *Service) GetExerciseProgression(reqContext context.Context, params *ExerciseProgressionParams) ([]models.ProgressionPoint, error) {
+	sets, err := s.repo.GetExerciseSets(reqContext, &repository.GetExerciseSetsParams{
+		UserID:     params.UserID,
+		ExerciseID: params.ExerciseID,
+		From:       params.From,
+		To:         params.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildProgression(sets, params.Formula), nil
+}
+
+// buildProgression folds sets, which must be grouped by session, into one
+// point per session.
+func buildProgression(sets []*repository.ExerciseSet, formula onerepmax.Formula) []models.ProgressionPoint {
+	points := make([]models.ProgressionPoint, 0)
+	for _, set := range sets {
+		if len(points) == 0 || points[len(points)-1].SessionID != set.SessionID {
+			points = append(points, models.ProgressionPoint{
+				SessionID: set.SessionID,
+				Date:      set.SessionDate,
+			})
+		}
+		point := &points[len(points)-1]
+		if set.Weight > point.TopSet.Weight ||
+			(set.Weight == point.TopSet.Weight && set.Reps > point.TopSet.Reps) {
+			point.TopSet = models.TopSet{Weight: set.Weight, Reps: set.Reps}
+		}
+		point.Estimated1RM = max(point.Estimated1RM, round2(onerepmax.Estimate(formula, set.Weight, set.Reps)))
+		point.TotalVolume = round2(point.TotalVolume + set.Weight*float64(set.Reps))
+		point.TotalReps += set.Reps
+	}
+	return points
+}
+
+func (s *Service) GetMuscleVolume(reqContext context.Context, params *MuscleVolumeParams) ([]models.PeriodVolume, error) {
+	if params.Period != PeriodWeek && params.Period != PeriodMonth {
+		return nil, ErrInvalidPeriod
+	}
+	excluded := []string{}
+	if !params.CountDropsets {
+		excluded = append(excluded, "dropset")
+	}
+	if !params.CountFailureSets {
+		excluded = append(excluded, "failure")
+	}
+	volumes, err := s.repo.GetMuscleVolume(reqContext, &repository.GetMuscleVolumeParams{
+		UserID:           params.UserID,
+		Bucket:           string(params.Period),
+		From:             params.From,
+		To:               params.To,
+		ExcludedSetTypes: excluded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]models.PeriodVolume, 0)
+	for _, volume := range volumes {
+		if len(periods) == 0 || !periods[len(periods)-1].PeriodStart.Equal(volume.PeriodStart) {
+			periods = append(periods, models.PeriodVolume{
+				Period:      periodLabel(params.Period, volume.PeriodStart),
+				PeriodStart: volume.PeriodStart,
+				Muscles:     []models.MuscleVolume{},
+			})
+		}
+		period := &periods[len(periods)-1]
+		period.Muscles = append(period.Muscles, models.MuscleVolume{
+			Muscle:    volume.Muscle,
+			HardSets:  volume.HardSets,
+			TotalReps: volume.TotalReps,
+			Tonnage:   round2(volume.Tonnage),
+		})
+	}
+	return periods, nil
+}
+
+// periodLabel renders weeks as 2025-W49 and months as 2025-12. Weeks start on
+// the user's preferred day, so they are named after the ISO week holding
+// their fourth day, the one sharing most of their days.
+func periodLabel(period Period, start time.Time) string {
+	if period == PeriodWeek {
+		year, week := start.AddDate(0, 0, 3).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return start.Format("2006-01")
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/analytics/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/onerepmax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) GetExerciseSets(ctx context.Context, params *repository.GetExerciseSetsParams) ([]*repository.ExerciseSet, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]*repository.ExerciseSet), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) GetMuscleVolume(ctx context.Context, params *repository.GetMuscleVolumeParams) ([]*repository.MuscleVolume, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]*repository.MuscleVolume), args.Error(1)
+}
+
+func TestService_GetExerciseProgression_AggregatesPerSession(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	service := NewService(mockRepo)
+	ctx := context.Background()
+
+	day1 := time.Date(2025, 12, 1, 18, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 3)
+	mockRepo.On("GetExerciseSets", ctx, mock.MatchedBy(func(p *repository.GetExerciseSetsParams) bool {
+		return p.UserID == 42 && p.ExerciseID == 1
+	})).Return([]*repository.ExerciseSet{
+		{SessionID: 1, SessionDate: day1, Weight: 100, Reps: 5},
+		{SessionID: 1, SessionDate: day1, Weight: 100, Reps: 6},
+		{SessionID: 1, SessionDate: day1, Weight: 80, Reps: 10},
+		{SessionID: 2, SessionDate: day2, Weight: 105, Reps: 3},
+	}, nil)
+
+	points, err := service.GetExerciseProgression(ctx, &ExerciseProgressionParams{
+		UserID:     42,
+		ExerciseID: 1,
+		Formula:    onerepmax.Epley,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	assert.Equal(t, int64(1), points[0].SessionID)
+	assert.Equal(t, day1, points[0].Date)
+	assert.Equal(t, 100.0, points[0].TopSet.Weight)
+	assert.Equal(t, 6, points[0].TopSet.Reps)
+	assert.Equal(t, 120.0, points[0].Estimated1RM)
+	assert.Equal(t, 1900.0, points[0].TotalVolume)
+	assert.Equal(t, 21, points[0].TotalReps)
+	assert.Equal(t, 105.0, points[1].TopSet.Weight)
+	assert.Equal(t, 315.0, points[1].TotalVolume)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetExerciseProgression_Formulas(t *testing.T) {
+	sets := []*repository.ExerciseSet{{SessionID: 1, Weight: 100, Reps: 10}}
+
+	assert.Equal(t, 133.33, buildProgression(sets, onerepmax.Epley)[0].Estimated1RM)
+	assert.Equal(t, 133.33, buildProgression(sets, onerepmax.Brzycki)[0].Estimated1RM)
+	assert.Equal(t, 125.89, buildProgression(sets, onerepmax.Lombardi)[0].Estimated1RM)
+}
+
+func TestService_GetExerciseProgression_RepositoryError(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	service := NewService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetExerciseSets", ctx, mock.Anything).Return(([]*repository.ExerciseSet)(nil), errors.New("db error"))
+
+	points, err := service.GetExerciseProgression(ctx, &ExerciseProgressionParams{UserID: 42, ExerciseID: 1})
+
+	assert.Error(t, err)
+	assert.Nil(t, points)
+}
+
+func TestService_GetMuscleVolume_GroupsByPeriod(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	service := NewService(mockRepo)
+	ctx := context.Background()
+
+	week1 := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+	mockRepo.On("GetMuscleVolume", ctx, mock.MatchedBy(func(p *repository.GetMuscleVolumeParams) bool {
+		return p.UserID == 42 && p.Bucket == "week" && assert.ObjectsAreEqual([]string{"dropset"}, p.ExcludedSetTypes)
+	})).Return([]*repository.MuscleVolume{
+		{PeriodStart: week1, Muscle: "Back", HardSets: 10, TotalReps: 80, Tonnage: 4000},
+		{PeriodStart: week1, Muscle: "Chest", HardSets: 12, TotalReps: 96, Tonnage: 6000.5},
+		{PeriodStart: week2, Muscle: "Chest", HardSets: 8, TotalReps: 64, Tonnage: 4100},
+	}, nil)
+
+	periods, err := service.GetMuscleVolume(ctx, &MuscleVolumeParams{
+		UserID:           42,
+		Period:           PeriodWeek,
+		CountDropsets:    false,
+		CountFailureSets: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, periods, 2)
+	assert.Equal(t, "2025-W49", periods[0].Period)
+	assert.Len(t, periods[0].Muscles, 2)
+	assert.Equal(t, "Chest", periods[0].Muscles[1].Muscle)
+	assert.Equal(t, 6000.5, periods[0].Muscles[1].Tonnage)
+	assert.Equal(t, "2025-W50", periods[1].Period)
+	assert.Equal(t, 8, periods[1].Muscles[0].HardSets)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPeriodLabel_WeeksStartingOffMonday(t *testing.T) {
+	sunday := time.Date(2025, 12, 7, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2025, 12, 6, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2025-W50", periodLabel(PeriodWeek, sunday))
+	assert.Equal(t, "2025-W50", periodLabel(PeriodWeek, saturday))
+	assert.Equal(t, "2025-W49", periodLabel(PeriodWeek, sunday.AddDate(0, 0, -7)))
+	assert.Equal(t, "2026-W01", periodLabel(PeriodWeek, yearEnd))
+}
+
+func TestService_GetMuscleVolume_MonthLabel(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	service := NewService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetMuscleVolume", ctx, mock.MatchedBy(func(p *repository.GetMuscleVolumeParams) bool {
+		return p.Bucket == "month" && len(p.ExcludedSetTypes) == 0
+	})).Return([]*repository.MuscleVolume{
+		{PeriodStart: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), Muscle: "Legs", HardSets: 30},
+	}, nil)
+
+	periods, err := service.GetMuscleVolume(ctx, &MuscleVolumeParams{
+		UserID:           42,
+		Period:           PeriodMonth,
+		CountDropsets:    true,
+		CountFailureSets: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-11", periods[0].Period)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetMuscleVolume_InvalidPeriod(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	service := NewService(mockRepo)
+
+	periods, err := service.GetMuscleVolume(context.Background(), &MuscleVolumeParams{UserID: 42, Period: "day"})
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	assert.Nil(t, periods)
+	mockRepo.AssertNotCalled(t, "GetMuscleVolume", mock.Anything, mock.Anything)
+}
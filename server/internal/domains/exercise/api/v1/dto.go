@@ -6,12 +6,17 @@ type Exercise struct {
 	Description  string `json:"description"`
 	TargetMuscle string `json:"targetMuscle"`
 	PictureURL   string `json:"pictureURL"`
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+	Equipment    string `json:"equipment"`
+	IsGlobal     bool   `json:"isGlobal"`
+	Archived     bool   `json:"archived"`
 }
 
 type CreateExerciseRequest struct {
 	Name         string `json:"name" binding:"required"`
 	Description  string `json:"description"`
 	TargetMuscle string `json:"targetMuscle" binding:"required"`
+	Equipment    string `json:"equipment"`
 }
 
 type CreateExerciseResponse struct {
@@ -20,4 +25,24 @@ type CreateExerciseResponse struct {
 
 type GetExerciseListResponse struct {
 	Exercises []Exercise `json:"exercises"`
+	Total     int        `json:"total"`
+}
+
+type UpdateExerciseRequest struct {
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	TargetMuscle *string `json:"targetMuscle"`
+	Equipment    *string `json:"equipment"`
+}
+
+type ExerciseResponse struct {
+	Exercise Exercise `json:"exercise"`
+}
+
+type DeleteExerciseResponse struct {
+	Archived bool `json:"archived"`
+}
+
+type MergeExerciseRequest struct {
+	TargetID int64 `json:"targetID" binding:"required"`
 }
@@ -2,30 +2,47 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/service"
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/storage"
+	"github.com/TBuckholz5/workouttracker/internal/util/validate"
+)
+
+var (
+	errMissingImage = apperror.Validation("missing_image", "request must be a multipart form with an image field",
+		apperror.Field("image", "required", "is required"))
+	errInvalidSignature = apperror.Forbidden("invalid_signature", "image link is invalid or expired")
 )
 
 type Handler struct {
 	service service.ExerciseService
+	signer  *storage.URLSigner
 }
 
-func NewHandler(s service.ExerciseService) *Handler {
-	return &Handler{service: s}
+func NewHandler(s service.ExerciseService, signer *storage.URLSigner) *Handler {
+	return &Handler{service: s, signer: signer}
 }
 
 func (h *Handler) CreateExercise(w http.ResponseWriter, r *http.Request) {
 	var payload CreateExerciseRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 	userID := r.Context().Value(auth.CtxKeyUserID)
 	if userID == nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		apperror.Write(w, r, auth.ErrNoUserID)
 		return
 	}
 	params := service.CreateExerciseForUserParams{
@@ -33,20 +50,15 @@ func (h *Handler) CreateExercise(w http.ResponseWriter, r *http.Request) {
 		Name:         payload.Name,
 		Description:  payload.Description,
 		TargetMuscle: payload.TargetMuscle,
+		Equipment:    payload.Equipment,
 	}
 	exercise, err := h.service.CreateExercise(r.Context(), &params)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		apperror.Write(w, r, err)
 		return
 	}
 	if err := json.NewEncoder(w).Encode(CreateExerciseResponse{
-		Exercise: Exercise{
-			ID:           exercise.ID,
-			Name:         exercise.Name,
-			Description:  exercise.Description,
-			TargetMuscle: exercise.TargetMuscle,
-			PictureURL:   exercise.PictureURL,
-		},
+		Exercise: h.exerciseToDTO(exercise),
 	}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -56,7 +68,7 @@ func (h *Handler) CreateExercise(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetExerciseForUser(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(auth.CtxKeyUserID)
 	if userID == nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		apperror.Write(w, r, auth.ErrNoUserID)
 		return
 	}
 	queryParams := r.URL.Query()
@@ -65,7 +77,7 @@ func (h *Handler) GetExerciseForUser(w http.ResponseWriter, r *http.Request) {
 	if val := queryParams.Get("offset"); val != "" {
 		parsedOffset, err := strconv.Atoi(val)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			apperror.Write(w, r, apperror.InvalidParameter("offset"))
 			return
 		}
 		offset = parsedOffset
@@ -73,32 +85,220 @@ func (h *Handler) GetExerciseForUser(w http.ResponseWriter, r *http.Request) {
 	if val := queryParams.Get("limit"); val != "" {
 		parsedLimit, err := strconv.Atoi(val)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			apperror.Write(w, r, apperror.InvalidParameter("limit"))
 			return
 		}
 		limit = parsedLimit
 	}
+	includeArchived := false
+	if val := queryParams.Get("includeArchived"); val != "" {
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("includeArchived"))
+			return
+		}
+		includeArchived = parsed
+	}
 	payload := service.GetExerciseForUserParams{
-		UserID: userID.(int64),
-		Offset: offset,
-		Limit:  limit,
+		UserID:          userID.(int64),
+		Search:          queryParams.Get("q"),
+		TargetMuscle:    queryParams.Get("targetMuscle"),
+		Equipment:       queryParams.Get("equipment"),
+		Sort:            queryParams.Get("sort"),
+		IncludeArchived: includeArchived,
+		Offset:          offset,
+		Limit:           limit,
 	}
-	exercises, err := h.service.GetExercisesForUser(r.Context(), &payload)
+	result, err := h.service.GetExercisesForUser(r.Context(), &payload)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		apperror.Write(w, r, err)
 		return
 	}
 	exercisesDTO := []Exercise{}
-	for _, ex := range exercises {
-		exercisesDTO = append(exercisesDTO, Exercise{
-			ID:           ex.ID,
-			Name:         ex.Name,
-			Description:  ex.Description,
-			TargetMuscle: ex.TargetMuscle,
-			PictureURL:   ex.PictureURL,
-		})
-	}
-	if err := json.NewEncoder(w).Encode(GetExerciseListResponse{Exercises: exercisesDTO}); err != nil {
+	for _, ex := range result.Exercises {
+		exercisesDTO = append(exercisesDTO, h.exerciseToDTO(ex))
+	}
+	if err := json.NewEncoder(w).Encode(GetExerciseListResponse{Exercises: exercisesDTO, Total: result.Total}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetExercise(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	exercise, err := h.service.GetExercise(r.Context(), userID.(int64), exerciseID)
+	h.writeExerciseResult(w, r, exercise, err)
+}
+
+func (h *Handler) UpdateExercise(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	var payload UpdateExerciseRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	exercise, err := h.service.UpdateExercise(r.Context(), &service.UpdateExerciseParams{
+		ID:           exerciseID,
+		UserID:       userID.(int64),
+		Name:         payload.Name,
+		Description:  payload.Description,
+		TargetMuscle: payload.TargetMuscle,
+		Equipment:    payload.Equipment,
+	})
+	h.writeExerciseResult(w, r, exercise, err)
+}
+
+func (h *Handler) DeleteExercise(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	archived, err := h.service.DeleteExercise(r.Context(), userID.(int64), exerciseID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(DeleteExerciseResponse{Archived: archived}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+func (h *Handler) MergeExercise(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sourceID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	var payload MergeExerciseRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	exercise, err := h.service.MergeExercises(r.Context(), userID.(int64), sourceID, payload.TargetID)
+	h.writeExerciseResult(w, r, exercise, err)
+}
+
+// UploadImage accepts a multipart form with the picture in the "image" field.
+func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	// Leave headroom for the multipart framing around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxImageBytes+1<<20)
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apperror.Write(w, r, service.ErrImageTooLarge)
+			return
+		}
+		apperror.Write(w, r, errMissingImage)
+		return
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxImageBytes+1))
+	if err != nil {
+		apperror.Write(w, r, errMissingImage)
+		return
+	}
+	exercise, err := h.service.UploadImage(r.Context(), userID.(int64), exerciseID, data)
+	h.writeExerciseResult(w, r, exercise, err)
+}
+
+// ServeImage streams a stored image. It is reached without a bearer token, so
+// access is granted by the signature on the URL handed out in exercise responses.
+func (h *Handler) ServeImage(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	query := r.URL.Query()
+	if err := h.signer.Verify(key, query.Get("expires"), query.Get("sig")); err != nil {
+		apperror.Write(w, r, errInvalidSignature)
+		return
+	}
+	body, contentType, err := h.service.OpenImage(r.Context(), key)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	defer func() { _ = body.Close() }()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, body)
+}
+
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(r.PathValue(name), 10, 64)
+}
+
+func (h *Handler) writeExerciseResult(w http.ResponseWriter, r *http.Request, exercise models.Exercise, err error) {
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(ExerciseResponse{Exercise: h.exerciseToDTO(exercise)}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) exerciseToDTO(exercise models.Exercise) Exercise {
+	dto := Exercise{
+		ID:           exercise.ID,
+		Name:         exercise.Name,
+		Description:  exercise.Description,
+		TargetMuscle: exercise.TargetMuscle,
+		PictureURL:   exercise.PictureURL,
+		Equipment:    exercise.Equipment,
+		IsGlobal:     exercise.IsGlobal,
+		Archived:     exercise.Archived,
+	}
+	if exercise.ImageKey != "" {
+		dto.PictureURL = h.signer.URL(exercise.ImageKey)
+	}
+	if exercise.ThumbnailKey != "" {
+		dto.ThumbnailURL = h.signer.URL(exercise.ThumbnailKey)
+	}
+	return dto
+}
@@ -6,4 +6,12 @@ type Exercise struct {
 	Description  string
 	TargetMuscle string
 	PictureURL   string
+	Equipment    string
+	// IsGlobal marks shared catalog entries, which users can see but not edit.
+	IsGlobal bool
+	// Archived exercises are hidden from pickers but kept for session history.
+	Archived bool
+	// ImageKey and ThumbnailKey locate an uploaded picture in blob storage.
+	ImageKey     string
+	ThumbnailKey string
 }
@@ -0,0 +1,75 @@
+// Package policy decides which exercises a user may reference from other
+// domains, such as the workouts of a session. Checks run before any write so
+// a bad reference never reaches a foreign key.
+package policy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+)
+
+var (
+	ErrExerciseForbidden = apperror.Forbidden("exercise_forbidden", "exercise belongs to another user")
+	ErrUnknownExercise   = apperror.Unprocessable("unknown_exercise", "exercise does not exist")
+)
+
+// Reference is an exercise ID taken from a request, along with the request
+// field it came from so violations can point at it.
+type Reference struct {
+	Field      string
+	ExerciseID int64
+}
+
+// OwnerLookup reports the owner of each existing exercise. Global exercises
+// map to nil and unknown IDs are missing from the result.
+type OwnerLookup interface {
+	GetExerciseOwners(ctx context.Context, exerciseIDs []int64) (map[int64]*int64, error)
+}
+
+type ExercisePolicy interface {
+	// CanUse returns ErrExerciseForbidden when a reference points at another
+	// user's private exercise and ErrUnknownExercise, with a field error per
+	// bad reference, when it points at nothing.
+	CanUse(ctx context.Context, userID int64, refs ...Reference) error
+}
+
+type Policy struct {
+	owners OwnerLookup
+}
+
+func NewPolicy(owners OwnerLookup) *Policy {
+	return &Policy{owners: owners}
+}
+
+func (p *Policy) CanUse(ctx context.Context, userID int64, refs ...Reference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		if !slices.Contains(ids, ref.ExerciseID) {
+			ids = append(ids, ref.ExerciseID)
+		}
+	}
+	owners, err := p.owners.GetExerciseOwners(ctx, ids)
+	if err != nil {
+		return err
+	}
+	var unknown []apperror.FieldError
+	for _, ref := range refs {
+		owner, ok := owners[ref.ExerciseID]
+		if !ok {
+			unknown = append(unknown, apperror.Field(ref.Field, "unknown", "does not exist"))
+			continue
+		}
+		if owner != nil && *owner != userID {
+			return ErrExerciseForbidden
+		}
+	}
+	if len(unknown) > 0 {
+		return apperror.Unprocessable(ErrUnknownExercise.Code, ErrUnknownExercise.Message, unknown...)
+	}
+	return nil
+}
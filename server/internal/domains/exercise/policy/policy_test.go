@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOwners map[int64]*int64
+
+func (f fakeOwners) GetExerciseOwners(ctx context.Context, exerciseIDs []int64) (map[int64]*int64, error) {
+	owners := make(map[int64]*int64)
+	for _, id := range exerciseIDs {
+		if owner, ok := f[id]; ok {
+			owners[id] = owner
+		}
+	}
+	return owners, nil
+}
+
+func owner(id int64) *int64 {
+	return &id
+}
+
+var exercises = fakeOwners{
+	1: nil,       // global
+	2: owner(42), // the caller's
+	3: owner(7),  // someone else's
+}
+
+func TestCanUse_OwnAndGlobalExercises(t *testing.T) {
+	p := NewPolicy(exercises)
+
+	assert.NoError(t, p.CanUse(context.Background(), 42,
+		Reference{Field: "workouts[0].exerciseID", ExerciseID: 1},
+		Reference{Field: "workouts[1].exerciseID", ExerciseID: 2},
+	))
+}
+
+func TestCanUse_ForeignExerciseIsForbidden(t *testing.T) {
+	p := NewPolicy(exercises)
+
+	err := p.CanUse(context.Background(), 42, Reference{Field: "exerciseID", ExerciseID: 3})
+
+	assert.ErrorIs(t, err, ErrExerciseForbidden)
+}
+
+func TestCanUse_UnknownExerciseNamesTheField(t *testing.T) {
+	p := NewPolicy(exercises)
+
+	err := p.CanUse(context.Background(), 42,
+		Reference{Field: "workouts[0].exerciseID", ExerciseID: 2},
+		Reference{Field: "workouts[1].exerciseID", ExerciseID: 99},
+	)
+
+	assert.ErrorIs(t, err, ErrUnknownExercise)
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.KindUnprocessable, appErr.Kind)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "workouts[1].exerciseID", Code: "unknown", Message: "does not exist"},
+	}, appErr.Fields)
+}
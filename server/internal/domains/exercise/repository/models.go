@@ -8,7 +8,13 @@ type exercise struct {
 	description  string
 	targetMuscle string
 	pictureUrl   string
+	equipment    string
 	createdAt    time.Time
 	updatedAt    time.Time
-	userId       int64
+	// userId is nil for entries in the global catalog.
+	userId     *int64
+	archivedAt *time.Time
+	// Storage keys of the uploaded image and its thumbnail, empty when unset.
+	imageKey     string
+	thumbnailKey string
 }
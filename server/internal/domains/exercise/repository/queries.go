@@ -1,9 +1,93 @@
 package repository
 
-const createExerciseQuery = `INSERT INTO exercises (name, description, target_muscle, picture_url, user_id)
-	VALUES ($1, $2, $3, $4, $5)
-	RETURNING id, name, description, target_muscle, picture_url, created_at, updated_at, user_id;`
+const exerciseColumns = `e.id, e.name, COALESCE(e.description, ''), COALESCE(e.target_muscle, ''), COALESCE(e.picture_url, ''), COALESCE(e.equipment, ''), e.created_at, e.updated_at, e.user_id, e.archived_at, COALESCE(e.image_key, ''), COALESCE(e.thumbnail_key, '')`
 
-const getExercisesForUserQuery = `SELECT id, name, description, target_muscle, picture_url, created_at, updated_at, user_id
-	FROM exercises WHERE user_id = $1
-	LIMIT $2 OFFSET $3;`
+const createExerciseQuery = `INSERT INTO exercises AS e (name, description, target_muscle, picture_url, equipment, user_id)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + exerciseColumns + `;`
+
+// exerciseFilter limits results to the user's own exercises merged with the
+// global catalog (rows without an owner). The name search is typo tolerant
+// through pg_trgm word similarity. Archived exercises are hidden unless $5 is set.
+const exerciseFilter = `(e.user_id = $1 OR e.user_id IS NULL)
+		AND ($2::text IS NULL OR e.name ILIKE '%' || $2 || '%' OR word_similarity($2, e.name) >= 0.4)
+		AND ($3::text IS NULL OR lower(e.target_muscle) = lower($3))
+		AND ($4::text IS NULL OR lower(e.equipment) = lower($4))
+		AND ($5::bool OR e.archived_at IS NULL)`
+
+// getExercisesForUserQuery is completed by listExercisesQuery with one of the
+// exerciseOrders clauses.
+const getExercisesForUserQuery = `SELECT ` + exerciseColumns + `
+	FROM exercises e
+	LEFT JOIN (
+		SELECT w.exercise_id, COUNT(*) AS use_count, MAX(s.created_at) AS last_used
+		FROM workouts w
+		JOIN sessions s ON s.id = w.session_id
+		WHERE s.user_id = $1
+		GROUP BY w.exercise_id
+	) usage ON usage.exercise_id = e.id
+	WHERE ` + exerciseFilter + `
+	ORDER BY `
+
+const countExercisesForUserQuery = `SELECT COUNT(*)
+	FROM exercises e
+	WHERE ` + exerciseFilter + `;`
+
+var exerciseOrders = map[ExerciseSort]string{
+	SortName:      "e.name, e.id",
+	SortRecent:    "usage.last_used DESC NULLS LAST, e.name, e.id",
+	SortFrequent:  "usage.use_count DESC NULLS LAST, e.name, e.id",
+	SortRelevance: "word_similarity($2, e.name) DESC, e.name, e.id",
+}
+
+func listExercisesQuery(sort ExerciseSort) string {
+	order, ok := exerciseOrders[sort]
+	if !ok {
+		order = exerciseOrders[SortName]
+	}
+	return getExercisesForUserQuery + order + `
+	LIMIT $6 OFFSET $7;`
+}
+
+const getExerciseByIDQuery = `SELECT ` + exerciseColumns + `
+	FROM exercises e
+	WHERE e.id = $1 AND (e.user_id = $2 OR e.user_id IS NULL);`
+
+// Only the owner may edit an exercise, so global catalog entries never match.
+const updateExerciseQuery = `UPDATE exercises AS e
+	SET name = COALESCE($3, e.name),
+		description = COALESCE($4, e.description),
+		target_muscle = COALESCE($5, e.target_muscle),
+		equipment = COALESCE($6, e.equipment),
+		updated_at = NOW()
+	WHERE e.id = $1 AND e.user_id = $2
+	RETURNING ` + exerciseColumns + `;`
+
+const setExerciseImageQuery = `UPDATE exercises AS e
+	SET image_key = $3, thumbnail_key = $4, updated_at = NOW()
+	WHERE e.id = $1 AND e.user_id = $2
+	RETURNING ` + exerciseColumns + `;`
+
+const lockOwnedExerciseQuery = `SELECT id FROM exercises WHERE id = $1 AND user_id = $2 FOR UPDATE;`
+
+const lockMergeTargetQuery = `SELECT id FROM exercises
+	WHERE id = $1 AND (user_id = $2 OR user_id IS NULL) AND archived_at IS NULL
+	FOR SHARE;`
+
+const exerciseReferencedQuery = `SELECT EXISTS (SELECT 1 FROM workouts WHERE exercise_id = $1)
+	OR EXISTS (SELECT 1 FROM routine_exercises WHERE exercise_id = $1)
+	OR EXISTS (SELECT 1 FROM personal_records WHERE exercise_id = $1);`
+
+const archiveExerciseQuery = `UPDATE exercises SET archived_at = COALESCE(archived_at, NOW()), updated_at = NOW() WHERE id = $1;`
+
+const deleteExerciseQuery = `DELETE FROM exercises WHERE id = $1 RETURNING COALESCE(image_key, ''), COALESCE(thumbnail_key, '');`
+
+const mergeWorkoutsQuery = `UPDATE workouts SET exercise_id = $2
+	WHERE exercise_id = $1 AND session_id IN (SELECT id FROM sessions WHERE user_id = $3);`
+
+const mergeRoutineExercisesQuery = `UPDATE routine_exercises SET exercise_id = $2
+	WHERE exercise_id = $1 AND routine_id IN (SELECT id FROM routines WHERE user_id = $3);`
+
+const deleteMergedRecordsQuery = `DELETE FROM personal_records WHERE exercise_id = $1 AND user_id = $2;`
+
+const getExerciseOwnersQuery = `SELECT id, user_id FROM exercises WHERE id = ANY($1);`
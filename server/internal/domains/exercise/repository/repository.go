@@ -2,29 +2,67 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
+	sessionRepository "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var ErrExerciseNotFound = apperror.NotFound("exercise_not_found", "exercise not found")
+
 type CreateExerciseParams struct {
 	Name         string
 	Description  string
 	TargetMuscle string
 	PictureURL   string
+	Equipment    string
 	UserID       int64
 }
 
+type ExerciseSort string
+
+const (
+	SortName      ExerciseSort = "name"
+	SortRecent    ExerciseSort = "recent"
+	SortFrequent  ExerciseSort = "frequent"
+	SortRelevance ExerciseSort = "relevance"
+)
+
 type GetExerciseForUserParams struct {
-	UserID int64
-	Limit  int
-	Offset int
+	UserID       int64
+	Search       *string
+	TargetMuscle *string
+	Equipment    *string
+	Sort         ExerciseSort
+	// IncludeArchived also returns exercises that were archived on delete,
+	// e.g. to resolve names in session history.
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+type UpdateExerciseParams struct {
+	ID           int64
+	UserID       int64
+	Name         *string
+	Description  *string
+	TargetMuscle *string
+	Equipment    *string
 }
 
 type ExerciseRepository interface {
 	CreateExercise(ctx context.Context, params *CreateExerciseParams) (models.Exercise, error)
 	GetExercisesForUser(ctx context.Context, params *GetExerciseForUserParams) ([]models.Exercise, error)
+	CountExercisesForUser(ctx context.Context, params *GetExerciseForUserParams) (int, error)
+	GetExerciseByID(ctx context.Context, userID, exerciseID int64) (models.Exercise, error)
+	UpdateExercise(ctx context.Context, params *UpdateExerciseParams) (models.Exercise, error)
+	SetExerciseImage(ctx context.Context, userID, exerciseID int64, imageKey, thumbnailKey string) (models.Exercise, error)
+	DeleteExercise(ctx context.Context, userID, exerciseID int64) (bool, []string, error)
+	MergeExercises(ctx context.Context, userID, sourceID, targetID int64) ([]string, error)
 }
 
 type Repository struct {
@@ -38,37 +76,30 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 }
 
 func (r *Repository) CreateExercise(ctx context.Context, params *CreateExerciseParams) (models.Exercise, error) {
-	var exercise exercise
-	err := r.pool.QueryRow(ctx, createExerciseQuery,
+	exercise, err := scanExercise(r.pool.QueryRow(ctx, createExerciseQuery,
 		params.Name,
 		params.Description,
 		params.TargetMuscle,
 		params.PictureURL,
+		params.Equipment,
 		params.UserID,
-	).Scan(
-		&exercise.id,
-		&exercise.name,
-		&exercise.description,
-		&exercise.targetMuscle,
-		&exercise.pictureUrl,
-		&exercise.createdAt,
-		&exercise.updatedAt,
-		&exercise.userId,
-	)
+	))
 	if err != nil {
 		return models.Exercise{}, fmt.Errorf("error creating exercise: %w", err)
 	}
-	return models.Exercise{
-		ID:           exercise.id,
-		Name:         exercise.name,
-		Description:  exercise.description,
-		TargetMuscle: exercise.targetMuscle,
-		PictureURL:   exercise.pictureUrl,
-	}, nil
+	return exercise.toModel(), nil
 }
 
 func (r *Repository) GetExercisesForUser(ctx context.Context, params *GetExerciseForUserParams) ([]models.Exercise, error) {
-	rows, err := r.pool.Query(ctx, getExercisesForUserQuery, params.UserID, params.Limit, params.Offset)
+	rows, err := r.pool.Query(ctx, listExercisesQuery(params.Sort),
+		params.UserID,
+		params.Search,
+		params.TargetMuscle,
+		params.Equipment,
+		params.IncludeArchived,
+		params.Limit,
+		params.Offset,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching exercises for user: %w", err)
 	}
@@ -76,27 +107,230 @@ func (r *Repository) GetExercisesForUser(ctx context.Context, params *GetExercis
 
 	exercises := make([]models.Exercise, 0)
 	for rows.Next() {
-		var exercise exercise
-		err := rows.Scan(
-			&exercise.id,
-			&exercise.name,
-			&exercise.description,
-			&exercise.targetMuscle,
-			&exercise.pictureUrl,
-			&exercise.createdAt,
-			&exercise.updatedAt,
-			&exercise.userId,
-		)
+		exercise, err := scanExercise(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning exercise row: %w", err)
 		}
-		exercises = append(exercises, models.Exercise{
-			ID:           exercise.id,
-			Name:         exercise.name,
-			Description:  exercise.description,
-			TargetMuscle: exercise.targetMuscle,
-			PictureURL:   exercise.pictureUrl,
-		})
+		exercises = append(exercises, exercise.toModel())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error fetching exercises for user: %w", err)
 	}
 	return exercises, nil
 }
+
+func (r *Repository) CountExercisesForUser(ctx context.Context, params *GetExerciseForUserParams) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, countExercisesForUserQuery,
+		params.UserID,
+		params.Search,
+		params.TargetMuscle,
+		params.Equipment,
+		params.IncludeArchived,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting exercises for user: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) GetExerciseByID(ctx context.Context, userID, exerciseID int64) (models.Exercise, error) {
+	exercise, err := scanExercise(r.pool.QueryRow(ctx, getExerciseByIDQuery, exerciseID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Exercise{}, ErrExerciseNotFound
+	}
+	if err != nil {
+		return models.Exercise{}, fmt.Errorf("failed to get exercise: %w", err)
+	}
+	return exercise.toModel(), nil
+}
+
+func (r *Repository) UpdateExercise(ctx context.Context, params *UpdateExerciseParams) (models.Exercise, error) {
+	exercise, err := scanExercise(r.pool.QueryRow(ctx, updateExerciseQuery,
+		params.ID,
+		params.UserID,
+		params.Name,
+		params.Description,
+		params.TargetMuscle,
+		params.Equipment,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Exercise{}, ErrExerciseNotFound
+	}
+	if err != nil {
+		return models.Exercise{}, fmt.Errorf("failed to update exercise: %w", err)
+	}
+	return exercise.toModel(), nil
+}
+
+func (r *Repository) SetExerciseImage(ctx context.Context, userID, exerciseID int64, imageKey, thumbnailKey string) (models.Exercise, error) {
+	exercise, err := scanExercise(r.pool.QueryRow(ctx, setExerciseImageQuery, exerciseID, userID, imageKey, thumbnailKey))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Exercise{}, ErrExerciseNotFound
+	}
+	if err != nil {
+		return models.Exercise{}, fmt.Errorf("failed to set exercise image: %w", err)
+	}
+	return exercise.toModel(), nil
+}
+
+// DeleteExercise removes an exercise owned by the user. Exercises that are
+// still referenced by history, routines or records are archived instead; the
+// returned bool reports whether that happened. The storage keys of a deleted
+// exercise's image are returned for the caller to remove after the commit.
+func (r *Repository) DeleteExercise(ctx context.Context, userID, exerciseID int64) (bool, []string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockOwnedExercise(ctx, tx, userID, exerciseID); err != nil {
+		return false, nil, err
+	}
+	archived, imageKeys, err := removeExercise(ctx, tx, exerciseID)
+	if err != nil {
+		return false, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return archived, imageKeys, nil
+}
+
+// MergeExercises moves the user's history and routines from the source
+// exercise onto the target, then removes the source. The source's records
+// are dropped and the target's replayed from the merged history, as records
+// from the two histories would otherwise overlap. Like DeleteExercise it
+// returns the image keys of a deleted source.
+func (r *Repository) MergeExercises(ctx context.Context, userID, sourceID, targetID int64) ([]string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockOwnedExercise(ctx, tx, userID, sourceID); err != nil {
+		return nil, err
+	}
+	var id int64
+	err = tx.QueryRow(ctx, lockMergeTargetQuery, targetID, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExerciseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock merge target: %w", err)
+	}
+	for _, query := range []string{mergeWorkoutsQuery, mergeRoutineExercisesQuery} {
+		if _, err := tx.Exec(ctx, query, sourceID, targetID, userID); err != nil {
+			return nil, fmt.Errorf("failed to merge exercises: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, deleteMergedRecordsQuery, sourceID, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged personal records: %w", err)
+	}
+	if _, err := sessionRepository.RebuildPersonalRecords(ctx, tx, userID, targetID); err != nil {
+		return nil, err
+	}
+	_, imageKeys, err := removeExercise(ctx, tx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return imageKeys, nil
+}
+
+func lockOwnedExercise(ctx context.Context, tx pgx.Tx, userID, exerciseID int64) error {
+	var id int64
+	err := tx.QueryRow(ctx, lockOwnedExerciseQuery, exerciseID, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrExerciseNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock exercise: %w", err)
+	}
+	return nil
+}
+
+// removeExercise archives a referenced exercise, which keeps its image, and
+// deletes any other, returning the image keys it held.
+func removeExercise(ctx context.Context, tx pgx.Tx, exerciseID int64) (bool, []string, error) {
+	var referenced bool
+	if err := tx.QueryRow(ctx, exerciseReferencedQuery, exerciseID).Scan(&referenced); err != nil {
+		return false, nil, fmt.Errorf("failed to check exercise references: %w", err)
+	}
+	if referenced {
+		if _, err := tx.Exec(ctx, archiveExerciseQuery, exerciseID); err != nil {
+			return false, nil, fmt.Errorf("failed to archive exercise: %w", err)
+		}
+		return true, nil, nil
+	}
+	var imageKey, thumbnailKey string
+	if err := tx.QueryRow(ctx, deleteExerciseQuery, exerciseID).Scan(&imageKey, &thumbnailKey); err != nil {
+		return false, nil, fmt.Errorf("failed to delete exercise: %w", err)
+	}
+	return false, []string{imageKey, thumbnailKey}, nil
+}
+
+func (e *exercise) toModel() models.Exercise {
+	return models.Exercise{
+		ID:           e.id,
+		Name:         e.name,
+		Description:  e.description,
+		TargetMuscle: e.targetMuscle,
+		PictureURL:   e.pictureUrl,
+		Equipment:    e.equipment,
+		IsGlobal:     e.userId == nil,
+		Archived:     e.archivedAt != nil,
+		ImageKey:     e.imageKey,
+		ThumbnailKey: e.thumbnailKey,
+	}
+}
+
+func scanExercise(row pgx.Row) (*exercise, error) {
+	var e exercise
+	err := row.Scan(
+		&e.id,
+		&e.name,
+		&e.description,
+		&e.targetMuscle,
+		&e.pictureUrl,
+		&e.equipment,
+		&e.createdAt,
+		&e.updatedAt,
+		&e.userId,
+		&e.archivedAt,
+		&e.imageKey,
+		&e.thumbnailKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetExerciseOwners maps each existing exercise among exerciseIDs to its
+// owner. Global exercises map to nil; unknown IDs are left out.
+func (r *Repository) GetExerciseOwners(ctx context.Context, exerciseIDs []int64) (map[int64]*int64, error) {
+	rows, err := r.pool.Query(ctx, getExerciseOwnersQuery, exerciseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[int64]*int64, len(exerciseIDs))
+	for rows.Next() {
+		var id int64
+		var owner *int64
+		if err := rows.Scan(&id, &owner); err != nil {
+			return nil, fmt.Errorf("failed to scan exercise owner: %w", err)
+		}
+		owners[id] = owner
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get exercise owners: %w", err)
+	}
+	return owners, nil
+}
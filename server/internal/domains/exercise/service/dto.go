@@ -1,5 +1,7 @@
 package service
 
+import "github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
+
 type CreateExerciseRequest struct {
 	Name         string `json:"name" binding:"required"`
 	Description  string `json:"description"`
@@ -7,9 +9,19 @@ type CreateExerciseRequest struct {
 }
 
 type GetExerciseForUserParams struct {
-	UserID int64 `json:"userID"`
-	Offset int   `json:"offset"`
-	Limit  int   `json:"limit"`
+	UserID          int64  `json:"userID"`
+	Search          string `json:"search"`
+	TargetMuscle    string `json:"targetMuscle"`
+	Equipment       string `json:"equipment"`
+	Sort            string `json:"sort"`
+	IncludeArchived bool   `json:"includeArchived"`
+	Offset          int    `json:"offset"`
+	Limit           int    `json:"limit"`
+}
+
+type GetExerciseListResult struct {
+	Exercises []models.Exercise
+	Total     int
 }
 
 type CreateExerciseForUserParams struct {
@@ -17,5 +29,15 @@ type CreateExerciseForUserParams struct {
 	Description  string `json:"description"`
 	TargetMuscle string `json:"targetMuscle"`
 	PictureURL   string `json:"pictureURL"`
+	Equipment    string `json:"equipment"`
 	UserID       int64  `json:"userID"`
 }
+
+type UpdateExerciseParams struct {
+	ID           int64   `json:"id"`
+	UserID       int64   `json:"userID"`
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	TargetMuscle *string `json:"targetMuscle"`
+	Equipment    *string `json:"equipment"`
+}
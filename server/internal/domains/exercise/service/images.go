@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
+	"github.com/TBuckholz5/workouttracker/internal/util/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	MaxImageBytes     = 5 << 20
+	maxImageDimension = 8000
+	thumbnailSize     = 320
+	imageKeyPrefix    = "exercises/"
+)
+
+var (
+	ErrImageTooLarge    = apperror.TooLarge("image_too_large", "image exceeds the maximum upload size")
+	ErrUnsupportedImage = apperror.UnsupportedMediaType("unsupported_image", "unsupported image type")
+	ErrImageNotFound    = apperror.NotFound("image_not_found", "image not found")
+)
+
+// imageExtensions lists the accepted upload types, detected from the content
+// rather than trusting the client supplied header.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+func (s *Service) UploadImage(reqContext context.Context, userID, exerciseID int64, data []byte) (models.Exercise, error) {
+	if len(data) > MaxImageBytes {
+		return models.Exercise{}, ErrImageTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return models.Exercise{}, ErrUnsupportedImage
+	}
+	thumbnail, err := makeThumbnail(data)
+	if err != nil {
+		return models.Exercise{}, err
+	}
+	existing, err := s.repo.GetExerciseByID(reqContext, userID, exerciseID)
+	if err != nil {
+		return models.Exercise{}, err
+	}
+	if existing.IsGlobal {
+		return models.Exercise{}, ErrExerciseNotFound
+	}
+
+	// A fresh key per upload keeps cached copies of the old image from being served.
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return models.Exercise{}, fmt.Errorf("failed to generate image key: %w", err)
+	}
+	prefix := fmt.Sprintf("%s%d/%s", imageKeyPrefix, exerciseID, hex.EncodeToString(token))
+	imageKey := prefix + ext
+	thumbnailKey := prefix + "_thumb.jpg"
+	if err := s.images.Put(reqContext, imageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return models.Exercise{}, err
+	}
+	if err := s.images.Put(reqContext, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		s.deleteImages(reqContext, imageKey)
+		return models.Exercise{}, err
+	}
+	exercise, err := s.repo.SetExerciseImage(reqContext, userID, exerciseID, imageKey, thumbnailKey)
+	if err != nil {
+		s.deleteImages(reqContext, imageKey, thumbnailKey)
+		return models.Exercise{}, err
+	}
+	s.deleteImages(reqContext, existing.ImageKey, existing.ThumbnailKey)
+	return exercise, nil
+}
+
+func (s *Service) OpenImage(reqContext context.Context, key string) (io.ReadCloser, string, error) {
+	if !strings.HasPrefix(key, imageKeyPrefix) {
+		return nil, "", ErrImageNotFound
+	}
+	body, contentType, err := s.images.Get(reqContext, key)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, "", ErrImageNotFound
+	}
+	return body, contentType, err
+}
+
+// deleteImages removes stale objects on a best effort basis; a leftover
+// object is harmless, so failures are only logged.
+func (s *Service) deleteImages(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.images.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).Error("failed to delete exercise image", "key", key, "error", err)
+		}
+	}
+}
+
+// makeThumbnail scales the image so its longest side fits thumbnailSize and
+// encodes it as JPEG on a white background.
+func makeThumbnail(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width > maxImageDimension || cfg.Height > maxImageDimension {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			height = max(1, height*thumbnailSize/width)
+			width = thumbnailSize
+		} else {
+			width = max(1, width*thumbnailSize/height)
+			height = thumbnailSize
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
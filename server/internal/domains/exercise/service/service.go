@@ -2,23 +2,45 @@ package service
 
 import (
 	"context"
+	"io"
+	"strings"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
 	repo "github.com/TBuckholz5/workouttracker/internal/domains/exercise/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/storage"
 )
 
 type ExerciseService interface {
 	CreateExercise(reqContext context.Context, params *CreateExerciseForUserParams) (models.Exercise, error)
-	GetExercisesForUser(reqContext context.Context, params *GetExerciseForUserParams) ([]models.Exercise, error)
+	GetExercisesForUser(reqContext context.Context, params *GetExerciseForUserParams) (*GetExerciseListResult, error)
+	GetExercise(reqContext context.Context, userID, exerciseID int64) (models.Exercise, error)
+	UpdateExercise(reqContext context.Context, params *UpdateExerciseParams) (models.Exercise, error)
+	DeleteExercise(reqContext context.Context, userID, exerciseID int64) (bool, error)
+	MergeExercises(reqContext context.Context, userID, sourceID, targetID int64) (models.Exercise, error)
+	UploadImage(reqContext context.Context, userID, exerciseID int64, data []byte) (models.Exercise, error)
+	OpenImage(reqContext context.Context, key string) (io.ReadCloser, string, error)
 }
 
+var (
+	ErrInvalidSort = apperror.Validation("invalid_sort", "invalid sort order",
+		apperror.Field("sort", "invalid", "must be name, recent, frequent or relevance"))
+	ErrInvalidName = apperror.Validation("invalid_name", "exercise name must not be empty",
+		apperror.Field("name", "required", "must not be empty"))
+	ErrInvalidMerge = apperror.Validation("invalid_merge", "cannot merge an exercise into itself",
+		apperror.Field("targetID", "invalid", "must differ from the merged exercise"))
+	ErrExerciseNotFound = repo.ErrExerciseNotFound
+)
+
 type Service struct {
-	repo repo.ExerciseRepository
+	repo   repo.ExerciseRepository
+	images storage.Storage
 }
 
-func NewService(r repo.ExerciseRepository) *Service {
+func NewService(r repo.ExerciseRepository, images storage.Storage) *Service {
 	return &Service{
-		repo: r,
+		repo:   r,
+		images: images,
 	}
 }
 
@@ -28,18 +50,107 @@ func (s *Service) CreateExercise(reqContext context.Context, params *CreateExerc
 		Description:  params.Description,
 		TargetMuscle: params.TargetMuscle,
 		PictureURL:   params.PictureURL,
+		Equipment:    params.Equipment,
 		UserID:       params.UserID,
 	})
 }
 
-func (s *Service) GetExercisesForUser(reqContext context.Context, params *GetExerciseForUserParams) ([]models.Exercise, error) {
-	exercises, err := s.repo.GetExercisesForUser(reqContext, &repo.GetExerciseForUserParams{
-		UserID: params.UserID,
-		Offset: params.Offset,
-		Limit:  params.Limit,
-	})
+func (s *Service) GetExercisesForUser(reqContext context.Context, params *GetExerciseForUserParams) (*GetExerciseListResult, error) {
+	search := optionalString(params.Search)
+	sort, err := resolveSort(params.Sort, search != nil)
+	if err != nil {
+		return nil, err
+	}
+	repoParams := &repo.GetExerciseForUserParams{
+		UserID:          params.UserID,
+		Search:          search,
+		TargetMuscle:    optionalString(params.TargetMuscle),
+		Equipment:       optionalString(params.Equipment),
+		Sort:            sort,
+		IncludeArchived: params.IncludeArchived,
+		Offset:          params.Offset,
+		Limit:           params.Limit,
+	}
+	exercises, err := s.repo.GetExercisesForUser(reqContext, repoParams)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountExercisesForUser(reqContext, repoParams)
 	if err != nil {
 		return nil, err
 	}
-	return exercises, nil
+	return &GetExerciseListResult{
+		Exercises: exercises,
+		Total:     total,
+	}, nil
+}
+
+func (s *Service) GetExercise(reqContext context.Context, userID, exerciseID int64) (models.Exercise, error) {
+	return s.repo.GetExerciseByID(reqContext, userID, exerciseID)
+}
+
+func (s *Service) UpdateExercise(reqContext context.Context, params *UpdateExerciseParams) (models.Exercise, error) {
+	if params.Name != nil && strings.TrimSpace(*params.Name) == "" {
+		return models.Exercise{}, ErrInvalidName
+	}
+	return s.repo.UpdateExercise(reqContext, &repo.UpdateExerciseParams{
+		ID:           params.ID,
+		UserID:       params.UserID,
+		Name:         params.Name,
+		Description:  params.Description,
+		TargetMuscle: params.TargetMuscle,
+		Equipment:    params.Equipment,
+	})
+}
+
+// DeleteExercise removes the exercise and reports whether it was archived
+// instead because past sessions still reference it. The image of a removed
+// exercise is deleted once the removal is committed.
+func (s *Service) DeleteExercise(reqContext context.Context, userID, exerciseID int64) (bool, error) {
+	archived, imageKeys, err := s.repo.DeleteExercise(reqContext, userID, exerciseID)
+	if err != nil {
+		return false, err
+	}
+	s.deleteImages(reqContext, imageKeys...)
+	return archived, nil
+}
+
+func (s *Service) MergeExercises(reqContext context.Context, userID, sourceID, targetID int64) (models.Exercise, error) {
+	if sourceID == targetID {
+		return models.Exercise{}, ErrInvalidMerge
+	}
+	imageKeys, err := s.repo.MergeExercises(reqContext, userID, sourceID, targetID)
+	if err != nil {
+		return models.Exercise{}, err
+	}
+	s.deleteImages(reqContext, imageKeys...)
+	return s.repo.GetExerciseByID(reqContext, userID, targetID)
+}
+
+// resolveSort defaults to relevance when searching and to alphabetical order otherwise.
+func resolveSort(sort string, searching bool) (repo.ExerciseSort, error) {
+	switch repo.ExerciseSort(sort) {
+	case "":
+		if searching {
+			return repo.SortRelevance, nil
+		}
+		return repo.SortName, nil
+	case repo.SortName, repo.SortRecent, repo.SortFrequent:
+		return repo.ExerciseSort(sort), nil
+	case repo.SortRelevance:
+		if !searching {
+			return "", ErrInvalidSort
+		}
+		return repo.SortRelevance, nil
+	default:
+		return "", ErrInvalidSort
+	}
+}
+
+func optionalString(val string) *string {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil
+	}
+	return &val
 }
@@ -1,8 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/models"
@@ -25,6 +32,74 @@ func (m *mockExerciserepository) GetExercisesForUser(ctx context.Context, params
 	return args.Get(0).([]models.Exercise), args.Error(1)
 }
 
+func (m *mockExerciserepository) CountExercisesForUser(ctx context.Context, params *repository.GetExerciseForUserParams) (int, error) {
+	args := m.Called(ctx, params)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockExerciserepository) GetExerciseByID(ctx context.Context, userID, exerciseID int64) (models.Exercise, error) {
+	args := m.Called(ctx, userID, exerciseID)
+	return args.Get(0).(models.Exercise), args.Error(1)
+}
+
+func (m *mockExerciserepository) UpdateExercise(ctx context.Context, params *repository.UpdateExerciseParams) (models.Exercise, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.Exercise), args.Error(1)
+}
+
+func (m *mockExerciserepository) SetExerciseImage(ctx context.Context, userID, exerciseID int64, imageKey, thumbnailKey string) (models.Exercise, error) {
+	args := m.Called(ctx, userID, exerciseID, imageKey, thumbnailKey)
+	return args.Get(0).(models.Exercise), args.Error(1)
+}
+
+func (m *mockExerciserepository) DeleteExercise(ctx context.Context, userID, exerciseID int64) (bool, []string, error) {
+	args := m.Called(ctx, userID, exerciseID)
+	imageKeys, _ := args.Get(1).([]string)
+	return args.Bool(0), imageKeys, args.Error(2)
+}
+
+func (m *mockExerciserepository) MergeExercises(ctx context.Context, userID, sourceID, targetID int64) ([]string, error) {
+	args := m.Called(ctx, userID, sourceID, targetID)
+	imageKeys, _ := args.Get(0).([]string)
+	return imageKeys, args.Error(1)
+}
+
+type mockStorage struct {
+	mock.Mock
+	objects map[string][]byte
+}
+
+func (m *mockStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, size, contentType)
+	data, _ := io.ReadAll(body)
+	if m.objects == nil {
+		m.objects = map[string][]byte{}
+	}
+	m.objects[key] = data
+	return args.Error(0)
+}
+
+func (m *mockStorage) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	args := m.Called(ctx, key)
+	return io.NopCloser(bytes.NewReader(m.objects[key])), args.String(0), args.Error(1)
+}
+
+func (m *mockStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
 func TestCreateExercise_Success(t *testing.T) {
 	mockrepository := new(mockExerciserepository)
 	req := &CreateExerciseForUserParams{
@@ -39,7 +114,7 @@ func TestCreateExercise_Success(t *testing.T) {
 		return p.Name == req.Name && p.Description == req.Description && p.TargetMuscle == req.TargetMuscle && p.UserID == req.UserID
 	})).Return(expected, nil)
 
-	svc := NewService(mockrepository)
+	svc := NewService(mockrepository, nil)
 	result, err := svc.CreateExercise(context.Background(), req)
 	assert.Nil(t, err)
 	assert.Equal(t, expected, result)
@@ -57,13 +132,54 @@ func TestCreateExercise_repositoryError(t *testing.T) {
 	}
 	mockrepository.On("CreateExercise", mock.Anything, mock.Anything).Return(models.Exercise{}, errors.New("db error"))
 
-	svc := NewService(mockrepository)
+	svc := NewService(mockrepository, nil)
 	result, err := svc.CreateExercise(context.Background(), req)
 	assert.NotNil(t, err)
 	assert.Equal(t, models.Exercise{}, result)
 	mockrepository.AssertNumberOfCalls(t, "CreateExercise", 1)
 }
 
+func TestCreateExercise_PassesEquipment(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	req := &CreateExerciseForUserParams{
+		UserID:       1,
+		Name:         "Landmine Press",
+		TargetMuscle: "Chest",
+		Equipment:    "barbell",
+	}
+	expected := models.Exercise{ID: 1, Name: req.Name, TargetMuscle: req.TargetMuscle, Equipment: req.Equipment}
+	mockrepository.On("CreateExercise", mock.Anything, mock.MatchedBy(func(p *repository.CreateExerciseParams) bool {
+		return p.Equipment == "barbell"
+	})).Return(expected, nil)
+
+	svc := NewService(mockrepository, nil)
+	result, err := svc.CreateExercise(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+	assert.False(t, result.IsGlobal)
+}
+
+func TestGetExercisesForUser_IncludesGlobalCatalog(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	req := &GetExerciseForUserParams{
+		UserID: 1,
+		Offset: 0,
+		Limit:  10,
+	}
+	expected := []models.Exercise{
+		{ID: 1, Name: "Barbell Bench Press", IsGlobal: true},
+		{ID: 400, Name: "My Bench Variation"},
+	}
+	mockrepository.On("GetExercisesForUser", mock.Anything, mock.Anything).Return(expected, nil)
+	mockrepository.On("CountExercisesForUser", mock.Anything, mock.Anything).Return(2, nil)
+
+	svc := NewService(mockrepository, nil)
+	result, err := svc.GetExercisesForUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.True(t, result.Exercises[0].IsGlobal)
+	assert.False(t, result.Exercises[1].IsGlobal)
+}
+
 func TestGetExercisesForUser_Success(t *testing.T) {
 	mockrepository := new(mockExerciserepository)
 	req := &GetExerciseForUserParams{
@@ -75,11 +191,13 @@ func TestGetExercisesForUser_Success(t *testing.T) {
 	mockrepository.On("GetExercisesForUser", mock.Anything, mock.MatchedBy(func(p *repository.GetExerciseForUserParams) bool {
 		return p.UserID == req.UserID && p.Offset == req.Offset && p.Limit == req.Limit
 	})).Return(expected, nil)
+	mockrepository.On("CountExercisesForUser", mock.Anything, mock.Anything).Return(1, nil)
 
-	svc := NewService(mockrepository)
+	svc := NewService(mockrepository, nil)
 	result, err := svc.GetExercisesForUser(context.Background(), req)
 	assert.Nil(t, err)
-	assert.Equal(t, expected, result)
+	assert.Equal(t, expected, result.Exercises)
+	assert.Equal(t, 1, result.Total)
 	mockrepository.AssertNumberOfCalls(t, "GetExercisesForUser", 1)
 }
 
@@ -91,11 +209,13 @@ func TestGetExercisesForUser_EmptyResult(t *testing.T) {
 		Limit:  10,
 	}
 	mockrepository.On("GetExercisesForUser", mock.Anything, mock.Anything).Return([]models.Exercise{}, nil)
+	mockrepository.On("CountExercisesForUser", mock.Anything, mock.Anything).Return(0, nil)
 
-	svc := NewService(mockrepository)
+	svc := NewService(mockrepository, nil)
 	result, err := svc.GetExercisesForUser(context.Background(), req)
 	assert.Nil(t, err)
-	assert.Equal(t, []models.Exercise{}, result)
+	assert.Equal(t, []models.Exercise{}, result.Exercises)
+	assert.Equal(t, 0, result.Total)
 	mockrepository.AssertNumberOfCalls(t, "GetExercisesForUser", 1)
 }
 
@@ -108,9 +228,262 @@ func TestGetExercisesForUser_repositoryError(t *testing.T) {
 	}
 	mockrepository.On("GetExercisesForUser", mock.Anything, mock.Anything).Return([]models.Exercise{}, errors.New("db error"))
 
-	svc := NewService(mockrepository)
+	svc := NewService(mockrepository, nil)
 	result, err := svc.GetExercisesForUser(context.Background(), req)
 	assert.NotNil(t, err)
 	assert.Nil(t, result)
 	mockrepository.AssertNumberOfCalls(t, "GetExercisesForUser", 1)
 }
+
+func TestGetExercisesForUser_PassesFilters(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	req := &GetExerciseForUserParams{
+		UserID:       1,
+		Search:       "  bench ",
+		TargetMuscle: "Chest",
+		Sort:         "frequent",
+		Limit:        10,
+	}
+	matcher := mock.MatchedBy(func(p *repository.GetExerciseForUserParams) bool {
+		return p.Search != nil && *p.Search == "bench" &&
+			p.TargetMuscle != nil && *p.TargetMuscle == "Chest" &&
+			p.Equipment == nil &&
+			p.Sort == repository.SortFrequent
+	})
+	mockrepository.On("GetExercisesForUser", mock.Anything, matcher).Return([]models.Exercise{{ID: 1}}, nil)
+	mockrepository.On("CountExercisesForUser", mock.Anything, matcher).Return(14, nil)
+
+	svc := NewService(mockrepository, nil)
+	result, err := svc.GetExercisesForUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, 14, result.Total)
+	mockrepository.AssertExpectations(t)
+}
+
+func TestGetExercisesForUser_DefaultSort(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	mockrepository.On("GetExercisesForUser", mock.Anything, mock.MatchedBy(func(p *repository.GetExerciseForUserParams) bool {
+		return p.Search == nil && p.Sort == repository.SortName
+	})).Return([]models.Exercise{}, nil)
+	mockrepository.On("GetExercisesForUser", mock.Anything, mock.MatchedBy(func(p *repository.GetExerciseForUserParams) bool {
+		return p.Search != nil && p.Sort == repository.SortRelevance
+	})).Return([]models.Exercise{}, nil)
+	mockrepository.On("CountExercisesForUser", mock.Anything, mock.Anything).Return(0, nil)
+
+	svc := NewService(mockrepository, nil)
+	_, err := svc.GetExercisesForUser(context.Background(), &GetExerciseForUserParams{UserID: 1, Limit: 10})
+	assert.Nil(t, err)
+	_, err = svc.GetExercisesForUser(context.Background(), &GetExerciseForUserParams{UserID: 1, Search: "squat", Limit: 10})
+	assert.Nil(t, err)
+	mockrepository.AssertNumberOfCalls(t, "GetExercisesForUser", 2)
+}
+
+func TestGetExercisesForUser_InvalidSort(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	svc := NewService(mockrepository, nil)
+
+	_, err := svc.GetExercisesForUser(context.Background(), &GetExerciseForUserParams{UserID: 1, Sort: "popular"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = svc.GetExercisesForUser(context.Background(), &GetExerciseForUserParams{UserID: 1, Sort: "relevance"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	mockrepository.AssertNotCalled(t, "GetExercisesForUser", mock.Anything, mock.Anything)
+}
+
+func TestUpdateExercise_Success(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	name := "Paused Bench Press"
+	expected := models.Exercise{ID: 5, Name: name}
+	mockrepository.On("UpdateExercise", mock.Anything, mock.MatchedBy(func(p *repository.UpdateExerciseParams) bool {
+		return p.ID == 5 && p.UserID == 1 && *p.Name == name && p.Description == nil
+	})).Return(expected, nil)
+
+	svc := NewService(mockrepository, nil)
+	result, err := svc.UpdateExercise(context.Background(), &UpdateExerciseParams{ID: 5, UserID: 1, Name: &name})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestUpdateExercise_EmptyName(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	name := "  "
+
+	svc := NewService(mockrepository, nil)
+	_, err := svc.UpdateExercise(context.Background(), &UpdateExerciseParams{ID: 5, UserID: 1, Name: &name})
+	assert.ErrorIs(t, err, ErrInvalidName)
+	mockrepository.AssertNotCalled(t, "UpdateExercise", mock.Anything, mock.Anything)
+}
+
+func TestUpdateExercise_GlobalNotFound(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	name := "Renamed"
+	mockrepository.On("UpdateExercise", mock.Anything, mock.Anything).Return(models.Exercise{}, repository.ErrExerciseNotFound)
+
+	svc := NewService(mockrepository, nil)
+	_, err := svc.UpdateExercise(context.Background(), &UpdateExerciseParams{ID: 1, UserID: 1, Name: &name})
+	assert.ErrorIs(t, err, ErrExerciseNotFound)
+}
+
+func TestDeleteExercise_Archived(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	mockrepository.On("DeleteExercise", mock.Anything, int64(1), int64(5)).Return(true, nil, nil)
+
+	svc := NewService(mockrepository, nil)
+	archived, err := svc.DeleteExercise(context.Background(), 1, 5)
+	assert.Nil(t, err)
+	assert.True(t, archived)
+}
+
+func TestDeleteExercise_DeletesImages(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+	mockrepository.On("DeleteExercise", mock.Anything, int64(1), int64(5)).
+		Return(false, []string{"exercises/5/abc.png", "exercises/5/abc_thumb.jpg"}, nil)
+	store.On("Delete", mock.Anything, "exercises/5/abc.png").Return(nil)
+	store.On("Delete", mock.Anything, "exercises/5/abc_thumb.jpg").Return(errors.New("storage down"))
+
+	svc := NewService(mockrepository, store)
+	archived, err := svc.DeleteExercise(context.Background(), 1, 5)
+	assert.Nil(t, err)
+	assert.False(t, archived)
+	store.AssertExpectations(t)
+}
+
+func TestMergeExercises_Success(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	target := models.Exercise{ID: 9, Name: "Barbell Bench Press", IsGlobal: true}
+	store := new(mockStorage)
+	mockrepository.On("MergeExercises", mock.Anything, int64(1), int64(5), int64(9)).
+		Return([]string{"exercises/5/abc.png", "exercises/5/abc_thumb.jpg"}, nil)
+	mockrepository.On("GetExerciseByID", mock.Anything, int64(1), int64(9)).Return(target, nil)
+	store.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	svc := NewService(mockrepository, store)
+	result, err := svc.MergeExercises(context.Background(), 1, 5, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, target, result)
+	mockrepository.AssertExpectations(t)
+	store.AssertNumberOfCalls(t, "Delete", 2)
+}
+
+func TestMergeExercises_SameExercise(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+
+	svc := NewService(mockrepository, nil)
+	_, err := svc.MergeExercises(context.Background(), 1, 5, 5)
+	assert.ErrorIs(t, err, ErrInvalidMerge)
+	mockrepository.AssertNotCalled(t, "MergeExercises", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMergeExercises_RepositoryError(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	mockrepository.On("MergeExercises", mock.Anything, int64(1), int64(5), int64(9)).Return(nil, repository.ErrExerciseNotFound)
+
+	svc := NewService(mockrepository, nil)
+	_, err := svc.MergeExercises(context.Background(), 1, 5, 9)
+	assert.ErrorIs(t, err, ErrExerciseNotFound)
+	mockrepository.AssertNotCalled(t, "GetExerciseByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadImage_Success(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+	mockrepository.On("GetExerciseByID", mock.Anything, int64(1), int64(5)).Return(models.Exercise{ID: 5}, nil)
+	store.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "exercises/5/") && strings.HasSuffix(key, ".png")
+	}), mock.Anything, "image/png").Return(nil)
+	store.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasSuffix(key, "_thumb.jpg")
+	}), mock.Anything, "image/jpeg").Return(nil)
+	mockrepository.On("SetExerciseImage", mock.Anything, int64(1), int64(5), mock.Anything, mock.Anything).
+		Return(models.Exercise{ID: 5, ImageKey: "exercises/5/a.png", ThumbnailKey: "exercises/5/a_thumb.jpg"}, nil)
+
+	svc := NewService(mockrepository, store)
+	result, err := svc.UploadImage(context.Background(), 1, 5, testPNG(t, 1000, 500))
+	assert.Nil(t, err)
+	assert.Equal(t, "exercises/5/a.png", result.ImageKey)
+	store.AssertNumberOfCalls(t, "Put", 2)
+	store.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	var thumbnail []byte
+	for key, data := range store.objects {
+		if strings.HasSuffix(key, "_thumb.jpg") {
+			thumbnail = data
+		}
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumbnail))
+	assert.Nil(t, err)
+	assert.Equal(t, thumbnailSize, cfg.Width)
+	assert.Equal(t, thumbnailSize/2, cfg.Height)
+}
+
+func TestUploadImage_ReplacesPreviousImage(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+	mockrepository.On("GetExerciseByID", mock.Anything, int64(1), int64(5)).
+		Return(models.Exercise{ID: 5, ImageKey: "exercises/5/old.png", ThumbnailKey: "exercises/5/old_thumb.jpg"}, nil)
+	store.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockrepository.On("SetExerciseImage", mock.Anything, int64(1), int64(5), mock.Anything, mock.Anything).Return(models.Exercise{ID: 5}, nil)
+	store.On("Delete", mock.Anything, "exercises/5/old.png").Return(nil)
+	store.On("Delete", mock.Anything, "exercises/5/old_thumb.jpg").Return(nil)
+
+	svc := NewService(mockrepository, store)
+	_, err := svc.UploadImage(context.Background(), 1, 5, testPNG(t, 10, 10))
+	assert.Nil(t, err)
+	store.AssertExpectations(t)
+}
+
+func TestUploadImage_UnsupportedType(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+
+	svc := NewService(mockrepository, store)
+	_, err := svc.UploadImage(context.Background(), 1, 5, []byte("GIF89a not really an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+	_, err = svc.UploadImage(context.Background(), 1, 5, []byte("%PDF-1.7"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadImage_TooLarge(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+
+	svc := NewService(mockrepository, store)
+	_, err := svc.UploadImage(context.Background(), 1, 5, make([]byte, MaxImageBytes+1))
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+func TestUploadImage_GlobalExercise(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+	mockrepository.On("GetExerciseByID", mock.Anything, int64(1), int64(2)).Return(models.Exercise{ID: 2, IsGlobal: true}, nil)
+
+	svc := NewService(mockrepository, store)
+	_, err := svc.UploadImage(context.Background(), 1, 2, testPNG(t, 10, 10))
+	assert.ErrorIs(t, err, ErrExerciseNotFound)
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadImage_CleansUpOnRepositoryError(t *testing.T) {
+	mockrepository := new(mockExerciserepository)
+	store := new(mockStorage)
+	mockrepository.On("GetExerciseByID", mock.Anything, int64(1), int64(5)).Return(models.Exercise{ID: 5}, nil)
+	store.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockrepository.On("SetExerciseImage", mock.Anything, int64(1), int64(5), mock.Anything, mock.Anything).
+		Return(models.Exercise{}, errors.New("db error"))
+	store.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	svc := NewService(mockrepository, store)
+	_, err := svc.UploadImage(context.Background(), 1, 5, testPNG(t, 10, 10))
+	assert.NotNil(t, err)
+	store.AssertNumberOfCalls(t, "Delete", 2)
+}
+
+func TestOpenImage_RejectsForeignKeys(t *testing.T) {
+	store := new(mockStorage)
+
+	svc := NewService(new(mockExerciserepository), store)
+	_, _, err := svc.OpenImage(context.Background(), "private/secret.txt")
+	assert.ErrorIs(t, err, ErrImageNotFound)
+	store.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
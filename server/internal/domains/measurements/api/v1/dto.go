@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/models"
+)
+
+type CreateMeasurementRequest struct {
+	Type     string  `json:"type" binding:"required"`
+	BodyPart string  `json:"bodyPart"`
+	Value    float64 `json:"value" binding:"required"`
+	// MeasuredAt defaults to now.
+	MeasuredAt *time.Time `json:"measuredAt"`
+}
+
+type UpdateMeasurementRequest struct {
+	Value      *float64   `json:"value"`
+	MeasuredAt *time.Time `json:"measuredAt"`
+}
+
+type MeasurementResponse struct {
+	Measurement models.Measurement `json:"measurement"`
+}
+
+type ListMeasurementsResponse struct {
+	Measurements []models.Measurement `json:"measurements"`
+}
+
+type TrendResponse struct {
+	Type     string              `json:"type"`
+	BodyPart string              `json:"bodyPart,omitempty"`
+	Window   int                 `json:"window"`
+	Points   []models.TrendPoint `json:"points"`
+}
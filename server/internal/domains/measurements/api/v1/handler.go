@@ -0,0 +1,206 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/service"
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/timeparse"
+	"github.com/TBuckholz5/workouttracker/internal/util/validate"
+)
+
+type Handler struct {
+	service service.MeasurementsService
+}
+
+func NewHandler(s service.MeasurementsService) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload CreateMeasurementRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	params := &service.CreateMeasurementParams{
+		UserID:   userID.(int64),
+		Type:     payload.Type,
+		BodyPart: payload.BodyPart,
+		Value:    payload.Value,
+	}
+	if payload.MeasuredAt != nil {
+		params.MeasuredAt = payload.MeasuredAt.UTC()
+	}
+	measurement, err := h.service.Create(r.Context(), params)
+	writeMeasurementResult(w, r, measurement, err)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	measurementID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	var payload UpdateMeasurementRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if payload.MeasuredAt != nil {
+		measuredAt := payload.MeasuredAt.UTC()
+		payload.MeasuredAt = &measuredAt
+	}
+	measurement, err := h.service.Update(r.Context(), &service.UpdateMeasurementParams{
+		UserID:        userID.(int64),
+		MeasurementID: measurementID,
+		Value:         payload.Value,
+		MeasuredAt:    payload.MeasuredAt,
+	})
+	writeMeasurementResult(w, r, measurement, err)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	measurementID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	if err := h.service.Delete(r.Context(), userID.(int64), measurementID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	queryParams := r.URL.Query()
+	from, to, err := parseRange(queryParams.Get("from"), queryParams.Get("to"))
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("to"))
+		return
+	}
+	measurements, err := h.service.List(r.Context(), &service.ListMeasurementsParams{
+		UserID:   userID.(int64),
+		Type:     queryParams.Get("type"),
+		BodyPart: queryParams.Get("bodyPart"),
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(ListMeasurementsResponse{Measurements: measurements}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetTrend(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	queryParams := r.URL.Query()
+	params := service.TrendParams{
+		UserID:   userID.(int64),
+		Type:     queryParams.Get("type"),
+		BodyPart: queryParams.Get("bodyPart"),
+		Window:   defaultTrendWindow,
+	}
+	if val := queryParams.Get("window"); val != "" {
+		window, err := strconv.Atoi(val)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("window"))
+			return
+		}
+		params.Window = window
+	}
+	var err error
+	params.From, params.To, err = parseRange(queryParams.Get("from"), queryParams.Get("to"))
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("to"))
+		return
+	}
+	points, err := h.service.GetTrend(r.Context(), &params)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(TrendResponse{
+		Type:     params.Type,
+		BodyPart: params.BodyPart,
+		Window:   params.Window,
+		Points:   points,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+const defaultTrendWindow = 7
+
+func parseRange(fromVal, toVal string) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if fromVal != "" {
+		t, err := timeparse.Parse(fromVal, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &t
+	}
+	if toVal != "" {
+		t, err := timeparse.Parse(toVal, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &t
+	}
+	return from, to, nil
+}
+
+func writeMeasurementResult(w http.ResponseWriter, r *http.Request, measurement *models.Measurement, err error) {
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(MeasurementResponse{Measurement: *measurement}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
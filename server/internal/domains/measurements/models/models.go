@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	TypeWeight        = "weight"
+	TypeBodyFat       = "body_fat"
+	TypeCircumference = "circumference"
+)
+
+// BodyParts lists where circumferences can be taken.
+var BodyParts = []string{
+	"neck", "shoulders", "chest", "waist", "hips",
+	"left_biceps", "right_biceps", "left_forearm", "right_forearm",
+	"left_thigh", "right_thigh", "left_calf", "right_calf",
+}
+
+// Measurement is one logged value. Weight is in the user's weight unit, body
+// fat in percent and circumferences, which carry a BodyPart, in centimetres.
+type Measurement struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	BodyPart   string    `json:"bodyPart,omitempty"`
+	Value      float64   `json:"value"`
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+// TrendPoint is the average of one day's entries together with the rolling
+// average over the days leading up to it.
+type TrendPoint struct {
+	Date           time.Time `json:"date"`
+	Value          float64   `json:"value"`
+	RollingAverage float64   `json:"rollingAverage"`
+}
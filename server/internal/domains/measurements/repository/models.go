@@ -0,0 +1,8 @@
+package repository
+
+import "time"
+
+type DailyAverage struct {
+	Day   time.Time `db:"day"`
+	Value float64   `db:"value"`
+}
@@ -0,0 +1,48 @@
+package repository
+
+const measurementColumns = `id, type, COALESCE(body_part, ''), value, measured_at`
+
+const createMeasurementQuery = `INSERT INTO measurements (user_id, type, body_part, value, measured_at)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+	RETURNING ` + measurementColumns
+
+const getMeasurementQuery = `SELECT ` + measurementColumns + `
+	FROM measurements
+	WHERE id = $1 AND user_id = $2`
+
+const updateMeasurementQuery = `UPDATE measurements
+	SET value = COALESCE($3, value),
+		measured_at = COALESCE($4, measured_at),
+		updated_at = NOW()
+	WHERE id = $1 AND user_id = $2
+	RETURNING ` + measurementColumns
+
+const deleteMeasurementQuery = `DELETE FROM measurements
+	WHERE id = $1 AND user_id = $2`
+
+const listMeasurementsQuery = `SELECT ` + measurementColumns + `
+	FROM measurements
+	WHERE user_id = $1
+		AND ($2::text IS NULL OR type = $2)
+		AND ($3::text IS NULL OR body_part = $3)
+		AND ($4::timestamp IS NULL OR measured_at >= $4)
+		AND ($5::timestamp IS NULL OR measured_at < $5)
+	ORDER BY measured_at DESC, id DESC`
+
+// getDailyAveragesQuery averages each day's entries so several weigh-ins on
+// one day count once in a rolling average.
+const getDailyAveragesQuery = `SELECT measured_at::date AS day, AVG(value)::float8
+	FROM measurements
+	WHERE user_id = $1
+		AND type = $2
+		AND body_part IS NOT DISTINCT FROM NULLIF($3, '')
+		AND ($4::timestamp IS NULL OR measured_at >= $4)
+		AND ($5::timestamp IS NULL OR measured_at < $5)
+	GROUP BY day
+	ORDER BY day`
+
+const getBodyweightOnQuery = `SELECT bodyweight_on($1, $2::date)::float8`
+
+const getBodyweightForSessionQuery = `SELECT bodyweight_on(s.user_id, s.started_at::date)::float8
+	FROM sessions s
+	WHERE s.id = $1 AND s.user_id = $2`
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrMeasurementNotFound = apperror.NotFound("measurement_not_found", "measurement not found")
+	ErrSessionNotFound     = apperror.NotFound("session_not_found", "workout session not found")
+)
+
+type CreateMeasurementParams struct {
+	UserID     int64
+	Type       string
+	BodyPart   string
+	Value      float64
+	MeasuredAt time.Time
+}
+
+type UpdateMeasurementParams struct {
+	ID         int64
+	UserID     int64
+	Value      *float64
+	MeasuredAt *time.Time
+}
+
+type ListMeasurementsParams struct {
+	UserID   int64
+	Type     *string
+	BodyPart *string
+	From     *time.Time
+	To       *time.Time
+}
+
+type GetDailyAveragesParams struct {
+	UserID   int64
+	Type     string
+	BodyPart string
+	From     *time.Time
+	To       *time.Time
+}
+
+type MeasurementsRepository interface {
+	CreateMeasurement(ctx context.Context, params *CreateMeasurementParams) (models.Measurement, error)
+	GetMeasurement(ctx context.Context, userID, measurementID int64) (models.Measurement, error)
+	UpdateMeasurement(ctx context.Context, params *UpdateMeasurementParams) (models.Measurement, error)
+	DeleteMeasurement(ctx context.Context, userID, measurementID int64) error
+	ListMeasurements(ctx context.Context, params *ListMeasurementsParams) ([]models.Measurement, error)
+	GetDailyAverages(ctx context.Context, params *GetDailyAveragesParams) ([]*DailyAverage, error)
+	GetBodyweightOn(ctx context.Context, userID int64, day time.Time) (*float64, error)
+	GetBodyweightForSession(ctx context.Context, userID, sessionID int64) (*float64, error)
+}
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		pool: pool,
+	}
+}
+
+func (r *Repository) CreateMeasurement(ctx context.Context, params *CreateMeasurementParams) (models.Measurement, error) {
+	measurement, err := scanMeasurement(r.pool.QueryRow(ctx, createMeasurementQuery,
+		params.UserID,
+		params.Type,
+		params.BodyPart,
+		params.Value,
+		params.MeasuredAt,
+	))
+	if err != nil {
+		return models.Measurement{}, fmt.Errorf("failed to create measurement: %w", err)
+	}
+	return measurement, nil
+}
+
+func (r *Repository) GetMeasurement(ctx context.Context, userID, measurementID int64) (models.Measurement, error) {
+	measurement, err := scanMeasurement(r.pool.QueryRow(ctx, getMeasurementQuery, measurementID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Measurement{}, ErrMeasurementNotFound
+	}
+	if err != nil {
+		return models.Measurement{}, fmt.Errorf("failed to get measurement: %w", err)
+	}
+	return measurement, nil
+}
+
+func (r *Repository) UpdateMeasurement(ctx context.Context, params *UpdateMeasurementParams) (models.Measurement, error) {
+	measurement, err := scanMeasurement(r.pool.QueryRow(ctx, updateMeasurementQuery,
+		params.ID,
+		params.UserID,
+		params.Value,
+		params.MeasuredAt,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Measurement{}, ErrMeasurementNotFound
+	}
+	if err != nil {
+		return models.Measurement{}, fmt.Errorf("failed to update measurement: %w", err)
+	}
+	return measurement, nil
+}
+
+func (r *Repository) DeleteMeasurement(ctx context.Context, userID, measurementID int64) error {
+	tag, err := r.pool.Exec(ctx, deleteMeasurementQuery, measurementID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete measurement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMeasurementNotFound
+	}
+	return nil
+}
+
+// ListMeasurements returns the user's entries matching the filters, newest
+// first.
+func (r *Repository) ListMeasurements(ctx context.Context, params *ListMeasurementsParams) ([]models.Measurement, error) {
+	rows, err := r.pool.Query(ctx, listMeasurementsQuery,
+		params.UserID,
+		params.Type,
+		params.BodyPart,
+		params.From,
+		params.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+	defer rows.Close()
+
+	measurements := make([]models.Measurement, 0)
+	for rows.Next() {
+		measurement, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan measurement: %w", err)
+		}
+		measurements = append(measurements, measurement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+	return measurements, nil
+}
+
+// GetDailyAverages returns one average per day with entries, oldest first.
+func (r *Repository) GetDailyAverages(ctx context.Context, params *GetDailyAveragesParams) ([]*DailyAverage, error) {
+	rows, err := r.pool.Query(ctx, getDailyAveragesQuery,
+		params.UserID,
+		params.Type,
+		params.BodyPart,
+		params.From,
+		params.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily averages: %w", err)
+	}
+	defer rows.Close()
+
+	averages := make([]*DailyAverage, 0)
+	for rows.Next() {
+		var average DailyAverage
+		if err := rows.Scan(&average.Day, &average.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan daily average: %w", err)
+		}
+		averages = append(averages, &average)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily averages: %w", err)
+	}
+	return averages, nil
+}
+
+// GetBodyweightOn returns the latest weight logged on or before day, or nil
+// when there is none.
+func (r *Repository) GetBodyweightOn(ctx context.Context, userID int64, day time.Time) (*float64, error) {
+	var bodyweight pgtype.Float8
+	if err := r.pool.QueryRow(ctx, getBodyweightOnQuery, userID, day).Scan(&bodyweight); err != nil {
+		return nil, fmt.Errorf("failed to get bodyweight: %w", err)
+	}
+	if !bodyweight.Valid {
+		return nil, nil
+	}
+	return &bodyweight.Float64, nil
+}
+
+// GetBodyweightForSession is GetBodyweightOn for the day the session started.
+func (r *Repository) GetBodyweightForSession(ctx context.Context, userID, sessionID int64) (*float64, error) {
+	var bodyweight pgtype.Float8
+	err := r.pool.QueryRow(ctx, getBodyweightForSessionQuery, sessionID, userID).Scan(&bodyweight)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bodyweight for session: %w", err)
+	}
+	if !bodyweight.Valid {
+		return nil, nil
+	}
+	return &bodyweight.Float64, nil
+}
+
+func scanMeasurement(row pgx.Row) (models.Measurement, error) {
+	var measurement models.Measurement
+	err := row.Scan(
+		&measurement.ID,
+		&measurement.Type,
+		&measurement.BodyPart,
+		&measurement.Value,
+		&measurement.MeasuredAt,
+	)
+	if err != nil {
+		return models.Measurement{}, err
+	}
+	return measurement, nil
+}
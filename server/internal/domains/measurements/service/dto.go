@@ -0,0 +1,37 @@
+package service
+
+import "time"
+
+type CreateMeasurementParams struct {
+	UserID   int64
+	Type     string
+	BodyPart string
+	Value    float64
+	// MeasuredAt defaults to now when zero.
+	MeasuredAt time.Time
+}
+
+type UpdateMeasurementParams struct {
+	UserID        int64
+	MeasurementID int64
+	Value         *float64
+	MeasuredAt    *time.Time
+}
+
+type ListMeasurementsParams struct {
+	UserID   int64
+	Type     string
+	BodyPart string
+	From     *time.Time
+	To       *time.Time
+}
+
+type TrendParams struct {
+	UserID   int64
+	Type     string
+	BodyPart string
+	From     *time.Time
+	To       *time.Time
+	// Window is the number of days averaged into each point.
+	Window int
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+const maxTrendWindow = 90
+
+// maxValues bound entries per type: kilograms, percent and centimetres.
+var maxValues = map[string]float64{
+	models.TypeWeight:        500,
+	models.TypeBodyFat:       100,
+	models.TypeCircumference: 300,
+}
+
+var (
+	ErrMeasurementNotFound = repository.ErrMeasurementNotFound
+	ErrSessionNotFound     = repository.ErrSessionNotFound
+	ErrInvalidType         = apperror.Validation("invalid_measurement_type", "type must be weight, body_fat or circumference",
+		apperror.Field("type", "invalid", "must be weight, body_fat or circumference"))
+	ErrInvalidBodyPart = apperror.Validation("invalid_body_part", "circumferences need a known body part; other types take none",
+		apperror.Field("bodyPart", "invalid", "must be a known body part for circumferences and empty otherwise"))
+	ErrInvalidValue = apperror.Validation("invalid_measurement_value", "value is out of range for the measurement type",
+		apperror.Field("value", "range", "is out of range for the measurement type"))
+	ErrInvalidTrendWindow = apperror.Validation("invalid_trend_window", "trend window must be between 1 and 90 days",
+		apperror.Field("window", "range", "must be between 1 and 90"))
+)
+
+type MeasurementsService interface {
+	Create(reqContext context.Context, params *CreateMeasurementParams) (*models.Measurement, error)
+	Update(reqContext context.Context, params *UpdateMeasurementParams) (*models.Measurement, error)
+	Delete(reqContext context.Context, userID, measurementID int64) error
+	List(reqContext context.Context, params *ListMeasurementsParams) ([]models.Measurement, error)
+	GetTrend(reqContext context.Context, params *TrendParams) ([]models.TrendPoint, error)
+	BodyweightOn(reqContext context.Context, userID int64, day time.Time) (*float64, error)
+	BodyweightForSession(reqContext context.Context, userID, sessionID int64) (*float64, error)
+}
+
+// Service takes and returns weights in the user's preferred unit, except for
+// the bodyweight lookups meant for other domains, which use kilograms.
+type Service struct {
+	repo        repository.MeasurementsRepository
+	weightUnits units.Resolver
+	now         func() time.Time
+}
+
+func NewService(r repository.MeasurementsRepository, weightUnits units.Resolver) *Service {
+	return &Service{
+		repo:        r,
+		weightUnits: weightUnits,
+		now:         time.Now,
+	}
+}
+
+func (s *Service) Create(reqContext context.Context, params *CreateMeasurementParams) (*models.Measurement, error) {
+	if err := validateKind(params.Type, params.BodyPart); err != nil {
+		return nil, err
+	}
+	unit, err := s.weightUnits.WeightUnit(reqContext, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	value := toStored(params.Type, params.Value, unit)
+	if err := validateValue(params.Type, value); err != nil {
+		return nil, err
+	}
+	measuredAt := params.MeasuredAt
+	if measuredAt.IsZero() {
+		measuredAt = s.now().UTC()
+	}
+	measurement, err := s.repo.CreateMeasurement(reqContext, &repository.CreateMeasurementParams{
+		UserID:     params.UserID,
+		Type:       params.Type,
+		BodyPart:   params.BodyPart,
+		Value:      value,
+		MeasuredAt: measuredAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	measurement.Value = fromStored(measurement.Type, measurement.Value, unit)
+	return &measurement, nil
+}
+
+func (s *Service) Update(reqContext context.Context, params *UpdateMeasurementParams) (*models.Measurement, error) {
+	existing, err := s.repo.GetMeasurement(reqContext, params.UserID, params.MeasurementID)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := s.weightUnits.WeightUnit(reqContext, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	update := &repository.UpdateMeasurementParams{
+		ID:         params.MeasurementID,
+		UserID:     params.UserID,
+		MeasuredAt: params.MeasuredAt,
+	}
+	if params.Value != nil {
+		value := toStored(existing.Type, *params.Value, unit)
+		if err := validateValue(existing.Type, value); err != nil {
+			return nil, err
+		}
+		update.Value = &value
+	}
+	measurement, err := s.repo.UpdateMeasurement(reqContext, update)
+	if err != nil {
+		return nil, err
+	}
+	measurement.Value = fromStored(measurement.Type, measurement.Value, unit)
+	return &measurement, nil
+}
+
+func (s *Service) Delete(reqContext context.Context, userID, measurementID int64) error {
+	return s.repo.DeleteMeasurement(reqContext, userID, measurementID)
+}
+
+func (s *Service) List(reqContext context.Context, params *ListMeasurementsParams) ([]models.Measurement, error) {
+	repoParams := &repository.ListMeasurementsParams{
+		UserID: params.UserID,
+		From:   params.From,
+		To:     params.To,
+	}
+	if params.Type != "" {
+		if _, ok := maxValues[params.Type]; !ok {
+			return nil, ErrInvalidType
+		}
+		repoParams.Type = &params.Type
+	}
+	if params.BodyPart != "" {
+		repoParams.BodyPart = &params.BodyPart
+	}
+	unit, err := s.weightUnits.WeightUnit(reqContext, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := s.repo.ListMeasurements(reqContext, repoParams)
+	if err != nil {
+		return nil, err
+	}
+	for i := range measurements {
+		measurements[i].Value = fromStored(measurements[i].Type, measurements[i].Value, unit)
+	}
+	return measurements, nil
+}
+
+// GetTrend returns one point per day with entries of the given kind. Each
+// point's rolling average covers the days within the window ending on it, so
+// entries from before From still count towards the first points.
+func (s *Service) GetTrend(reqContext context.Context, params *TrendParams) ([]models.TrendPoint, error) {
+	if err := validateKind(params.Type, params.BodyPart); err != nil {
+		return nil, err
+	}
+	window := params.Window
+	if window < 1 || window > maxTrendWindow {
+		return nil, ErrInvalidTrendWindow
+	}
+	repoParams := &repository.GetDailyAveragesParams{
+		UserID:   params.UserID,
+		Type:     params.Type,
+		BodyPart: params.BodyPart,
+		To:       params.To,
+	}
+	if params.From != nil {
+		from := params.From.AddDate(0, 0, -(window - 1))
+		repoParams.From = &from
+	}
+	unit, err := s.weightUnits.WeightUnit(reqContext, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	averages, err := s.repo.GetDailyAverages(reqContext, repoParams)
+	if err != nil {
+		return nil, err
+	}
+	for _, average := range averages {
+		average.Value = fromStored(params.Type, average.Value, unit)
+	}
+	points := rollingAverage(averages, window)
+	if params.From != nil {
+		from := truncateDay(*params.From)
+		points = slices.DeleteFunc(points, func(p models.TrendPoint) bool {
+			return p.Date.Before(from)
+		})
+	}
+	return points, nil
+}
+
+// BodyweightOn returns the latest weight logged on or before day, or nil if
+// the user has not logged one yet. Other domains use it to relate their data
+// to bodyweight, e.g. for strength-to-bodyweight ratios.
+func (s *Service) BodyweightOn(reqContext context.Context, userID int64, day time.Time) (*float64, error) {
+	return s.repo.GetBodyweightOn(reqContext, userID, day)
+}
+
+// BodyweightForSession is BodyweightOn for the day the session started.
+func (s *Service) BodyweightForSession(reqContext context.Context, userID, sessionID int64) (*float64, error) {
+	return s.repo.GetBodyweightForSession(reqContext, userID, sessionID)
+}
+
+// rollingAverage expects averages sorted by day, one per day.
+func rollingAverage(averages []*repository.DailyAverage, window int) []models.TrendPoint {
+	points := make([]models.TrendPoint, 0, len(averages))
+	start := 0
+	sum := 0.0
+	for i, average := range averages {
+		sum += average.Value
+		windowStart := average.Day.AddDate(0, 0, -(window - 1))
+		for averages[start].Day.Before(windowStart) {
+			sum -= averages[start].Value
+			start++
+		}
+		points = append(points, models.TrendPoint{
+			Date:           average.Day,
+			Value:          round2(average.Value),
+			RollingAverage: round2(sum / float64(i-start+1)),
+		})
+	}
+	return points
+}
+
+func validateKind(measurementType, bodyPart string) error {
+	if _, ok := maxValues[measurementType]; !ok {
+		return ErrInvalidType
+	}
+	if measurementType == models.TypeCircumference {
+		if !slices.Contains(models.BodyParts, bodyPart) {
+			return ErrInvalidBodyPart
+		}
+	} else if bodyPart != "" {
+		return ErrInvalidBodyPart
+	}
+	return nil
+}
+
+func validateValue(measurementType string, value float64) error {
+	if value <= 0 || value > maxValues[measurementType] {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// toStored converts weights entered in unit to kilograms; other types are
+// stored as given.
+func toStored(measurementType string, value float64, unit units.WeightUnit) float64 {
+	if measurementType != models.TypeWeight {
+		return value
+	}
+	return units.ToKilograms(value, unit)
+}
+
+func fromStored(measurementType string, value float64, unit units.WeightUnit) float64 {
+	if measurementType != models.TypeWeight {
+		return value
+	}
+	return units.FromKilograms(value, unit)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/measurements/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMeasurementsRepository struct {
+	mock.Mock
+}
+
+func (m *MockMeasurementsRepository) CreateMeasurement(ctx context.Context, params *repository.CreateMeasurementParams) (models.Measurement, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.Measurement), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) GetMeasurement(ctx context.Context, userID, measurementID int64) (models.Measurement, error) {
+	args := m.Called(ctx, userID, measurementID)
+	return args.Get(0).(models.Measurement), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) UpdateMeasurement(ctx context.Context, params *repository.UpdateMeasurementParams) (models.Measurement, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.Measurement), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) DeleteMeasurement(ctx context.Context, userID, measurementID int64) error {
+	args := m.Called(ctx, userID, measurementID)
+	return args.Error(0)
+}
+
+func (m *MockMeasurementsRepository) ListMeasurements(ctx context.Context, params *repository.ListMeasurementsParams) ([]models.Measurement, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]models.Measurement), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) GetDailyAverages(ctx context.Context, params *repository.GetDailyAveragesParams) ([]*repository.DailyAverage, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]*repository.DailyAverage), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) GetBodyweightOn(ctx context.Context, userID int64, day time.Time) (*float64, error) {
+	args := m.Called(ctx, userID, day)
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+func (m *MockMeasurementsRepository) GetBodyweightForSession(ctx context.Context, userID, sessionID int64) (*float64, error) {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+// fixedUnit resolves every user to the same weight unit.
+type fixedUnit units.WeightUnit
+
+func (u fixedUnit) WeightUnit(context.Context, int64) (units.WeightUnit, error) {
+	return units.WeightUnit(u), nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestService_Create_ConvertsWeight(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Pounds))
+	ctx := context.Background()
+
+	mockRepo.On("CreateMeasurement", ctx, mock.MatchedBy(func(p *repository.CreateMeasurementParams) bool {
+		return p.Type == models.TypeWeight && p.Value == 81.647 && p.BodyPart == ""
+	})).Return(models.Measurement{ID: 1, Type: models.TypeWeight, Value: 81.647, MeasuredAt: day(1)}, nil)
+
+	measurement, err := service.Create(ctx, &CreateMeasurementParams{
+		UserID:     1,
+		Type:       models.TypeWeight,
+		Value:      180,
+		MeasuredAt: day(1),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 180.0, measurement.Value)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Create_DefaultsMeasuredAtToNow(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Kilograms))
+	now := time.Date(2025, 12, 20, 7, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mockRepo.On("CreateMeasurement", ctx, mock.MatchedBy(func(p *repository.CreateMeasurementParams) bool {
+		return p.MeasuredAt.Equal(now) && p.BodyPart == "waist"
+	})).Return(models.Measurement{ID: 1, Type: models.TypeCircumference, BodyPart: "waist", Value: 82}, nil)
+
+	_, err := service.Create(ctx, &CreateMeasurementParams{
+		UserID:   1,
+		Type:     models.TypeCircumference,
+		BodyPart: "waist",
+		Value:    82,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Kilograms))
+
+	cases := map[*CreateMeasurementParams]error{
+		{Type: "height", Value: 180}:                                     ErrInvalidType,
+		{Type: models.TypeCircumference, Value: 40}:                      ErrInvalidBodyPart,
+		{Type: models.TypeCircumference, BodyPart: "ear", Value: 5}:      ErrInvalidBodyPart,
+		{Type: models.TypeWeight, BodyPart: "waist", Value: 80}:          ErrInvalidBodyPart,
+		{Type: models.TypeBodyFat, Value: 120}:                           ErrInvalidValue,
+		{Type: models.TypeWeight, Value: 0}:                              ErrInvalidValue,
+		{Type: models.TypeCircumference, BodyPart: "chest", Value: -100}: ErrInvalidValue,
+	}
+	for params, want := range cases {
+		_, err := service.Create(context.Background(), params)
+		assert.ErrorIs(t, err, want)
+	}
+	mockRepo.AssertNotCalled(t, "CreateMeasurement", mock.Anything, mock.Anything)
+}
+
+func TestService_Update_ValidatesAgainstExistingType(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Kilograms))
+	ctx := context.Background()
+
+	mockRepo.On("GetMeasurement", ctx, int64(1), int64(5)).
+		Return(models.Measurement{ID: 5, Type: models.TypeBodyFat, Value: 18}, nil)
+
+	value := 140.0
+	_, err := service.Update(ctx, &UpdateMeasurementParams{UserID: 1, MeasurementID: 5, Value: &value})
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	mockRepo.AssertNotCalled(t, "UpdateMeasurement", mock.Anything, mock.Anything)
+}
+
+func TestService_Update_NotFound(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Kilograms))
+	ctx := context.Background()
+
+	mockRepo.On("GetMeasurement", ctx, int64(1), int64(5)).
+		Return(models.Measurement{}, repository.ErrMeasurementNotFound)
+
+	measuredAt := day(3)
+	_, err := service.Update(ctx, &UpdateMeasurementParams{UserID: 1, MeasurementID: 5, MeasuredAt: &measuredAt})
+
+	assert.ErrorIs(t, err, ErrMeasurementNotFound)
+}
+
+func TestService_GetTrend_RollingAverage(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Kilograms))
+	ctx := context.Background()
+
+	from := day(4)
+	mockRepo.On("GetDailyAverages", ctx, mock.MatchedBy(func(p *repository.GetDailyAveragesParams) bool {
+		// The window reaches back before From so the first point is complete.
+		return p.Type == models.TypeWeight && p.From != nil && p.From.Equal(day(2))
+	})).Return([]*repository.DailyAverage{
+		{Day: day(2), Value: 80},
+		{Day: day(3), Value: 81},
+		{Day: day(4), Value: 82},
+		{Day: day(8), Value: 79},
+	}, nil)
+
+	points, err := service.GetTrend(ctx, &TrendParams{
+		UserID: 1,
+		Type:   models.TypeWeight,
+		From:   &from,
+		Window: 3,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TrendPoint{
+		{Date: day(4), Value: 82, RollingAverage: 81},
+		// Days 6 and 7 have no entries, so only day 8 is in its window.
+		{Date: day(8), Value: 79, RollingAverage: 79},
+	}, points)
+}
+
+func TestService_GetTrend_ConvertsWeight(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Pounds))
+	ctx := context.Background()
+
+	mockRepo.On("GetDailyAverages", ctx, mock.Anything).Return([]*repository.DailyAverage{
+		{Day: day(1), Value: 100},
+	}, nil)
+
+	points, err := service.GetTrend(ctx, &TrendParams{UserID: 1, Type: models.TypeWeight, Window: 7})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 220.46, points[0].Value)
+	assert.Equal(t, 220.46, points[0].RollingAverage)
+}
+
+func TestService_GetTrend_InvalidWindow(t *testing.T) {
+	service := NewService(new(MockMeasurementsRepository), fixedUnit(units.Kilograms))
+
+	for _, window := range []int{0, 91} {
+		_, err := service.GetTrend(context.Background(), &TrendParams{UserID: 1, Type: models.TypeWeight, Window: window})
+		assert.ErrorIs(t, err, ErrInvalidTrendWindow)
+	}
+}
+
+func TestService_BodyweightForSession(t *testing.T) {
+	mockRepo := new(MockMeasurementsRepository)
+	service := NewService(mockRepo, fixedUnit(units.Pounds))
+	ctx := context.Background()
+
+	bodyweight := 81.5
+	mockRepo.On("GetBodyweightForSession", ctx, int64(1), int64(9)).Return(&bodyweight, nil)
+
+	got, err := service.BodyweightForSession(ctx, 1, 9)
+
+	assert.NoError(t, err)
+	// Lookups for other domains stay in kilograms.
+	assert.Equal(t, 81.5, *got)
+}
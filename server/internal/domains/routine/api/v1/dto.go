@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	sessionModels "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+)
+
+type RoutineResponse struct {
+	Routine models.Routine `json:"routine"`
+}
+
+type ListRoutinesResponse struct {
+	Routines []models.Routine `json:"routines"`
+}
+
+type StartSessionResponse struct {
+	Session sessionModels.WorkoutSession `json:"session"`
+}
+
+type SaveSessionAsRoutineRequest struct {
+	SessionID   int64  `json:"sessionID" binding:"required"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
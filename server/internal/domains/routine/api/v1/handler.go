@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/service"
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+	"github.com/TBuckholz5/workouttracker/internal/util/validate"
+)
+
+type Handler struct {
+	service     service.RoutineService
+	weightUnits units.Resolver
+}
+
+func NewHandler(s service.RoutineService, weightUnits units.Resolver) *Handler {
+	return &Handler{service: s, weightUnits: weightUnits}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var payload models.Routine
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	payload.UserID = userID.(int64)
+	unit, ok := h.weightUnit(w, r, payload.UserID)
+	if !ok {
+		return
+	}
+	routineToKilograms(&payload, unit)
+	routine, err := h.service.Create(r.Context(), &payload)
+	writeRoutineResult(w, r, routine, unit, err)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var payload models.Routine
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	routineID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	payload.ID = routineID
+	payload.UserID = userID.(int64)
+	unit, ok := h.weightUnit(w, r, payload.UserID)
+	if !ok {
+		return
+	}
+	routineToKilograms(&payload, unit)
+	routine, err := h.service.Update(r.Context(), &payload)
+	writeRoutineResult(w, r, routine, unit, err)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	routineID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	routine, err := h.service.GetByID(r.Context(), userID.(int64), routineID)
+	writeRoutineResult(w, r, routine, unit, err)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	routines, err := h.service.List(r.Context(), userID.(int64))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	for i := range routines {
+		routineFromKilograms(&routines[i], unit)
+	}
+	if err := json.NewEncoder(w).Encode(ListRoutinesResponse{Routines: routines}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	routineID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	if err := h.service.Delete(r.Context(), userID.(int64), routineID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	routineID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.StartSession(r.Context(), userID.(int64), routineID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	sessionFromKilograms(session, unit)
+	if err := json.NewEncoder(w).Encode(StartSessionResponse{Session: *session}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) CreateFromSession(w http.ResponseWriter, r *http.Request) {
+	var payload SaveSessionAsRoutineRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	routine, err := h.service.CreateFromSession(r.Context(), &service.SaveSessionAsRoutineParams{
+		UserID:      userID.(int64),
+		SessionID:   payload.SessionID,
+		Name:        payload.Name,
+		Description: payload.Description,
+	})
+	writeRoutineResult(w, r, routine, unit, err)
+}
+
+func writeRoutineResult(w http.ResponseWriter, r *http.Request, routine *models.Routine, unit units.WeightUnit, err error) {
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	routineFromKilograms(routine, unit)
+	if err := json.NewEncoder(w).Encode(RoutineResponse{Routine: *routine}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Create_RejectsInvalidRoutine(t *testing.T) {
+	// Validation runs before the service or the unit preference is needed.
+	handler := NewHandler(nil, nil)
+	body := `{"name": " ", "exercises": [{"position": 0, "sets": [
+		{"targetReps": -1, "targetWeight": -2.5, "set_type": "warmup", "set_order": 1}
+	]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/routines", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.Create(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, apperror.ContentType, rec.Header().Get("Content-Type"))
+	var problem apperror.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, "validation_failed", problem.Code)
+	fields := make([]string, 0, len(problem.Errors))
+	for _, fieldErr := range problem.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"name",
+		"exercises[0].exerciseID",
+		"exercises[0].sets[0].targetReps",
+		"exercises[0].sets[0].targetWeight",
+		"exercises[0].sets[0].set_type",
+	}, fields)
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	sessionModels "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+// weightUnit looks up the unit the user reads and writes weights in. On
+// failure it writes the error response and returns false.
+func (h *Handler) weightUnit(w http.ResponseWriter, r *http.Request, userID int64) (units.WeightUnit, bool) {
+	unit, err := h.weightUnits.WeightUnit(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return "", false
+	}
+	return unit, true
+}
+
+// Target weights are stored in kilograms; requests and responses use the
+// user's unit.
+
+func routineToKilograms(routine *models.Routine, unit units.WeightUnit) {
+	convertTargetWeights(routine, func(weight float64) float64 { return units.ToKilograms(weight, unit) })
+}
+
+func routineFromKilograms(routine *models.Routine, unit units.WeightUnit) {
+	convertTargetWeights(routine, func(weight float64) float64 { return units.FromKilograms(weight, unit) })
+}
+
+func convertTargetWeights(routine *models.Routine, convert func(float64) float64) {
+	for i := range routine.Exercises {
+		sets := routine.Exercises[i].Sets
+		for j := range sets {
+			sets[j].TargetWeight = convert(sets[j].TargetWeight)
+		}
+	}
+}
+
+func sessionFromKilograms(session *sessionModels.WorkoutSession, unit units.WeightUnit) {
+	for i := range session.Workouts {
+		sets := session.Workouts[i].Sets
+		for j := range sets {
+			sets[j].Weight = units.FromKilograms(sets[j].Weight, unit)
+		}
+	}
+}
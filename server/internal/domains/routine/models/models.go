@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type RoutineSet struct {
+	ID           int64   `json:"id,omitempty"`
+	TargetReps   int     `json:"targetReps" binding:"min=0"`
+	TargetWeight float64 `json:"targetWeight" binding:"min=0"`
+	SetType      string  `json:"set_type" binding:"required,oneof=normal dropset superset failure"`
+	SetOrder     int     `json:"set_order" binding:"min=0"`
+}
+
+type RoutineExercise struct {
+	ID          int64        `json:"id,omitempty"`
+	ExerciseID  int64        `json:"exerciseID" binding:"required"`
+	Description string       `json:"description,omitempty"`
+	Position    int          `json:"position" binding:"min=0"`
+	Sets        []RoutineSet `json:"sets"`
+}
+
+type Routine struct {
+	ID          int64             `json:"id,omitempty"`
+	UserID      int64             `json:"userID,omitempty"`
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description,omitempty"`
+	Exercises   []RoutineExercise `json:"exercises"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+)
+
+type RoutineSet struct {
+	ID                int64     `db:"id"`
+	RoutineExerciseID int64     `db:"routine_exercise_id"`
+	TargetReps        int       `db:"target_reps"`
+	TargetWeight      float64   `db:"target_weight"`
+	SetType           string    `db:"set_type"`
+	SetOrder          int       `db:"set_order"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+type RoutineExercise struct {
+	ID          int64     `db:"id"`
+	RoutineID   int64     `db:"routine_id"`
+	ExerciseID  int64     `db:"exercise_id"`
+	Description string    `db:"description"`
+	Position    int       `db:"position"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Routine struct {
+	ID          int64     `db:"id"`
+	UserID      int64     `db:"user_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
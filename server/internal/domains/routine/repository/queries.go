@@ -0,0 +1,41 @@
+package repository
+
+const createRoutineQuery = `INSERT INTO routines (name, user_id, description)
+	VALUES ($1, $2, $3)
+	RETURNING id, user_id, name, COALESCE(description, ''), created_at, updated_at;`
+
+const updateRoutineQuery = `UPDATE routines
+	SET name = $3, description = $4, updated_at = NOW()
+	WHERE id = $1 AND user_id = $2
+	RETURNING id, user_id, name, COALESCE(description, ''), created_at, updated_at;`
+
+const createRoutineExerciseQuery = `INSERT INTO routine_exercises (routine_id, exercise_id, description, position)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, routine_id, exercise_id, COALESCE(description, ''), position, created_at, updated_at;`
+
+const createRoutineSetQuery = `INSERT INTO routine_sets (routine_exercise_id, target_reps, target_weight, set_type, set_order)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, routine_exercise_id, target_reps, COALESCE(target_weight, 0), set_type, set_order, created_at, updated_at;`
+
+const deleteRoutineExercisesQuery = `DELETE FROM routine_exercises WHERE routine_id = $1;`
+
+const getRoutineByIDQuery = `SELECT id, user_id, name, COALESCE(description, ''), created_at, updated_at
+	FROM routines
+	WHERE id = $1 AND user_id = $2;`
+
+const listRoutinesQuery = `SELECT id, user_id, name, COALESCE(description, ''), created_at, updated_at
+	FROM routines
+	WHERE user_id = $1
+	ORDER BY name, id;`
+
+const getRoutineExercisesQuery = `SELECT id, routine_id, exercise_id, COALESCE(description, ''), position, created_at, updated_at
+	FROM routine_exercises
+	WHERE routine_id = ANY($1)
+	ORDER BY routine_id, position, id;`
+
+const getRoutineSetsQuery = `SELECT id, routine_exercise_id, target_reps, COALESCE(target_weight, 0), set_type, set_order, created_at, updated_at
+	FROM routine_sets
+	WHERE routine_exercise_id = ANY($1)
+	ORDER BY routine_exercise_id, set_order, id;`
+
+const deleteRoutineQuery = `DELETE FROM routines WHERE id = $1 AND user_id = $2;`
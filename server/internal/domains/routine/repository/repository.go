@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrRoutineNotFound = apperror.NotFound("routine_not_found", "routine not found")
+
+type RoutineRepository interface {
+	Create(ctx context.Context, routine *models.Routine) (*Routine, []*RoutineExercise, []*RoutineSet, error)
+	Update(ctx context.Context, routine *models.Routine) (*Routine, []*RoutineExercise, []*RoutineSet, error)
+	GetByID(ctx context.Context, userID int64, routineID int64) (*Routine, []*RoutineExercise, []*RoutineSet, error)
+	List(ctx context.Context, userID int64) ([]*Routine, []*RoutineExercise, []*RoutineSet, error)
+	Delete(ctx context.Context, userID int64, routineID int64) error
+}
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		pool: pool,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, routine *models.Routine) (*Routine, []*RoutineExercise, []*RoutineSet, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	newRoutine, err := scanRoutine(tx.QueryRow(ctx, createRoutineQuery, routine.Name, routine.UserID, routine.Description))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create routine: %w", err)
+	}
+	exercises, sets, err := insertExercises(ctx, tx, newRoutine.ID, routine.Exercises)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return newRoutine, exercises, sets, nil
+}
+
+// Update overwrites the routine's name and description and replaces its
+// exercises and sets wholesale.
+func (r *Repository) Update(ctx context.Context, routine *models.Routine) (*Routine, []*RoutineExercise, []*RoutineSet, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	updated, err := scanRoutine(tx.QueryRow(ctx, updateRoutineQuery, routine.ID, routine.UserID, routine.Name, routine.Description))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil, ErrRoutineNotFound
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to update routine: %w", err)
+	}
+	if _, err := tx.Exec(ctx, deleteRoutineExercisesQuery, updated.ID); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to clear routine exercises: %w", err)
+	}
+	exercises, sets, err := insertExercises(ctx, tx, updated.ID, routine.Exercises)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, exercises, sets, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, userID int64, routineID int64) (*Routine, []*RoutineExercise, []*RoutineSet, error) {
+	routine, err := scanRoutine(r.pool.QueryRow(ctx, getRoutineByIDQuery, routineID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil, ErrRoutineNotFound
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get routine: %w", err)
+	}
+	exercises, sets, err := r.getExercisesAndSets(ctx, []int64{routine.ID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return routine, exercises, sets, nil
+}
+
+func (r *Repository) List(ctx context.Context, userID int64) ([]*Routine, []*RoutineExercise, []*RoutineSet, error) {
+	rows, err := r.pool.Query(ctx, listRoutinesQuery, userID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list routines: %w", err)
+	}
+	defer rows.Close()
+
+	routines := make([]*Routine, 0)
+	routineIDs := make([]int64, 0)
+	for rows.Next() {
+		routine, err := scanRoutine(rows)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan routine row: %w", err)
+		}
+		routines = append(routines, routine)
+		routineIDs = append(routineIDs, routine.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list routines: %w", err)
+	}
+	if len(routines) == 0 {
+		return routines, []*RoutineExercise{}, []*RoutineSet{}, nil
+	}
+
+	exercises, sets, err := r.getExercisesAndSets(ctx, routineIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return routines, exercises, sets, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, userID int64, routineID int64) error {
+	tag, err := r.pool.Exec(ctx, deleteRoutineQuery, routineID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete routine: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRoutineNotFound
+	}
+	return nil
+}
+
+func (r *Repository) getExercisesAndSets(ctx context.Context, routineIDs []int64) ([]*RoutineExercise, []*RoutineSet, error) {
+	rows, err := r.pool.Query(ctx, getRoutineExercisesQuery, routineIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get routine exercises: %w", err)
+	}
+	exercises := make([]*RoutineExercise, 0)
+	exerciseIDs := make([]int64, 0)
+	for rows.Next() {
+		exercise, err := scanRoutineExercise(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan routine exercise row: %w", err)
+		}
+		exercises = append(exercises, exercise)
+		exerciseIDs = append(exerciseIDs, exercise.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get routine exercises: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx, getRoutineSetsQuery, exerciseIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get routine sets: %w", err)
+	}
+	defer rows.Close()
+	sets := make([]*RoutineSet, 0)
+	for rows.Next() {
+		set, err := scanRoutineSet(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan routine set row: %w", err)
+		}
+		sets = append(sets, set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get routine sets: %w", err)
+	}
+	return exercises, sets, nil
+}
+
+func insertExercises(ctx context.Context, tx pgx.Tx, routineID int64, exercises []models.RoutineExercise) ([]*RoutineExercise, []*RoutineSet, error) {
+	newExercises := make([]*RoutineExercise, len(exercises))
+	var newSets []*RoutineSet
+	for i, e := range exercises {
+		// Positions follow the order the exercises were submitted in.
+		exercise, err := scanRoutineExercise(tx.QueryRow(ctx, createRoutineExerciseQuery, routineID, e.ExerciseID, e.Description, i+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create routine exercise: %w", err)
+		}
+		newExercises[i] = exercise
+
+		for _, s := range e.Sets {
+			set, err := scanRoutineSet(tx.QueryRow(ctx, createRoutineSetQuery, exercise.ID, s.TargetReps, s.TargetWeight, s.SetType, s.SetOrder))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create routine set: %w", err)
+			}
+			newSets = append(newSets, set)
+		}
+	}
+	return newExercises, newSets, nil
+}
+
+func scanRoutine(row pgx.Row) (*Routine, error) {
+	var routine Routine
+	err := row.Scan(
+		&routine.ID,
+		&routine.UserID,
+		&routine.Name,
+		&routine.Description,
+		&routine.CreatedAt,
+		&routine.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &routine, nil
+}
+
+func scanRoutineExercise(row pgx.Row) (*RoutineExercise, error) {
+	var exercise RoutineExercise
+	err := row.Scan(
+		&exercise.ID,
+		&exercise.RoutineID,
+		&exercise.ExerciseID,
+		&exercise.Description,
+		&exercise.Position,
+		&exercise.CreatedAt,
+		&exercise.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+func scanRoutineSet(row pgx.Row) (*RoutineSet, error) {
+	var set RoutineSet
+	err := row.Scan(
+		&set.ID,
+		&set.RoutineExerciseID,
+		&set.TargetReps,
+		&set.TargetWeight,
+		&set.SetType,
+		&set.SetOrder,
+		&set.CreatedAt,
+		&set.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
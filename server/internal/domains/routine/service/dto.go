@@ -0,0 +1,8 @@
+package service
+
+type SaveSessionAsRoutineParams struct {
+	UserID      int64
+	SessionID   int64
+	Name        string
+	Description string
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/repository"
+	sessionModels "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	sessionService "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/service"
+)
+
+var (
+	ErrRoutineNotFound     = repository.ErrRoutineNotFound
+	ErrSessionNotFound     = sessionService.ErrSessionNotFound
+	ErrActiveSessionExists = sessionService.ErrActiveSessionExists
+)
+
+// SessionService is the subset of the workout session service needed to start
+// a session from a routine and to build a routine from a logged session.
+type SessionService interface {
+	Start(reqContext context.Context, session *sessionModels.WorkoutSession) (*sessionModels.WorkoutSession, error)
+	GetByID(reqContext context.Context, userID int64, sessionID int64) (*sessionModels.WorkoutSession, error)
+}
+
+type RoutineService interface {
+	Create(reqContext context.Context, routine *models.Routine) (*models.Routine, error)
+	Update(reqContext context.Context, routine *models.Routine) (*models.Routine, error)
+	GetByID(reqContext context.Context, userID int64, routineID int64) (*models.Routine, error)
+	List(reqContext context.Context, userID int64) ([]models.Routine, error)
+	Delete(reqContext context.Context, userID int64, routineID int64) error
+	StartSession(reqContext context.Context, userID int64, routineID int64) (*sessionModels.WorkoutSession, error)
+	CreateFromSession(reqContext context.Context, params *SaveSessionAsRoutineParams) (*models.Routine, error)
+}
+
+type Service struct {
+	repo      repository.RoutineRepository
+	sessions  SessionService
+	exercises policy.ExercisePolicy
+}
+
+func NewService(r repository.RoutineRepository, sessions SessionService, exercises policy.ExercisePolicy) *Service {
+	return &Service{
+		repo:      r,
+		sessions:  sessions,
+		exercises: exercises,
+	}
+}
+
+func (s *Service) Create(reqContext context.Context, routine *models.Routine) (*models.Routine, error) {
+	if err := s.checkExercises(reqContext, routine); err != nil {
+		return nil, err
+	}
+	repositoryRoutine, repositoryExercises, repositorySets, err := s.repo.Create(reqContext, routine)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryToModels(repositoryRoutine, repositoryExercises, repositorySets), nil
+}
+
+func (s *Service) Update(reqContext context.Context, routine *models.Routine) (*models.Routine, error) {
+	if err := s.checkExercises(reqContext, routine); err != nil {
+		return nil, err
+	}
+	repositoryRoutine, repositoryExercises, repositorySets, err := s.repo.Update(reqContext, routine)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryToModels(repositoryRoutine, repositoryExercises, repositorySets), nil
+}
+
+func (s *Service) GetByID(reqContext context.Context, userID int64, routineID int64) (*models.Routine, error) {
+	repositoryRoutine, repositoryExercises, repositorySets, err := s.repo.GetByID(reqContext, userID, routineID)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryToModels(repositoryRoutine, repositoryExercises, repositorySets), nil
+}
+
+func (s *Service) List(reqContext context.Context, userID int64) ([]models.Routine, error) {
+	repositoryRoutines, repositoryExercises, repositorySets, err := s.repo.List(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	routines := make([]models.Routine, 0, len(repositoryRoutines))
+	for _, routine := range repositoryRoutines {
+		routines = append(routines, *repositoryToModels(routine, repositoryExercises, repositorySets))
+	}
+	return routines, nil
+}
+
+func (s *Service) Delete(reqContext context.Context, userID int64, routineID int64) error {
+	return s.repo.Delete(reqContext, userID, routineID)
+}
+
+// StartSession starts the user's active session from the routine through the
+// workout session service, so the one-active-session rule applies, and
+// returns the stored session.
+func (s *Service) StartSession(reqContext context.Context, userID int64, routineID int64) (*sessionModels.WorkoutSession, error) {
+	routine, err := s.GetByID(reqContext, userID, routineID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessions.Start(reqContext, routineToSession(routine))
+}
+
+func (s *Service) CreateFromSession(reqContext context.Context, params *SaveSessionAsRoutineParams) (*models.Routine, error) {
+	session, err := s.sessions.GetByID(reqContext, params.UserID, params.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Create(reqContext, sessionToRoutine(session, params.Name, params.Description))
+}
+
+// checkExercises keeps routines to exercises the user may log, since starting
+// a routine copies them into a session.
+func (s *Service) checkExercises(reqContext context.Context, routine *models.Routine) error {
+	refs := make([]policy.Reference, 0, len(routine.Exercises))
+	for i, exercise := range routine.Exercises {
+		refs = append(refs, policy.Reference{Field: fmt.Sprintf("exercises[%d].exerciseID", i), ExerciseID: exercise.ExerciseID})
+	}
+	return s.exercises.CanUse(reqContext, routine.UserID, refs...)
+}
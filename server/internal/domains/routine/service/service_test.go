@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/repository"
+	sessionModels "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoutineRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoutineRepository) Create(ctx context.Context, routine *models.Routine) (*repository.Routine, []*repository.RoutineExercise, []*repository.RoutineSet, error) {
+	args := m.Called(ctx, routine)
+	return args.Get(0).(*repository.Routine),
+		args.Get(1).([]*repository.RoutineExercise),
+		args.Get(2).([]*repository.RoutineSet),
+		args.Error(3)
+}
+
+func (m *MockRoutineRepository) Update(ctx context.Context, routine *models.Routine) (*repository.Routine, []*repository.RoutineExercise, []*repository.RoutineSet, error) {
+	args := m.Called(ctx, routine)
+	return args.Get(0).(*repository.Routine),
+		args.Get(1).([]*repository.RoutineExercise),
+		args.Get(2).([]*repository.RoutineSet),
+		args.Error(3)
+}
+
+func (m *MockRoutineRepository) GetByID(ctx context.Context, userID int64, routineID int64) (*repository.Routine, []*repository.RoutineExercise, []*repository.RoutineSet, error) {
+	args := m.Called(ctx, userID, routineID)
+	return args.Get(0).(*repository.Routine),
+		args.Get(1).([]*repository.RoutineExercise),
+		args.Get(2).([]*repository.RoutineSet),
+		args.Error(3)
+}
+
+func (m *MockRoutineRepository) List(ctx context.Context, userID int64) ([]*repository.Routine, []*repository.RoutineExercise, []*repository.RoutineSet, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*repository.Routine),
+		args.Get(1).([]*repository.RoutineExercise),
+		args.Get(2).([]*repository.RoutineSet),
+		args.Error(3)
+}
+
+func (m *MockRoutineRepository) Delete(ctx context.Context, userID int64, routineID int64) error {
+	args := m.Called(ctx, userID, routineID)
+	return args.Error(0)
+}
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Start(ctx context.Context, session *sessionModels.WorkoutSession) (*sessionModels.WorkoutSession, error) {
+	args := m.Called(ctx, session)
+	return args.Get(0).(*sessionModels.WorkoutSession), args.Error(1)
+}
+
+func (m *MockSessionService) GetByID(ctx context.Context, userID int64, sessionID int64) (*sessionModels.WorkoutSession, error) {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Get(0).(*sessionModels.WorkoutSession), args.Error(1)
+}
+
+type MockExercisePolicy struct {
+	mock.Mock
+}
+
+func (m *MockExercisePolicy) CanUse(ctx context.Context, userID int64, refs ...policy.Reference) error {
+	args := m.Called(ctx, userID, refs)
+	return args.Error(0)
+}
+
+// allowExercises is an exercise policy for tests that don't exercise it.
+type allowExercises struct{}
+
+func (allowExercises) CanUse(context.Context, int64, ...policy.Reference) error {
+	return nil
+}
+
+func TestService_List_GroupsExercisesByRoutine(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	service := NewService(mockRepo, nil, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("List", ctx, int64(42)).Return(
+		[]*repository.Routine{{ID: 1, UserID: 42, Name: "Legs A"}, {ID: 2, UserID: 42, Name: "Push A"}},
+		[]*repository.RoutineExercise{
+			{ID: 10, RoutineID: 1, ExerciseID: 5, Position: 2},
+			{ID: 11, RoutineID: 1, ExerciseID: 4, Position: 1},
+			{ID: 20, RoutineID: 2, ExerciseID: 1, Position: 1},
+		},
+		[]*repository.RoutineSet{
+			{ID: 100, RoutineExerciseID: 10, TargetReps: 5, SetOrder: 1},
+			{ID: 200, RoutineExerciseID: 20, TargetReps: 8, SetOrder: 1},
+		},
+		nil)
+
+	result, err := service.List(ctx, 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Len(t, result[0].Exercises, 2)
+	assert.Equal(t, int64(4), result[0].Exercises[0].ExerciseID)
+	assert.Equal(t, int64(5), result[0].Exercises[1].ExerciseID)
+	assert.Len(t, result[0].Exercises[1].Sets, 1)
+	assert.Len(t, result[1].Exercises, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_StartSession_StartsFromTargets(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	mockSessions := new(MockSessionService)
+	service := NewService(mockRepo, mockSessions, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, int64(42), int64(1)).Return(
+		&repository.Routine{ID: 1, UserID: 42, Name: "Push A"},
+		[]*repository.RoutineExercise{{ID: 10, RoutineID: 1, ExerciseID: 3, Position: 1}},
+		[]*repository.RoutineSet{
+			{ID: 101, RoutineExerciseID: 10, TargetReps: 8, TargetWeight: 60, SetType: "normal", SetOrder: 2},
+			{ID: 100, RoutineExerciseID: 10, TargetReps: 10, TargetWeight: 50, SetType: "normal", SetOrder: 1},
+		},
+		nil)
+	var started *sessionModels.WorkoutSession
+	mockSessions.On("Start", ctx, mock.MatchedBy(func(session *sessionModels.WorkoutSession) bool {
+		started = session
+		return true
+	})).Return(&sessionModels.WorkoutSession{ID: 7, UserID: 42, Name: "Push A"}, nil)
+
+	session, err := service.StartSession(ctx, 42, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), session.ID)
+	assert.Equal(t, "Push A", started.Name)
+	assert.Equal(t, int64(42), started.UserID)
+	assert.Len(t, started.Workouts, 1)
+	assert.Equal(t, int64(3), started.Workouts[0].ExerciseID)
+	assert.Equal(t, 10, started.Workouts[0].Sets[0].Reps)
+	assert.Equal(t, 50.0, started.Workouts[0].Sets[0].Weight)
+	assert.Equal(t, 2, started.Workouts[0].Sets[1].SetOrder)
+	mockRepo.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestService_StartSession_ActiveSessionExists(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	mockSessions := new(MockSessionService)
+	service := NewService(mockRepo, mockSessions, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, int64(42), int64(1)).Return(
+		&repository.Routine{ID: 1, UserID: 42, Name: "Push A"},
+		[]*repository.RoutineExercise{},
+		[]*repository.RoutineSet{},
+		nil)
+	mockSessions.On("Start", ctx, mock.Anything).Return((*sessionModels.WorkoutSession)(nil), ErrActiveSessionExists)
+
+	session, err := service.StartSession(ctx, 42, 1)
+
+	assert.ErrorIs(t, err, ErrActiveSessionExists)
+	assert.Nil(t, session)
+}
+
+func TestService_StartSession_NotFound(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	service := NewService(mockRepo, nil, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, int64(42), int64(1)).Return(
+		(*repository.Routine)(nil),
+		([]*repository.RoutineExercise)(nil),
+		([]*repository.RoutineSet)(nil),
+		repository.ErrRoutineNotFound)
+
+	session, err := service.StartSession(ctx, 42, 1)
+
+	assert.ErrorIs(t, err, ErrRoutineNotFound)
+	assert.Nil(t, session)
+}
+
+func TestService_CreateFromSession(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	mockSessions := new(MockSessionService)
+	service := NewService(mockRepo, mockSessions, allowExercises{})
+	ctx := context.Background()
+
+	mockSessions.On("GetByID", ctx, int64(42), int64(7)).Return(&sessionModels.WorkoutSession{
+		ID:     7,
+		UserID: 42,
+		Name:   "Monday",
+		Workouts: []sessionModels.Workout{
+			{ExerciseID: 3, Sets: []sessionModels.WorkoutSet{{Reps: 5, Weight: 100, SetType: "normal", SetOrder: 1}}},
+			{ExerciseID: 9, Sets: []sessionModels.WorkoutSet{}},
+		},
+	}, nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(r *models.Routine) bool {
+		return r.UserID == 42 && r.Name == "Pull A" && len(r.Exercises) == 2 &&
+			r.Exercises[0].Position == 1 && r.Exercises[0].Sets[0].TargetWeight == 100 &&
+			r.Exercises[1].ExerciseID == 9
+	})).Return(
+		&repository.Routine{ID: 1, UserID: 42, Name: "Pull A"},
+		[]*repository.RoutineExercise{},
+		[]*repository.RoutineSet{},
+		nil)
+
+	routine, err := service.CreateFromSession(ctx, &SaveSessionAsRoutineParams{UserID: 42, SessionID: 7, Name: "Pull A"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), routine.ID)
+	mockRepo.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestService_CreateFromSession_SessionError(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	mockSessions := new(MockSessionService)
+	service := NewService(mockRepo, mockSessions, allowExercises{})
+	ctx := context.Background()
+
+	mockSessions.On("GetByID", ctx, int64(42), int64(7)).Return((*sessionModels.WorkoutSession)(nil), errors.New("db error"))
+
+	routine, err := service.CreateFromSession(ctx, &SaveSessionAsRoutineParams{UserID: 42, SessionID: 7})
+
+	assert.Error(t, err)
+	assert.Nil(t, routine)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_ForeignExercise(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	exercises := new(MockExercisePolicy)
+	service := NewService(mockRepo, nil, exercises)
+	ctx := context.Background()
+
+	routine := &models.Routine{
+		UserID:    42,
+		Name:      "Push A",
+		Exercises: []models.RoutineExercise{{ExerciseID: 1, Position: 1}, {ExerciseID: 7, Position: 2}},
+	}
+	exercises.On("CanUse", ctx, int64(42), []policy.Reference{
+		{Field: "exercises[0].exerciseID", ExerciseID: 1},
+		{Field: "exercises[1].exerciseID", ExerciseID: 7},
+	}).Return(policy.ErrExerciseForbidden)
+
+	result, err := service.Create(ctx, routine)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, policy.ErrExerciseForbidden)
+	exercises.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Update_UnknownExercise(t *testing.T) {
+	mockRepo := new(MockRoutineRepository)
+	exercises := new(MockExercisePolicy)
+	service := NewService(mockRepo, nil, exercises)
+	ctx := context.Background()
+
+	routine := &models.Routine{
+		ID:        3,
+		UserID:    42,
+		Name:      "Push A",
+		Exercises: []models.RoutineExercise{{ExerciseID: 99, Position: 1}},
+	}
+	exercises.On("CanUse", ctx, int64(42), []policy.Reference{
+		{Field: "exercises[0].exerciseID", ExerciseID: 99},
+	}).Return(policy.ErrUnknownExercise)
+
+	result, err := service.Update(ctx, routine)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, policy.ErrUnknownExercise)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
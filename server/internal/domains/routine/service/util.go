@@ -0,0 +1,119 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/routine/repository"
+	sessionModels "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+)
+
+func repositoryToModels(routine *repository.Routine, exercises []*repository.RoutineExercise, sets []*repository.RoutineSet) *models.Routine {
+	exerciseMap := make(map[int64]*models.RoutineExercise)
+	modelExercises := make([]*models.RoutineExercise, 0, len(exercises))
+	for _, exercise := range exercises {
+		if exercise.RoutineID != routine.ID {
+			continue
+		}
+		modelExercise := &models.RoutineExercise{
+			ID:          exercise.ID,
+			ExerciseID:  exercise.ExerciseID,
+			Description: exercise.Description,
+			Position:    exercise.Position,
+			Sets:        []models.RoutineSet{},
+		}
+		exerciseMap[exercise.ID] = modelExercise
+		modelExercises = append(modelExercises, modelExercise)
+	}
+	for _, set := range sets {
+		exercise, ok := exerciseMap[set.RoutineExerciseID]
+		if !ok {
+			continue
+		}
+		exercise.Sets = append(exercise.Sets, models.RoutineSet{
+			ID:           set.ID,
+			TargetReps:   set.TargetReps,
+			TargetWeight: set.TargetWeight,
+			SetType:      set.SetType,
+			SetOrder:     set.SetOrder,
+		})
+	}
+	sort.SliceStable(modelExercises, func(i, j int) bool {
+		return modelExercises[i].Position < modelExercises[j].Position
+	})
+	routineExercises := make([]models.RoutineExercise, 0, len(modelExercises))
+	for _, exercise := range modelExercises {
+		sort.SliceStable(exercise.Sets, func(i, j int) bool {
+			return exercise.Sets[i].SetOrder < exercise.Sets[j].SetOrder
+		})
+		routineExercises = append(routineExercises, *exercise)
+	}
+	return &models.Routine{
+		ID:          routine.ID,
+		UserID:      routine.UserID,
+		Name:        routine.Name,
+		Description: routine.Description,
+		Exercises:   routineExercises,
+		CreatedAt:   routine.CreatedAt,
+	}
+}
+
+// routineToSession builds the session to start from the routine's exercises,
+// using the targets as the starting values for every set.
+func routineToSession(routine *models.Routine) *sessionModels.WorkoutSession {
+	workouts := make([]sessionModels.Workout, 0, len(routine.Exercises))
+	for _, exercise := range routine.Exercises {
+		sets := make([]sessionModels.WorkoutSet, 0, len(exercise.Sets))
+		for _, set := range exercise.Sets {
+			sets = append(sets, sessionModels.WorkoutSet{
+				Reps:     set.TargetReps,
+				Weight:   set.TargetWeight,
+				SetType:  set.SetType,
+				SetOrder: set.SetOrder,
+			})
+		}
+		workouts = append(workouts, sessionModels.Workout{
+			ExerciseID:  exercise.ExerciseID,
+			Description: exercise.Description,
+			Sets:        sets,
+		})
+	}
+	return &sessionModels.WorkoutSession{
+		UserID:      routine.UserID,
+		Name:        routine.Name,
+		Description: routine.Description,
+		Workouts:    workouts,
+	}
+}
+
+// sessionToRoutine turns a logged session into a template, taking what was
+// actually performed as the new targets.
+func sessionToRoutine(session *sessionModels.WorkoutSession, name string, description string) *models.Routine {
+	exercises := make([]models.RoutineExercise, 0, len(session.Workouts))
+	for i, workout := range session.Workouts {
+		sets := make([]models.RoutineSet, 0, len(workout.Sets))
+		for _, set := range workout.Sets {
+			sets = append(sets, models.RoutineSet{
+				TargetReps:   set.Reps,
+				TargetWeight: set.Weight,
+				SetType:      set.SetType,
+				SetOrder:     set.SetOrder,
+			})
+		}
+		exercises = append(exercises, models.RoutineExercise{
+			ExerciseID:  workout.ExerciseID,
+			Description: workout.Description,
+			Position:    i + 1,
+			Sets:        sets,
+		})
+	}
+	if name == "" {
+		name = session.Name
+	}
+	return &models.Routine{
+		UserID:      session.UserID,
+		Name:        name,
+		Description: description,
+		Exercises:   exercises,
+	}
+}
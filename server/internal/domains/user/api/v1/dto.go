@@ -1,5 +1,7 @@
 package v1
 
+import "time"
+
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
@@ -12,5 +14,103 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresInDays defaults to 90.
+	ExpiresInDays int `json:"expiresInDays"`
+}
+
+type AccessTokenResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// Token is only set in the response to creating the token.
+	Token string `json:"token,omitempty"`
+}
+
+type ListAccessTokensResponse struct {
+	Tokens []AccessTokenResponse `json:"tokens"`
+}
+
+// PreferencesResponse reports bodyweight in the user's weight unit.
+type PreferencesResponse struct {
+	WeightUnit         string   `json:"weightUnit"`
+	Bodyweight         *float64 `json:"bodyweight"`
+	DefaultRestSeconds int      `json:"defaultRestSeconds"`
+	WeekStart          string   `json:"weekStart"`
+}
+
+type ProfileResponse struct {
+	Username         string              `json:"username"`
+	Email            string              `json:"email"`
+	EmailVerified    bool                `json:"emailVerified"`
+	TwoFactorEnabled bool                `json:"twoFactorEnabled"`
+	Preferences      PreferencesResponse `json:"preferences"`
+}
+
+// UpdatePreferencesRequest changes only the fields that are set. Bodyweight
+// is read in the weight unit that applies after the update.
+type UpdatePreferencesRequest struct {
+	WeightUnit         *string  `json:"weightUnit"`
+	Bodyweight         *float64 `json:"bodyweight"`
+	DefaultRestSeconds *int     `json:"defaultRestSeconds"`
+	WeekStart          *string  `json:"weekStart"`
+}
+
+type UpdateProfileRequest struct {
+	Preferences *UpdatePreferencesRequest `json:"preferences"`
 }
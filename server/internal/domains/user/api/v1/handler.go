@@ -1,10 +1,27 @@
 package v1
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/user/service"
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+	"github.com/TBuckholz5/workouttracker/internal/util/validate"
+)
+
+const (
+	oidcStateCookie       = "oidc_state"
+	oidcStateCookieMaxAge = 10 * 60
 )
 
 type Handler struct {
@@ -18,7 +35,11 @@ func NewHandler(s service.UserService) *Handler {
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	var payload RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 	if err := h.service.CreateUser(r.Context(), &service.RegisterParams{
@@ -26,7 +47,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		Email:    payload.Email,
 		Password: payload.Password,
 	}); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		apperror.Write(w, r, err)
 		return
 	}
 }
@@ -34,20 +55,504 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var payload LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		apperror.Write(w, r, apperror.ErrMalformedBody)
 		return
 	}
-	token, err := h.service.AuthenticateUser(r.Context(), &service.LoginParams{
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	result, err := h.service.AuthenticateUser(r.Context(), &service.LoginParams{
 		Username: payload.Username,
 		Password: payload.Password,
+		ClientIP: clientIP(r),
+	})
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+	writeLoginResult(w, r, result)
+}
+
+// LoginTwoFactor completes a login that returned a two-factor challenge.
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var payload TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	tokens, err := h.service.CompleteTwoFactorLogin(r.Context(), &service.TwoFactorLoginParams{
+		ChallengeToken: payload.ChallengeToken,
+		Code:           payload.Code,
+		ClientIP:       clientIP(r),
+	})
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+	writeTokens(w, r, tokens)
+}
+
+// StartOIDCLogin redirects to the provider's sign-in page. The state is also
+// set as a cookie so the callback only completes logins started by the same
+// browser.
+func (h *Handler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	start, err := h.service.StartOIDCLogin(r.Context(), r.PathValue("provider"))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    start.State,
+		Path:     "/",
+		MaxAge:   oidcStateCookieMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, start.AuthorizationURL, http.StatusFound)
+}
+
+// OIDCCallback completes the login when the provider redirects back and
+// responds like Login.
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		apperror.Write(w, r, service.ErrOIDCLoginFailed)
+		return
+	}
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		apperror.Write(w, r, apperror.InvalidParameter("state"))
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/", MaxAge: -1})
+
+	result, err := h.service.CompleteOIDCLogin(r.Context(), &service.OIDCLoginParams{
+		Provider: r.PathValue("provider"),
+		State:    state,
+		Code:     query.Get("code"),
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	writeLoginResult(w, r, result)
+}
+
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	tokens, err := h.service.RefreshSession(r.Context(), payload.RefreshToken)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	writeTokens(w, r, tokens)
+}
+
+// Logout revokes the session the calling access token belongs to.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	sessionID := r.Context().Value(auth.CtxKeySessionID)
+	if userID == nil || sessionID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	if err := h.service.Logout(r.Context(), userID.(int64), sessionID.(int64)); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	if err := h.service.LogoutAll(r.Context(), userID.(int64)); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var payload VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := h.service.VerifyEmail(r.Context(), payload.Token); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	if err := h.service.ResendVerificationEmail(r.Context(), userID.(int64)); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword always answers 202 so callers can't tell whether the
+// address has an account.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var payload ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := h.service.RequestPasswordReset(r.Context(), payload.Email); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var payload ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := h.service.ResetPassword(r.Context(), payload.Token, payload.Password); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	sessionID := r.Context().Value(auth.CtxKeySessionID)
+	if userID == nil || sessionID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := h.service.ChangePassword(r.Context(), &service.ChangePasswordParams{
+		UserID:          userID.(int64),
+		SessionID:       sessionID.(int64),
+		CurrentPassword: payload.CurrentPassword,
+		NewPassword:     payload.NewPassword,
+	}); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	enrollment, err := h.service.EnrollTOTP(r.Context(), userID.(int64))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(TOTPEnrollmentResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	codes, err := h.service.ConfirmTOTP(r.Context(), userID.(int64), payload.Code)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: codes}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if err := h.service.DisableTOTP(r.Context(), userID.(int64), payload.Code); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) CreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload CreateAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	created, err := h.service.CreateAccessToken(r.Context(), &service.CreateAccessTokenParams{
+		UserID:    userID.(int64),
+		Name:      payload.Name,
+		Scopes:    payload.Scopes,
+		ExpiresIn: time.Duration(payload.ExpiresInDays) * 24 * time.Hour,
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+		apperror.Write(w, r, err)
+		return
+	}
+	response := accessTokenToDTO(created.AccessToken)
+	response.Token = created.Token
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) ListAccessTokens(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	accessTokens, err := h.service.ListAccessTokens(r.Context(), userID.(int64))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	response := ListAccessTokensResponse{Tokens: make([]AccessTokenResponse, 0, len(accessTokens))}
+	for _, accessToken := range accessTokens {
+		response.Tokens = append(response.Tokens, accessTokenToDTO(accessToken))
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) RevokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	tokenID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	if err := h.service.RevokeAccessToken(r.Context(), userID.(int64), tokenID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	profile, err := h.service.GetProfile(r.Context(), userID.(int64))
+	writeProfileResult(w, r, profile, err)
+}
+
+func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	var payload UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	params := &service.UpdatePreferencesParams{UserID: userID.(int64)}
+	if p := payload.Preferences; p != nil {
+		params.WeightUnit = p.WeightUnit
+		params.Bodyweight = p.Bodyweight
+		params.DefaultRestSeconds = p.DefaultRestSeconds
+		params.WeekStart = p.WeekStart
+	}
+	profile, err := h.service.UpdatePreferences(r.Context(), params)
+	writeProfileResult(w, r, profile, err)
+}
+
+func writeProfileResult(w http.ResponseWriter, r *http.Request, profile *service.Profile, err error) {
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	preferences := profile.Preferences
+	response := ProfileResponse{
+		Username:         profile.Username,
+		Email:            profile.Email,
+		EmailVerified:    profile.EmailVerified,
+		TwoFactorEnabled: profile.TwoFactorEnabled,
+		Preferences: PreferencesResponse{
+			WeightUnit:         string(preferences.WeightUnit),
+			DefaultRestSeconds: preferences.DefaultRestSeconds,
+			WeekStart:          strings.ToLower(preferences.WeekStart.String()),
+		},
+	}
+	if preferences.Bodyweight != nil {
+		bodyweight := units.FromKilograms(*preferences.Bodyweight, preferences.WeightUnit)
+		response.Preferences.Bodyweight = &bodyweight
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func accessTokenToDTO(accessToken models.AccessToken) AccessTokenResponse {
+	return AccessTokenResponse{
+		ID:         accessToken.ID,
+		Name:       accessToken.Name,
+		Scopes:     accessToken.Scopes,
+		ExpiresAt:  accessToken.ExpiresAt,
+		LastUsedAt: accessToken.LastUsedAt,
+		CreatedAt:  accessToken.CreatedAt,
+	}
+}
+
+// writeLoginResult writes the tokens of a completed login, or the challenge
+// of one that still needs a second factor.
+func writeLoginResult(w http.ResponseWriter, r *http.Request, result *service.LoginResult) {
+	if result.ChallengeToken == "" {
+		writeTokens(w, r, result.Tokens)
 		return
 	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    result.ChallengeToken,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeLoginError maps failed logins to 401, or 429 with Retry-After while
+// the login is throttled. A bad challenge token or code is a failed login
+// here rather than a validation error, so it keeps its code but becomes a 401.
+func writeLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+	var appErr *apperror.Error
+	if (errors.Is(err, service.ErrInvalidToken) || errors.Is(err, service.ErrInvalidTOTPCode)) && errors.As(err, &appErr) {
+		rejected := *appErr
+		rejected.Kind = apperror.KindUnauthorized
+		err = &rejected
+	}
+	apperror.Write(w, r, err)
+}
 
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeTokens(w http.ResponseWriter, r *http.Request, tokens *service.TokenPair) {
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(LoginResponse{Token: token}); err != nil {
+	if err := json.NewEncoder(w).Encode(LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
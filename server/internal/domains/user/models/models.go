@@ -1,8 +1,40 @@
 package models
 
+import (
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
 type User struct {
 	ID       int64
 	Username string
 	Email    string
 	PwHash   string
+	// EmailVerified is set once the user confirmed their address.
+	EmailVerified bool
+	// TOTPSecret is set during and after two-factor enrollment; TOTPEnabled
+	// once the user confirmed it with a code.
+	TOTPSecret  string
+	TOTPEnabled bool
+}
+
+// AccessToken is a personal access token as shown to its owner; the secret
+// itself is only returned once, on creation.
+type AccessToken struct {
+	ID         int64
+	Name       string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Preferences are the user's settings for logging workouts. Bodyweight is in
+// kilograms regardless of WeightUnit and is nil until the user sets it.
+type Preferences struct {
+	WeightUnit         units.WeightUnit
+	Bodyweight         *float64
+	DefaultRestSeconds int
+	WeekStart          time.Weekday
 }
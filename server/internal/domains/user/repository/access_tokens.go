@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrAccessTokenNotFound = apperror.NotFound("access_token_not_found", "access token not found")
+	ErrInvalidAccessToken  = apperror.Unauthorized("invalid_access_token", "invalid or expired access token")
+)
+
+type CreateAccessTokenParams struct {
+	UserID    int64
+	Name      string
+	TokenHash string
+	Scopes    []string
+	TTL       time.Duration
+}
+
+func (r *Repository) CreateAccessToken(ctx context.Context, params *CreateAccessTokenParams) (models.AccessToken, error) {
+	accessToken, err := scanAccessToken(r.pool.QueryRow(ctx, createAccessToken,
+		params.UserID,
+		params.Name,
+		params.TokenHash,
+		params.Scopes,
+		params.TTL.Seconds(),
+	))
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("failed to create access token: %w", err)
+	}
+	return accessToken, nil
+}
+
+func (r *Repository) ListAccessTokens(ctx context.Context, userID int64) ([]models.AccessToken, error) {
+	rows, err := r.pool.Query(ctx, listAccessTokens, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	accessTokens := make([]models.AccessToken, 0)
+	for rows.Next() {
+		accessToken, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		accessTokens = append(accessTokens, accessToken)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	return accessTokens, nil
+}
+
+func (r *Repository) RevokeAccessToken(ctx context.Context, userID, tokenID int64) error {
+	tag, err := r.pool.Exec(ctx, revokeAccessToken, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateAccessToken returns the owner and scopes of a valid token and
+// marks it as used.
+func (r *Repository) AuthenticateAccessToken(ctx context.Context, tokenHash string) (int64, []string, error) {
+	var (
+		userID int64
+		scopes []string
+	)
+	err := r.pool.QueryRow(ctx, authenticateAccessToken, tokenHash).Scan(&userID, &scopes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to authenticate access token: %w", err)
+	}
+	return userID, scopes, nil
+}
+
+func scanAccessToken(row pgx.Row) (models.AccessToken, error) {
+	var (
+		accessToken models.AccessToken
+		lastUsedAt  pgtype.Timestamp
+	)
+	err := row.Scan(
+		&accessToken.ID,
+		&accessToken.Name,
+		&accessToken.Scopes,
+		&accessToken.ExpiresAt,
+		&lastUsedAt,
+		&accessToken.CreatedAt,
+	)
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+	if lastUsedAt.Valid {
+		accessToken.LastUsedAt = &lastUsedAt.Time
+	}
+	return accessToken, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrUsernameTaken = apperror.Conflict("username_taken", "username is already taken",
+		apperror.Field("username", "taken", "is already taken"))
+	ErrEmailTaken = apperror.Conflict("email_taken", "email is already in use",
+		apperror.Field("email", "taken", "is already in use"))
+	ErrIdentityLinked   = apperror.Conflict("identity_linked", "identity is already linked to a user")
+	ErrInvalidOIDCState = apperror.Validation("invalid_login_state", "invalid or expired login state")
+)
+
+const uniqueViolationCode = "23505"
+
+var uniqueConstraintErrors = map[string]error{
+	"users_username_key":                 ErrUsernameTaken,
+	"users_email_key":                    ErrEmailTaken,
+	"user_identities_issuer_subject_key": ErrIdentityLinked,
+}
+
+type OIDCAuthRequest struct {
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+}
+
+type CreateIdentityUserParams struct {
+	Username      string
+	Email         string
+	EmailVerified bool
+	Issuer        string
+	Subject       string
+}
+
+func (r *Repository) GetUserForIdentity(ctx context.Context, issuer, subject string) (models.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx, getUserByIdentity, issuer, subject))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("could not get user for identity: %w", err)
+	}
+	return user, nil
+}
+
+func (r *Repository) LinkIdentity(ctx context.Context, userID int64, issuer, subject, email string) error {
+	if _, err := r.pool.Exec(ctx, createIdentity, userID, issuer, subject, email); err != nil {
+		return uniqueViolation(err, "failed to link identity")
+	}
+	return nil
+}
+
+// CreateUserWithIdentity creates a user without a password, signed in only
+// through the linked identity.
+func (r *Repository) CreateUserWithIdentity(ctx context.Context, params *CreateIdentityUserParams) (models.User, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	user, err := scanUser(tx.QueryRow(ctx, createUserWithoutPassword, params.Username, params.Email, params.EmailVerified))
+	if err != nil {
+		return models.User{}, uniqueViolation(err, "could not create user")
+	}
+	if _, err := tx.Exec(ctx, createIdentity, user.ID, params.Issuer, params.Subject, params.Email); err != nil {
+		return models.User{}, uniqueViolation(err, "failed to link identity")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return user, nil
+}
+
+func (r *Repository) CreateOIDCAuthRequest(ctx context.Context, stateHash string, req OIDCAuthRequest, ttl time.Duration) error {
+	if _, err := r.pool.Exec(ctx, createOIDCAuthRequest, stateHash, req.Provider, req.CodeVerifier, req.Nonce, ttl.Seconds()); err != nil {
+		return fmt.Errorf("failed to create oidc auth request: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOIDCAuthRequest returns and deletes the request for the state, so
+// every state can complete at most one login.
+func (r *Repository) ConsumeOIDCAuthRequest(ctx context.Context, stateHash string) (OIDCAuthRequest, error) {
+	var req OIDCAuthRequest
+	err := r.pool.QueryRow(ctx, consumeOIDCAuthRequest, stateHash).Scan(&req.Provider, &req.CodeVerifier, &req.Nonce)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return OIDCAuthRequest{}, ErrInvalidOIDCState
+	}
+	if err != nil {
+		return OIDCAuthRequest{}, fmt.Errorf("failed to consume oidc auth request: %w", err)
+	}
+	return req, nil
+}
+
+// uniqueViolation maps unique constraint violations on users and identities
+// to their sentinel errors and wraps anything else.
+func uniqueViolation(err error, msg string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		if sentinel, ok := uniqueConstraintErrors[pgErr.ConstraintName]; ok {
+			return sentinel
+		}
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
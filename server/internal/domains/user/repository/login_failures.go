@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type LoginScope string
+
+const (
+	LoginScopeUsername LoginScope = "username"
+	LoginScopeIP       LoginScope = "ip"
+)
+
+// RecordLoginFailure counts a failed login for the key and returns the number
+// of failures within the window.
+func (r *Repository) RecordLoginFailure(ctx context.Context, scope LoginScope, key string, window time.Duration) (int, error) {
+	var failures int
+	if err := r.pool.QueryRow(ctx, recordLoginFailure, scope, key, window.Seconds()).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *Repository) LockLogin(ctx context.Context, scope LoginScope, key string, d time.Duration) error {
+	if _, err := r.pool.Exec(ctx, lockLogin, scope, key, d.Seconds()); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+// LoginLockoutRemaining returns how long logins for the username or from the
+// IP are still locked, or zero if neither is.
+func (r *Repository) LoginLockoutRemaining(ctx context.Context, username, ip string) (time.Duration, error) {
+	var seconds float64
+	if err := r.pool.QueryRow(ctx, loginLockoutRemaining, username, ip).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to check login lockout: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ClearLoginFailures resets the failures of a username after a successful
+// login. Failures per IP are left to expire with their window; otherwise
+// logging into an account of one's own between guesses would reset them.
+func (r *Repository) ClearLoginFailures(ctx context.Context, username string) error {
+	if _, err := r.pool.Exec(ctx, clearLoginFailures, username); err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+	return nil
+}
@@ -11,4 +11,8 @@ type user struct {
 	pwHash    pgtype.Text
 	createdAt pgtype.Timestamp
 	updatedAt pgtype.Timestamp
+	verified  pgtype.Timestamp
+	// totpSecret is set from enrollment on, totpEnabled once it is confirmed.
+	totpSecret  string
+	totpEnabled pgtype.Timestamp
 }
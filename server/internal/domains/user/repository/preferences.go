@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultPreferences mirrors the column defaults of user_preferences, for
+// users who never changed a setting.
+var defaultPreferences = models.Preferences{
+	WeightUnit:         units.Kilograms,
+	DefaultRestSeconds: 90,
+	WeekStart:          time.Monday,
+}
+
+type UpdatePreferencesParams struct {
+	UserID             int64
+	WeightUnit         *units.WeightUnit
+	Bodyweight         *float64
+	DefaultRestSeconds *int
+	WeekStart          *time.Weekday
+}
+
+func (r *Repository) GetPreferences(ctx context.Context, userID int64) (models.Preferences, error) {
+	preferences, err := scanPreferences(r.pool.QueryRow(ctx, getPreferences, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultPreferences, nil
+	}
+	if err != nil {
+		return models.Preferences{}, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+func (r *Repository) UpdatePreferences(ctx context.Context, params *UpdatePreferencesParams) (models.Preferences, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return models.Preferences{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, ensurePreferences, params.UserID); err != nil {
+		return models.Preferences{}, fmt.Errorf("failed to create preferences: %w", err)
+	}
+	preferences, err := scanPreferences(tx.QueryRow(ctx, updatePreferences,
+		params.UserID,
+		params.WeightUnit,
+		params.Bodyweight,
+		params.DefaultRestSeconds,
+		params.WeekStart,
+	))
+	if err != nil {
+		return models.Preferences{}, fmt.Errorf("failed to update preferences: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.Preferences{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return preferences, nil
+}
+
+func scanPreferences(row pgx.Row) (models.Preferences, error) {
+	var (
+		preferences models.Preferences
+		weightUnit  string
+		bodyweight  pgtype.Float8
+		weekStart   int
+	)
+	err := row.Scan(&weightUnit, &bodyweight, &preferences.DefaultRestSeconds, &weekStart)
+	if err != nil {
+		return models.Preferences{}, err
+	}
+	preferences.WeightUnit = units.WeightUnit(weightUnit)
+	preferences.WeekStart = time.Weekday(weekStart)
+	if bodyweight.Valid {
+		preferences.Bodyweight = &bodyweight.Float64
+	}
+	return preferences, nil
+}
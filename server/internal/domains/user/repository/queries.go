@@ -1,11 +1,263 @@
 package repository
 
+const userColumns = `id, username, email, pw_hash, created_at, updated_at, email_verified_at, COALESCE(totp_secret, ''), totp_enabled_at`
+
+const prefixedUserColumns = `u.id, u.username, u.email, u.pw_hash, u.created_at, u.updated_at, u.email_verified_at, COALESCE(u.totp_secret, ''), u.totp_enabled_at`
+
 const createUser = `INSERT INTO users (username, email, pw_hash)
 VALUES ($1, $2, $3)
-RETURNING id, username, email, pw_hash, created_at, updated_at
+RETURNING ` + userColumns + `
 `
 
-const getUserByUsername = `SELECT id, username, email, pw_hash, created_at, updated_at
+const getUserByUsername = `SELECT ` + userColumns + `
 FROM users
 WHERE username = $1
 `
+
+const getUserByEmail = `SELECT ` + userColumns + `
+FROM users
+WHERE lower(email) = lower($1)
+`
+
+const getUserByID = `SELECT ` + userColumns + `
+FROM users
+WHERE id = $1
+`
+
+const updatePassword = `UPDATE users
+SET pw_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+const createAuthSession = `INSERT INTO auth_sessions (user_id)
+VALUES ($1)
+RETURNING id
+`
+
+const createRefreshToken = `INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+VALUES ($1, $2, NOW() + make_interval(secs => $3))
+`
+
+const getRefreshTokenForUpdate = `SELECT rt.id, rt.session_id, s.user_id,
+	rt.rotated_at IS NOT NULL, rt.expires_at <= NOW(), s.revoked_at IS NOT NULL
+FROM refresh_tokens rt
+JOIN auth_sessions s ON s.id = rt.session_id
+WHERE rt.token_hash = $1
+FOR UPDATE OF rt, s
+`
+
+const markRefreshTokenRotated = `UPDATE refresh_tokens
+SET rotated_at = NOW()
+WHERE id = $1
+`
+
+const touchAuthSession = `UPDATE auth_sessions
+SET last_used_at = NOW()
+WHERE id = $1
+`
+
+const revokeAuthSessionFamily = `UPDATE auth_sessions
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL
+`
+
+const revokeAuthSession = `UPDATE auth_sessions
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+`
+
+const revokeAllAuthSessions = `UPDATE auth_sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+const isAuthSessionActive = `SELECT EXISTS (
+	SELECT 1 FROM auth_sessions WHERE id = $1 AND revoked_at IS NULL
+)
+`
+
+const revokeOtherAuthSessions = `UPDATE auth_sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+`
+
+const createUserToken = `INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+`
+
+// consumeUserToken marks a token used, so it can be redeemed only once.
+const consumeUserToken = `UPDATE user_tokens
+SET used_at = NOW()
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+RETURNING user_id
+`
+
+const invalidateUserTokens = `UPDATE user_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+`
+
+const markEmailVerified = `UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+WHERE id = $1
+`
+
+// recordLoginFailure restarts the count when the previous failure is older
+// than the window ($3 seconds).
+const recordLoginFailure = `INSERT INTO login_failures AS f (scope, key, failures, last_failed_at)
+VALUES ($1, $2, 1, NOW())
+ON CONFLICT (scope, key) DO UPDATE
+SET failures = CASE WHEN f.last_failed_at < NOW() - make_interval(secs => $3) THEN 1 ELSE f.failures + 1 END,
+	last_failed_at = NOW()
+RETURNING failures
+`
+
+const lockLogin = `UPDATE login_failures
+SET locked_until = NOW() + make_interval(secs => $3)
+WHERE scope = $1 AND key = $2
+`
+
+const loginLockoutRemaining = `SELECT COALESCE(EXTRACT(EPOCH FROM MAX(locked_until) - NOW()), 0)::float8
+FROM login_failures
+WHERE ((scope = 'username' AND key = $1) OR (scope = 'ip' AND key = $2))
+	AND locked_until > NOW()
+`
+
+const clearLoginFailures = `DELETE FROM login_failures
+WHERE scope = 'username' AND key = $1
+`
+
+const getUserTokenUser = `SELECT user_id
+FROM user_tokens
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+`
+
+// setTOTPSecret starts an enrollment; an enabled secret is never replaced.
+const setTOTPSecret = `UPDATE users
+SET totp_secret = $2, totp_last_step = NULL, updated_at = NOW()
+WHERE id = $1 AND totp_enabled_at IS NULL
+`
+
+const enableTOTP = `UPDATE users
+SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+`
+
+const disableTOTP = `UPDATE users
+SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+// useTOTPStep accepts each time step at most once.
+const useTOTPStep = `UPDATE users
+SET totp_last_step = $2
+WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+RETURNING id
+`
+
+const createRecoveryCode = `INSERT INTO user_recovery_codes (user_id, code_hash)
+VALUES ($1, $2)
+`
+
+const deleteRecoveryCodes = `DELETE FROM user_recovery_codes
+WHERE user_id = $1
+`
+
+const getRecoveryCodes = `SELECT id, code_hash
+FROM user_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL
+ORDER BY id
+`
+
+const useRecoveryCode = `UPDATE user_recovery_codes
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL
+RETURNING id
+`
+
+const getUserByIdentity = `SELECT ` + prefixedUserColumns + `
+FROM users u
+JOIN user_identities i ON i.user_id = u.id
+WHERE i.issuer = $1 AND i.subject = $2
+`
+
+const createIdentity = `INSERT INTO user_identities (user_id, issuer, subject, email)
+VALUES ($1, $2, $3, NULLIF($4, ''))
+`
+
+const createUserWithoutPassword = `INSERT INTO users (username, email, email_verified_at)
+VALUES ($1, NULLIF($2, ''), CASE WHEN $3::bool THEN NOW() END)
+RETURNING ` + userColumns + `
+`
+
+// createOIDCAuthRequest also drops expired requests so the table doesn't
+// grow with abandoned logins.
+const createOIDCAuthRequest = `WITH expired AS (
+	DELETE FROM oidc_auth_requests WHERE expires_at <= NOW()
+)
+INSERT INTO oidc_auth_requests (state_hash, provider, code_verifier, nonce, expires_at)
+VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+`
+
+const consumeOIDCAuthRequest = `DELETE FROM oidc_auth_requests
+WHERE state_hash = $1 AND expires_at > NOW()
+RETURNING provider, code_verifier, nonce
+`
+
+const accessTokenColumns = `id, name, scopes, expires_at, last_used_at, created_at`
+
+const createAccessToken = `INSERT INTO personal_access_tokens (user_id, name, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+RETURNING ` + accessTokenColumns + `
+`
+
+const listAccessTokens = `SELECT ` + accessTokenColumns + `
+FROM personal_access_tokens
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+const revokeAccessToken = `UPDATE personal_access_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+`
+
+// authenticateAccessToken looks up a usable token and records its use. The
+// timestamp is only written once a minute so busy scripts don't turn every
+// request into a write.
+const authenticateAccessToken = `WITH t AS (
+	SELECT id, user_id, scopes, last_used_at
+	FROM personal_access_tokens
+	WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+), touched AS (
+	UPDATE personal_access_tokens p
+	SET last_used_at = NOW()
+	FROM t
+	WHERE p.id = t.id AND (t.last_used_at IS NULL OR t.last_used_at < NOW() - INTERVAL '1 minute')
+)
+SELECT user_id, scopes FROM t
+`
+
+const preferenceColumns = `weight_unit, bodyweight, default_rest_seconds, week_start`
+
+const getPreferences = `SELECT ` + preferenceColumns + `
+FROM user_preferences
+WHERE user_id = $1
+`
+
+// ensurePreferences creates the row with the column defaults so updates only
+// need to handle the fields that change.
+const ensurePreferences = `INSERT INTO user_preferences (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) DO NOTHING
+`
+
+const updatePreferences = `UPDATE user_preferences
+SET weight_unit = COALESCE($2, weight_unit),
+	bodyweight = COALESCE($3, bodyweight),
+	default_rest_seconds = COALESCE($4, default_rest_seconds),
+	week_start = COALESCE($5, week_start),
+	updated_at = NOW()
+WHERE user_id = $1
+RETURNING ` + preferenceColumns + `
+`
@@ -2,15 +2,55 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var ErrUserNotFound = apperror.NotFound("user_not_found", "user not found")
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, params *CreateUserParams) (models.User, error)
 	GetUserForUsername(ctx context.Context, username string) (models.User, error)
+	GetUserForEmail(ctx context.Context, email string) (models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (models.User, error)
+	UpdatePassword(ctx context.Context, userID int64, pwHash string, keepSessionID int64) error
+	CreateUserToken(ctx context.Context, userID int64, purpose TokenPurpose, tokenHash string, ttl time.Duration) error
+	VerifyEmail(ctx context.Context, tokenHash string) (int64, error)
+	ResetPassword(ctx context.Context, tokenHash, pwHash string) (int64, error)
+	GetUserTokenUser(ctx context.Context, purpose TokenPurpose, tokenHash string) (int64, error)
+	ConsumeUserToken(ctx context.Context, purpose TokenPurpose, tokenHash string) (int64, error)
+	SetTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, userID int64) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	GetRecoveryCodes(ctx context.Context, userID int64) ([]RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, codeID int64) (bool, error)
+	CreateAuthSession(ctx context.Context, userID int64, refreshTokenHash string, ttl time.Duration) (int64, error)
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, ttl time.Duration) (AuthSession, error)
+	RevokeAuthSession(ctx context.Context, userID, sessionID int64) error
+	RevokeAllAuthSessions(ctx context.Context, userID int64) error
+	IsAuthSessionActive(ctx context.Context, sessionID int64) (bool, error)
+	RecordLoginFailure(ctx context.Context, scope LoginScope, key string, window time.Duration) (int, error)
+	LockLogin(ctx context.Context, scope LoginScope, key string, d time.Duration) error
+	LoginLockoutRemaining(ctx context.Context, username, ip string) (time.Duration, error)
+	ClearLoginFailures(ctx context.Context, username string) error
+	GetUserForIdentity(ctx context.Context, issuer, subject string) (models.User, error)
+	LinkIdentity(ctx context.Context, userID int64, issuer, subject, email string) error
+	CreateUserWithIdentity(ctx context.Context, params *CreateIdentityUserParams) (models.User, error)
+	CreateOIDCAuthRequest(ctx context.Context, stateHash string, req OIDCAuthRequest, ttl time.Duration) error
+	ConsumeOIDCAuthRequest(ctx context.Context, stateHash string) (OIDCAuthRequest, error)
+	CreateAccessToken(ctx context.Context, params *CreateAccessTokenParams) (models.AccessToken, error)
+	ListAccessTokens(ctx context.Context, userID int64) ([]models.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID, tokenID int64) error
+	AuthenticateAccessToken(ctx context.Context, tokenHash string) (int64, []string, error)
+	GetPreferences(ctx context.Context, userID int64) (models.Preferences, error)
+	UpdatePreferences(ctx context.Context, params *UpdatePreferencesParams) (models.Preferences, error)
 }
 
 type Repository struct {
@@ -30,29 +70,47 @@ type CreateUserParams struct {
 }
 
 func (r *Repository) CreateUser(ctx context.Context, params *CreateUserParams) (models.User, error) {
-	row := r.pool.QueryRow(ctx, createUser, params.Username, params.Email, params.PwHash)
-	var user user
-	err := row.Scan(
-		&user.id,
-		&user.username,
-		&user.email,
-		&user.pwHash,
-		&user.createdAt,
-		&user.updatedAt,
-	)
+	user, err := scanUser(r.pool.QueryRow(ctx, createUser, params.Username, params.Email, params.PwHash))
 	if err != nil {
-		return models.User{}, fmt.Errorf("could not create user: %w", err)
+		return models.User{}, uniqueViolation(err, "could not create user")
 	}
-	return models.User{
-		ID:       user.id,
-		Username: user.username.String,
-		Email:    user.email.String,
-		PwHash:   user.pwHash.String,
-	}, nil
+	return user, nil
 }
 
 func (r *Repository) GetUserForUsername(ctx context.Context, username string) (models.User, error) {
-	row := r.pool.QueryRow(ctx, getUserByUsername, username)
+	user, err := scanUser(r.pool.QueryRow(ctx, getUserByUsername, username))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("could not get user for username: %s", username)
+	}
+	return user, nil
+}
+
+func (r *Repository) GetUserForEmail(ctx context.Context, email string) (models.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx, getUserByEmail, email))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("could not get user for email: %w", err)
+	}
+	return user, nil
+}
+
+func (r *Repository) GetUserByID(ctx context.Context, userID int64) (models.User, error) {
+	user, err := scanUser(r.pool.QueryRow(ctx, getUserByID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("could not get user: %w", err)
+	}
+	return user, nil
+}
+
+func scanUser(row pgx.Row) (models.User, error) {
 	var user user
 	err := row.Scan(
 		&user.id,
@@ -61,14 +119,20 @@ func (r *Repository) GetUserForUsername(ctx context.Context, username string) (m
 		&user.pwHash,
 		&user.createdAt,
 		&user.updatedAt,
+		&user.verified,
+		&user.totpSecret,
+		&user.totpEnabled,
 	)
 	if err != nil {
-		return models.User{}, fmt.Errorf("could not get user for username: %s", username)
+		return models.User{}, err
 	}
 	return models.User{
-		ID:       user.id,
-		Username: user.username.String,
-		Email:    user.email.String,
-		PwHash:   user.pwHash.String,
+		ID:            user.id,
+		Username:      user.username.String,
+		Email:         user.email.String,
+		PwHash:        user.pwHash.String,
+		EmailVerified: user.verified.Valid,
+		TOTPSecret:    user.totpSecret,
+		TOTPEnabled:   user.totpEnabled.Valid,
 	}, nil
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	ErrRefreshTokenReused  = apperror.Unauthorized("refresh_token_reused", "refresh token reused")
+)
+
+// AuthSession is a single login (e.g. one device). All refresh tokens rotated
+// from the same login belong to it and are revoked together.
+type AuthSession struct {
+	ID     int64
+	UserID int64
+}
+
+func (r *Repository) CreateAuthSession(ctx context.Context, userID int64, refreshTokenHash string, ttl time.Duration) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var sessionID int64
+	if err := tx.QueryRow(ctx, createAuthSession, userID).Scan(&sessionID); err != nil {
+		return 0, fmt.Errorf("failed to create auth session: %w", err)
+	}
+	if _, err := tx.Exec(ctx, createRefreshToken, sessionID, refreshTokenHash, ttl.Seconds()); err != nil {
+		return 0, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return sessionID, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// session. Presenting a token that was already rotated means it leaked, so
+// the whole session is revoked and ErrRefreshTokenReused is returned.
+func (r *Repository) RotateRefreshToken(ctx context.Context, oldHash, newHash string, ttl time.Duration) (AuthSession, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return AuthSession{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var (
+		tokenID                   int64
+		session                   AuthSession
+		rotated, expired, revoked bool
+	)
+	err = tx.QueryRow(ctx, getRefreshTokenForUpdate, oldHash).Scan(
+		&tokenID,
+		&session.ID,
+		&session.UserID,
+		&rotated,
+		&expired,
+		&revoked,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return AuthSession{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return AuthSession{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if revoked {
+		return AuthSession{}, ErrInvalidRefreshToken
+	}
+	if rotated {
+		if _, err := tx.Exec(ctx, revokeAuthSessionFamily, session.ID); err != nil {
+			return AuthSession{}, fmt.Errorf("failed to revoke auth session: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return AuthSession{}, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return AuthSession{}, ErrRefreshTokenReused
+	}
+	if expired {
+		return AuthSession{}, ErrInvalidRefreshToken
+	}
+	if _, err := tx.Exec(ctx, markRefreshTokenRotated, tokenID); err != nil {
+		return AuthSession{}, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if _, err := tx.Exec(ctx, createRefreshToken, session.ID, newHash, ttl.Seconds()); err != nil {
+		return AuthSession{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	if _, err := tx.Exec(ctx, touchAuthSession, session.ID); err != nil {
+		return AuthSession{}, fmt.Errorf("failed to update auth session: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return AuthSession{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return session, nil
+}
+
+func (r *Repository) RevokeAuthSession(ctx context.Context, userID, sessionID int64) error {
+	if _, err := r.pool.Exec(ctx, revokeAuthSession, sessionID, userID); err != nil {
+		return fmt.Errorf("failed to revoke auth session: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) RevokeAllAuthSessions(ctx context.Context, userID int64) error {
+	if _, err := r.pool.Exec(ctx, revokeAllAuthSessions, userID); err != nil {
+		return fmt.Errorf("failed to revoke auth sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) IsAuthSessionActive(ctx context.Context, sessionID int64) (bool, error) {
+	var active bool
+	if err := r.pool.QueryRow(ctx, isAuthSessionActive, sessionID).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check auth session: %w", err)
+	}
+	return active, nil
+}
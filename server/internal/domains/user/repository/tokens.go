@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidUserToken = apperror.Validation("invalid_token", "invalid or expired token",
+	apperror.Field("token", "invalid", "is invalid or expired"))
+
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeLoginChallenge    TokenPurpose = "login_challenge"
+)
+
+// CreateUserToken stores a new token, invalidating earlier unused tokens of
+// the same purpose so only the most recent email works.
+func (r *Repository) CreateUserToken(ctx context.Context, userID int64, purpose TokenPurpose, tokenHash string, ttl time.Duration) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, invalidateUserTokens, userID, purpose); err != nil {
+		return fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+	if _, err := tx.Exec(ctx, createUserToken, userID, purpose, tokenHash, ttl.Seconds()); err != nil {
+		return fmt.Errorf("failed to create user token: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) VerifyEmail(ctx context.Context, tokenHash string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	userID, err := consumeToken(ctx, tx, TokenPurposeEmailVerification, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, markEmailVerified, userID); err != nil {
+		return 0, fmt.Errorf("failed to verify email: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return userID, nil
+}
+
+// ResetPassword redeems a reset token, sets the new password and revokes
+// every login session of the user.
+func (r *Repository) ResetPassword(ctx context.Context, tokenHash, pwHash string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	userID, err := consumeToken(ctx, tx, TokenPurposePasswordReset, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, updatePassword, userID, pwHash); err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+	if _, err := tx.Exec(ctx, invalidateUserTokens, userID, TokenPurposePasswordReset); err != nil {
+		return 0, fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+	if _, err := tx.Exec(ctx, revokeAllAuthSessions, userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke auth sessions: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return userID, nil
+}
+
+// UpdatePassword changes the password and revokes all other login sessions,
+// keeping the one the change was made from.
+func (r *Repository) UpdatePassword(ctx context.Context, userID int64, pwHash string, keepSessionID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, updatePassword, userID, pwHash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if _, err := tx.Exec(ctx, revokeOtherAuthSessions, userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke auth sessions: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetUserTokenUser returns the user a valid token belongs to without
+// redeeming it.
+func (r *Repository) GetUserTokenUser(ctx context.Context, purpose TokenPurpose, tokenHash string) (int64, error) {
+	var userID int64
+	err := r.pool.QueryRow(ctx, getUserTokenUser, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidUserToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user token: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *Repository) ConsumeUserToken(ctx context.Context, purpose TokenPurpose, tokenHash string) (int64, error) {
+	var userID int64
+	err := r.pool.QueryRow(ctx, consumeUserToken, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidUserToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume user token: %w", err)
+	}
+	return userID, nil
+}
+
+func consumeToken(ctx context.Context, tx pgx.Tx, purpose TokenPurpose, tokenHash string) (int64, error) {
+	var userID int64
+	err := tx.QueryRow(ctx, consumeUserToken, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidUserToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume user token: %w", err)
+	}
+	return userID, nil
+}
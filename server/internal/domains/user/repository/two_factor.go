@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrTOTPState = apperror.Conflict("two_factor_state", "two-factor authentication is not in the expected state")
+
+type RecoveryCode struct {
+	ID   int64
+	Hash string
+}
+
+// SetTOTPSecret stores the secret of a pending enrollment. It fails with
+// ErrTOTPState if two-factor authentication is already enabled.
+func (r *Repository) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	tag, err := r.pool.Exec(ctx, setTOTPSecret, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to set totp secret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTOTPState
+	}
+	return nil
+}
+
+// EnableTOTP confirms a pending enrollment, recording the step of the code
+// that confirmed it, and replaces the user's recovery codes.
+func (r *Repository) EnableTOTP(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, enableTOTP, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTOTPState
+	}
+	if _, err := tx.Exec(ctx, deleteRecoveryCodes, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, codeHash := range recoveryCodeHashes {
+		if _, err := tx.Exec(ctx, createRecoveryCode, userID, codeHash); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, disableTOTP, userID); err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+	if _, err := tx.Exec(ctx, deleteRecoveryCodes, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep records step as used and reports false if it, or a later step,
+// was already accepted.
+func (r *Repository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, useTOTPStep, userID, step).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to use totp step: %w", err)
+	}
+	return true, nil
+}
+
+func (r *Repository) GetRecoveryCodes(ctx context.Context, userID int64) ([]RecoveryCode, error) {
+	rows, err := r.pool.Query(ctx, getRecoveryCodes, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]RecoveryCode, 0)
+	for rows.Next() {
+		var code RecoveryCode
+		if err := rows.Scan(&code.ID, &code.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode marks the code used and reports false if it already was.
+func (r *Repository) UseRecoveryCode(ctx context.Context, codeID int64) (bool, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, useRecoveryCode, codeID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return true, nil
+}
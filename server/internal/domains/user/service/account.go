@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
+	"github.com/TBuckholz5/workouttracker/internal/util/mailer"
+	"github.com/TBuckholz5/workouttracker/internal/util/token"
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+	minPasswordLength    = 8
+)
+
+var (
+	ErrUserNotFound = repository.ErrUserNotFound
+	ErrInvalidToken = repository.ErrInvalidUserToken
+	ErrWeakPassword = apperror.Validation("weak_password", fmt.Sprintf("password must be at least %d characters", minPasswordLength),
+		apperror.Field("password", "too_short", fmt.Sprintf("must be at least %d characters", minPasswordLength)))
+	ErrIncorrectPassword = apperror.Forbidden("incorrect_password", "current password is incorrect")
+)
+
+func (s *Service) ResendVerificationEmail(reqContext context.Context, userID int64) error {
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return s.sendVerificationEmail(reqContext, user)
+}
+
+func (s *Service) VerifyEmail(reqContext context.Context, verificationToken string) error {
+	_, err := s.repo.VerifyEmail(reqContext, token.Hash(verificationToken))
+	return err
+}
+
+// RequestPasswordReset mails a reset link if the address belongs to a user.
+// Unknown addresses are not reported, so the endpoint can't be used to
+// discover accounts.
+func (s *Service) RequestPasswordReset(reqContext context.Context, email string) error {
+	user, err := s.repo.GetUserForEmail(reqContext, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resetToken, err := s.createUserToken(reqContext, user.ID, repository.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(reqContext, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Workout Tracker password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in one hour.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n",
+			user.Username, s.link("/reset-password", resetToken)),
+	})
+}
+
+// ResetPassword sets a new password using a mailed reset token. All existing
+// sessions are logged out.
+func (s *Service) ResetPassword(reqContext context.Context, resetToken, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	hashedPassword, err := s.hasher.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.ResetPassword(reqContext, token.Hash(resetToken), hashedPassword)
+	return err
+}
+
+// ChangePassword requires the current password and logs out every other session.
+func (s *Service) ChangePassword(reqContext context.Context, params *ChangePasswordParams) error {
+	if len(params.NewPassword) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	user, err := s.repo.GetUserByID(reqContext, params.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.hasher.VerifyPassword(user.PwHash, params.CurrentPassword); err != nil {
+		return ErrIncorrectPassword
+	}
+	hashedPassword, err := s.hasher.HashPassword(params.NewPassword)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(reqContext, user.ID, hashedPassword, params.SessionID)
+}
+
+func (s *Service) sendVerificationEmail(reqContext context.Context, user models.User) error {
+	verificationToken, err := s.createUserToken(reqContext, user.ID, repository.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(reqContext, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Workout Tracker email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below.\n\n%s\n",
+			user.Username, s.link("/verify-email", verificationToken)),
+	})
+}
+
+func (s *Service) createUserToken(reqContext context.Context, userID int64, purpose repository.TokenPurpose, ttl time.Duration) (string, error) {
+	raw, err := token.Generate()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreateUserToken(reqContext, userID, purpose, token.Hash(raw), ttl); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (s *Service) link(path, rawToken string) string {
+	return s.appURL + path + "?" + url.Values{"token": {rawToken}}.Encode()
+}
+
+// logMailError keeps mail delivery problems from failing the request that
+// triggered them; users can ask for the email again.
+func logMailError(ctx context.Context, err error) {
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to send email", "error", err)
+	}
+}
@@ -1,5 +1,11 @@
 package service
 
+import (
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+)
+
 type RegisterParams struct {
 	Username string
 	Email    string
@@ -9,4 +15,84 @@ type RegisterParams struct {
 type LoginParams struct {
 	Username string
 	Password string
+	// ClientIP is used to throttle repeated failures from one address.
+	ClientIP string
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int
+}
+
+// LoginResult holds either the tokens of a completed login or, for users with
+// two-factor authentication, the challenge to pass to CompleteTwoFactorLogin.
+type LoginResult struct {
+	Tokens         *TokenPair
+	ChallengeToken string
+}
+
+type TwoFactorLoginParams struct {
+	ChallengeToken string
+	Code           string
+	ClientIP       string
+}
+
+type TOTPEnrollment struct {
+	Secret string
+	// URI is the otpauth:// link authenticator apps scan as a QR code.
+	URI string
+}
+
+type ChangePasswordParams struct {
+	UserID          int64
+	SessionID       int64
+	CurrentPassword string
+	NewPassword     string
+}
+
+type OIDCLoginStart struct {
+	// AuthorizationURL is where the user signs in with the provider.
+	AuthorizationURL string
+	// State must come back unchanged with the provider's redirect.
+	State string
+}
+
+type OIDCLoginParams struct {
+	Provider string
+	State    string
+	Code     string
+}
+
+type CreateAccessTokenParams struct {
+	UserID int64
+	Name   string
+	Scopes []string
+	// ExpiresIn defaults to 90 days when zero.
+	ExpiresIn time.Duration
+}
+
+type CreatedAccessToken struct {
+	AccessToken models.AccessToken
+	// Token is the secret, which can't be retrieved again.
+	Token string
+}
+
+type Profile struct {
+	Username         string
+	Email            string
+	EmailVerified    bool
+	TwoFactorEnabled bool
+	Preferences      models.Preferences
+}
+
+// UpdatePreferencesParams leaves nil fields unchanged. Bodyweight is given in
+// the weight unit the user has after the update.
+type UpdatePreferencesParams struct {
+	UserID             int64
+	WeightUnit         *string
+	Bodyweight         *float64
+	DefaultRestSeconds *int
+	WeekStart          *string
 }
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/oidc"
+	"github.com/TBuckholz5/workouttracker/internal/util/token"
+)
+
+const (
+	oidcStateTTL           = 10 * time.Minute
+	maxOIDCUsernameLength  = 30
+	oidcUsernameAttempts   = 5
+	defaultOIDCUsernameTag = "user"
+)
+
+var (
+	ErrUnknownOIDCProvider = apperror.NotFound("unknown_login_provider", "unknown login provider")
+	ErrInvalidOIDCState    = repository.ErrInvalidOIDCState
+	ErrOIDCLoginFailed     = apperror.Unauthorized("external_login_failed", "external login failed")
+	// ErrEmailInUse is returned when the provider reports the email of an
+	// account whose own address was never verified. Linking it could hand
+	// the account to whoever registered the address first.
+	ErrEmailInUse = apperror.Conflict("email_in_use", "email belongs to an existing unverified account",
+		apperror.Field("email", "taken", "belongs to an existing unverified account"))
+)
+
+// OIDCProvider is an OpenID Connect provider users can sign in with.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Identity, error)
+}
+
+// StartOIDCLogin begins an authorization code flow with PKCE. The verifier
+// and nonce stay on the server, keyed by the hash of the returned state.
+func (s *Service) StartOIDCLogin(reqContext context.Context, providerName string) (*OIDCLoginStart, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	state, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	req := repository.OIDCAuthRequest{
+		Provider:     providerName,
+		CodeVerifier: oidc.GenerateVerifier(),
+		Nonce:        nonce,
+	}
+	authURL, err := provider.AuthCodeURL(reqContext, state, nonce, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateOIDCAuthRequest(reqContext, token.Hash(state), req, oidcStateTTL); err != nil {
+		return nil, err
+	}
+	return &OIDCLoginStart{AuthorizationURL: authURL, State: state}, nil
+}
+
+// CompleteOIDCLogin handles the provider's redirect back. The external
+// identity is matched to a linked user, linked to the user with the same
+// verified email, or gets a new user. Two-factor users receive a challenge as
+// with password logins.
+func (s *Service) CompleteOIDCLogin(reqContext context.Context, params *OIDCLoginParams) (*LoginResult, error) {
+	provider, ok := s.oidcProviders[params.Provider]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	req, err := s.repo.ConsumeOIDCAuthRequest(reqContext, token.Hash(params.State))
+	if err != nil {
+		return nil, err
+	}
+	if req.Provider != params.Provider {
+		return nil, ErrInvalidOIDCState
+	}
+	identity, err := provider.Exchange(reqContext, params.Code, req.CodeVerifier, req.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+	}
+	user, err := s.resolveOIDCUser(reqContext, identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return s.issueLoginChallenge(reqContext, user.ID)
+	}
+	tokens, err := s.startSession(reqContext, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+func (s *Service) resolveOIDCUser(reqContext context.Context, identity *oidc.Identity) (models.User, error) {
+	user, err := s.repo.GetUserForIdentity(reqContext, identity.Issuer, identity.Subject)
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return user, err
+	}
+
+	if identity.Email != "" && identity.EmailVerified {
+		user, err := s.repo.GetUserForEmail(reqContext, identity.Email)
+		switch {
+		case err == nil && !user.EmailVerified:
+			return models.User{}, ErrEmailInUse
+		case err == nil:
+			err := s.repo.LinkIdentity(reqContext, user.ID, identity.Issuer, identity.Subject, identity.Email)
+			if errors.Is(err, repository.ErrIdentityLinked) {
+				return s.repo.GetUserForIdentity(reqContext, identity.Issuer, identity.Subject)
+			}
+			return user, err
+		case !errors.Is(err, repository.ErrUserNotFound):
+			return models.User{}, err
+		}
+	}
+	return s.createOIDCUser(reqContext, identity)
+}
+
+func (s *Service) createOIDCUser(reqContext context.Context, identity *oidc.Identity) (models.User, error) {
+	params := &repository.CreateIdentityUserParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	}
+	// An unverified address could belong to someone else, so it isn't kept.
+	if identity.EmailVerified {
+		params.Email = identity.Email
+		params.EmailVerified = true
+	}
+	base := oidcUsername(identity)
+	for attempt := range oidcUsernameAttempts {
+		params.Username = base
+		if attempt > 0 {
+			params.Username = fmt.Sprintf("%s-%04d", base, rand.IntN(10000))
+		}
+		user, err := s.repo.CreateUserWithIdentity(reqContext, params)
+		switch {
+		case errors.Is(err, repository.ErrUsernameTaken):
+			continue
+		case errors.Is(err, repository.ErrEmailTaken):
+			return models.User{}, ErrEmailInUse
+		case errors.Is(err, repository.ErrIdentityLinked):
+			return s.repo.GetUserForIdentity(reqContext, identity.Issuer, identity.Subject)
+		}
+		return user, err
+	}
+	return models.User{}, repository.ErrUsernameTaken
+}
+
+// oidcUsername derives a username from the provider's preferred username or
+// the local part of the email.
+func oidcUsername(identity *oidc.Identity) string {
+	candidate := identity.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(identity.Email, "@")
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(candidate) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' || r == '-' {
+			b.WriteRune(r)
+		}
+		if b.Len() == maxOIDCUsernameLength {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return defaultOIDCUsernameTag
+	}
+	return b.String()
+}
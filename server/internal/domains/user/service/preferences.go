@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+const (
+	maxBodyweightKg = 500
+	maxRestSeconds  = 3600
+)
+
+var (
+	ErrInvalidWeightUnit = apperror.Validation("invalid_weight_unit", "weight unit must be kg or lb",
+		apperror.Field("preferences.weightUnit", "invalid", "must be kg or lb"))
+	ErrInvalidBodyweight = apperror.Validation("invalid_bodyweight", "bodyweight must be above 0 and at most 500 kg",
+		apperror.Field("preferences.bodyweight", "range", "must be above 0 and at most 500 kg"))
+	ErrInvalidRestTime = apperror.Validation("invalid_rest_time", "default rest time must be between 0 and 3600 seconds",
+		apperror.Field("preferences.defaultRestSeconds", "range", "must be between 0 and 3600"))
+	ErrInvalidWeekStart = apperror.Validation("invalid_week_start", "week start must be a day of the week",
+		apperror.Field("preferences.weekStart", "invalid", "must be a day of the week"))
+)
+
+func (s *Service) GetProfile(reqContext context.Context, userID int64) (*Profile, error) {
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.repo.GetPreferences(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
+		Preferences:      preferences,
+	}, nil
+}
+
+func (s *Service) UpdatePreferences(reqContext context.Context, params *UpdatePreferencesParams) (*Profile, error) {
+	update := &repository.UpdatePreferencesParams{UserID: params.UserID}
+	if params.WeightUnit != nil {
+		unit, err := units.ParseWeightUnit(*params.WeightUnit)
+		if err != nil {
+			return nil, ErrInvalidWeightUnit
+		}
+		update.WeightUnit = &unit
+	}
+	if params.Bodyweight != nil {
+		var unit units.WeightUnit
+		if update.WeightUnit != nil {
+			unit = *update.WeightUnit
+		} else {
+			var err error
+			if unit, err = s.WeightUnit(reqContext, params.UserID); err != nil {
+				return nil, err
+			}
+		}
+		bodyweight := units.ToKilograms(*params.Bodyweight, unit)
+		if bodyweight <= 0 || bodyweight > maxBodyweightKg {
+			return nil, ErrInvalidBodyweight
+		}
+		update.Bodyweight = &bodyweight
+	}
+	if params.DefaultRestSeconds != nil {
+		if *params.DefaultRestSeconds < 0 || *params.DefaultRestSeconds > maxRestSeconds {
+			return nil, ErrInvalidRestTime
+		}
+		update.DefaultRestSeconds = params.DefaultRestSeconds
+	}
+	if params.WeekStart != nil {
+		weekStart, err := parseWeekday(*params.WeekStart)
+		if err != nil {
+			return nil, err
+		}
+		update.WeekStart = &weekStart
+	}
+
+	if _, err := s.repo.UpdatePreferences(reqContext, update); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(reqContext, params.UserID)
+}
+
+// WeightUnit implements units.Resolver so other domains can convert weights
+// for the user.
+func (s *Service) WeightUnit(reqContext context.Context, userID int64) (units.WeightUnit, error) {
+	preferences, err := s.repo.GetPreferences(reqContext, userID)
+	if err != nil {
+		return "", err
+	}
+	return preferences.WeightUnit, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, ErrInvalidWeekStart
+}
@@ -2,29 +2,70 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
 	"github.com/TBuckholz5/workouttracker/internal/util/hash"
 	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
+	"github.com/TBuckholz5/workouttracker/internal/util/mailer"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
 )
 
 type UserService interface {
 	CreateUser(reqContext context.Context, userDto *RegisterParams) error
-	AuthenticateUser(reqContext context.Context, loginDto *LoginParams) (string, error)
+	AuthenticateUser(reqContext context.Context, loginDto *LoginParams) (*LoginResult, error)
+	CompleteTwoFactorLogin(reqContext context.Context, params *TwoFactorLoginParams) (*TokenPair, error)
+	RefreshSession(reqContext context.Context, refreshToken string) (*TokenPair, error)
+	Logout(reqContext context.Context, userID, sessionID int64) error
+	LogoutAll(reqContext context.Context, userID int64) error
+	IsSessionActive(reqContext context.Context, sessionID int64) (bool, error)
+	ResendVerificationEmail(reqContext context.Context, userID int64) error
+	VerifyEmail(reqContext context.Context, verificationToken string) error
+	RequestPasswordReset(reqContext context.Context, email string) error
+	ResetPassword(reqContext context.Context, resetToken, newPassword string) error
+	ChangePassword(reqContext context.Context, params *ChangePasswordParams) error
+	EnrollTOTP(reqContext context.Context, userID int64) (*TOTPEnrollment, error)
+	ConfirmTOTP(reqContext context.Context, userID int64, code string) ([]string, error)
+	DisableTOTP(reqContext context.Context, userID int64, code string) error
+	StartOIDCLogin(reqContext context.Context, provider string) (*OIDCLoginStart, error)
+	CompleteOIDCLogin(reqContext context.Context, params *OIDCLoginParams) (*LoginResult, error)
+	CreateAccessToken(reqContext context.Context, params *CreateAccessTokenParams) (*CreatedAccessToken, error)
+	ListAccessTokens(reqContext context.Context, userID int64) ([]models.AccessToken, error)
+	RevokeAccessToken(reqContext context.Context, userID, tokenID int64) error
+	AuthenticateAccessToken(reqContext context.Context, rawToken string) (int64, []string, bool, error)
+	GetProfile(reqContext context.Context, userID int64) (*Profile, error)
+	UpdatePreferences(reqContext context.Context, params *UpdatePreferencesParams) (*Profile, error)
+	WeightUnit(reqContext context.Context, userID int64) (units.WeightUnit, error)
 }
 
 type Service struct {
 	repo       repository.UserRepository
 	jwtService jwt.JwtService
 	hasher     hash.Hasher
+	mailer     mailer.Mailer
+	// appURL is the base of links sent by email.
+	appURL string
+	// oidcProviders are keyed by the name used in login URLs.
+	oidcProviders map[string]OIDCProvider
+
+	dummyHashOnce sync.Once
+	dummyHash     string
+	now           func() time.Time
 }
 
-func NewService(r repository.UserRepository, hasher hash.Hasher, jwtService jwt.JwtService) *Service {
+func NewService(r repository.UserRepository, hasher hash.Hasher, jwtService jwt.JwtService, m mailer.Mailer, appURL string, oidcProviders map[string]OIDCProvider) *Service {
 	return &Service{
-		repo:       r,
-		hasher:     hasher,
-		jwtService: jwtService,
+		repo:          r,
+		hasher:        hasher,
+		jwtService:    jwtService,
+		mailer:        m,
+		appURL:        strings.TrimSuffix(appURL, "/"),
+		now:           time.Now,
+		oidcProviders: oidcProviders,
 	}
 }
 
@@ -33,29 +74,54 @@ func (s *Service) CreateUser(reqContext context.Context, userDto *RegisterParams
 	if err != nil {
 		return err
 	}
-	_, err = s.repo.CreateUser(reqContext, &repository.CreateUserParams{
+	user, err := s.repo.CreateUser(reqContext, &repository.CreateUserParams{
 		Username: userDto.Username,
 		Email:    userDto.Email,
 		PwHash:   hashedPassword,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	logMailError(reqContext, s.sendVerificationEmail(reqContext, user))
+	return nil
 }
 
-func (s *Service) AuthenticateUser(reqContext context.Context, loginDto *LoginParams) (string, error) {
+// AuthenticateUser checks the credentials and opens a login session. Failed
+// attempts are counted per username and client IP; once either is locked the
+// password isn't checked at all and a *LoginThrottledError is returned. Users
+// with two-factor authentication get a challenge instead of tokens.
+func (s *Service) AuthenticateUser(reqContext context.Context, loginDto *LoginParams) (*LoginResult, error) {
+	if err := s.checkLoginLockout(reqContext, loginDto); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repo.GetUserForUsername(reqContext, loginDto.Username)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
 	if err != nil {
-		return "", err
+		_ = s.hasher.VerifyPassword(s.dummyPasswordHash(), loginDto.Password)
+	} else {
+		err = s.hasher.VerifyPassword(user.PwHash, loginDto.Password)
 	}
-
-	err = s.hasher.VerifyPassword(user.PwHash, loginDto.Password)
 	if err != nil {
-		return "", fmt.Errorf("passwords do not match")
+		if err := s.recordLoginFailure(reqContext, loginDto); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	token, err := s.jwtService.GenerateJwt(user.ID)
+	// Failures are only cleared once the second factor passed too, so
+	// knowing the password doesn't reset the throttle on code guesses.
+	if user.TOTPEnabled {
+		return s.issueLoginChallenge(reqContext, user.ID)
+	}
+	if err := s.repo.ClearLoginFailures(reqContext, loginKey(loginDto.Username)); err != nil {
+		return nil, err
+	}
+	tokens, err := s.startSession(reqContext, user.ID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	return token, nil
+	return &LoginResult{Tokens: tokens}, nil
 }
@@ -3,10 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
+	"github.com/TBuckholz5/workouttracker/internal/util/mailer"
+	"github.com/TBuckholz5/workouttracker/internal/util/oidc"
+	"github.com/TBuckholz5/workouttracker/internal/util/token"
+	"github.com/TBuckholz5/workouttracker/internal/util/totp"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,6 +34,208 @@ func (m *mockUserRepo) GetUserForUsername(ctx context.Context, username string)
 	return args.Get(0).(models.User), args.Error(1)
 }
 
+func (m *mockUserRepo) CreateAuthSession(ctx context.Context, userID int64, refreshTokenHash string, ttl time.Duration) (int64, error) {
+	args := m.Called(ctx, userID, refreshTokenHash, ttl)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepo) RotateRefreshToken(ctx context.Context, oldHash, newHash string, ttl time.Duration) (repository.AuthSession, error) {
+	args := m.Called(ctx, oldHash, newHash, ttl)
+	return args.Get(0).(repository.AuthSession), args.Error(1)
+}
+
+func (m *mockUserRepo) RevokeAuthSession(ctx context.Context, userID, sessionID int64) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) RevokeAllAuthSessions(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) IsAuthSessionActive(ctx context.Context, sessionID int64) (bool, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUserRepo) GetUserForEmail(ctx context.Context, email string) (models.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *mockUserRepo) GetUserByID(ctx context.Context, userID int64) (models.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *mockUserRepo) UpdatePassword(ctx context.Context, userID int64, pwHash string, keepSessionID int64) error {
+	args := m.Called(ctx, userID, pwHash, keepSessionID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) CreateUserToken(ctx context.Context, userID int64, purpose repository.TokenPurpose, tokenHash string, ttl time.Duration) error {
+	args := m.Called(ctx, userID, purpose, tokenHash, ttl)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) VerifyEmail(ctx context.Context, tokenHash string) (int64, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepo) ResetPassword(ctx context.Context, tokenHash, pwHash string) (int64, error) {
+	args := m.Called(ctx, tokenHash, pwHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepo) RecordLoginFailure(ctx context.Context, scope repository.LoginScope, key string, window time.Duration) (int, error) {
+	args := m.Called(ctx, scope, key, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepo) LockLogin(ctx context.Context, scope repository.LoginScope, key string, d time.Duration) error {
+	args := m.Called(ctx, scope, key, d)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) LoginLockoutRemaining(ctx context.Context, username, ip string) (time.Duration, error) {
+	args := m.Called(ctx, username, ip)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *mockUserRepo) ClearLoginFailures(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) GetUserTokenUser(ctx context.Context, purpose repository.TokenPurpose, tokenHash string) (int64, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepo) ConsumeUserToken(ctx context.Context, purpose repository.TokenPurpose, tokenHash string) (int64, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepo) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) EnableTOTP(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, userID, step, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) DisableTOTP(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUserRepo) GetRecoveryCodes(ctx context.Context, userID int64) ([]repository.RecoveryCode, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]repository.RecoveryCode), args.Error(1)
+}
+
+func (m *mockUserRepo) UseRecoveryCode(ctx context.Context, codeID int64) (bool, error) {
+	args := m.Called(ctx, codeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUserRepo) GetUserForIdentity(ctx context.Context, issuer, subject string) (models.User, error) {
+	args := m.Called(ctx, issuer, subject)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *mockUserRepo) LinkIdentity(ctx context.Context, userID int64, issuer, subject, email string) error {
+	args := m.Called(ctx, userID, issuer, subject, email)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) CreateUserWithIdentity(ctx context.Context, params *repository.CreateIdentityUserParams) (models.User, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *mockUserRepo) CreateOIDCAuthRequest(ctx context.Context, stateHash string, req repository.OIDCAuthRequest, ttl time.Duration) error {
+	args := m.Called(ctx, stateHash, req, ttl)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) ConsumeOIDCAuthRequest(ctx context.Context, stateHash string) (repository.OIDCAuthRequest, error) {
+	args := m.Called(ctx, stateHash)
+	return args.Get(0).(repository.OIDCAuthRequest), args.Error(1)
+}
+
+func (m *mockUserRepo) CreateAccessToken(ctx context.Context, params *repository.CreateAccessTokenParams) (models.AccessToken, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.AccessToken), args.Error(1)
+}
+
+func (m *mockUserRepo) ListAccessTokens(ctx context.Context, userID int64) ([]models.AccessToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.AccessToken), args.Error(1)
+}
+
+func (m *mockUserRepo) RevokeAccessToken(ctx context.Context, userID, tokenID int64) error {
+	args := m.Called(ctx, userID, tokenID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) AuthenticateAccessToken(ctx context.Context, tokenHash string) (int64, []string, error) {
+	args := m.Called(ctx, tokenHash)
+	scopes, _ := args.Get(1).([]string)
+	return args.Get(0).(int64), scopes, args.Error(2)
+}
+
+func (m *mockUserRepo) GetPreferences(ctx context.Context, userID int64) (models.Preferences, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Preferences), args.Error(1)
+}
+
+func (m *mockUserRepo) UpdatePreferences(ctx context.Context, params *repository.UpdatePreferencesParams) (models.Preferences, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.Preferences), args.Error(1)
+}
+
+// allowLogin sets up a login throttle that never locks.
+func allowLogin(repo *mockUserRepo) {
+	repo.On("LoginLockoutRemaining", mock.Anything, mock.Anything, mock.Anything).Return(time.Duration(0), nil)
+	repo.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	repo.On("ClearLoginFailures", mock.Anything, mock.Anything).Return(nil)
+}
+
+type mockOIDCProvider struct {
+	mock.Mock
+}
+
+func (m *mockOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	args := m.Called(ctx, state, nonce, verifier)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockOIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Identity, error) {
+	args := m.Called(ctx, code, verifier, nonce)
+	identity, _ := args.Get(0).(*oidc.Identity)
+	return identity, args.Error(1)
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
 type mockHasher struct {
 	mock.Mock
 }
@@ -43,14 +254,14 @@ type mockJwtService struct {
 	mock.Mock
 }
 
-func (m *mockJwtService) GenerateJwt(userID int64) (string, error) {
-	args := m.Called(userID)
+func (m *mockJwtService) GenerateJwt(userID, sessionID int64) (string, error) {
+	args := m.Called(userID, sessionID)
 	return args.String(0), args.Error(1)
 }
 
-func (m *mockJwtService) ValidateJwt(tokenString string) (int64, error) {
+func (m *mockJwtService) ValidateJwt(tokenString string) (jwt.Claims, error) {
 	args := m.Called(tokenString)
-	return args.Get(0).(int64), args.Error(1)
+	return args.Get(0).(jwt.Claims), args.Error(1)
 }
 
 func TestCreateUser_Success(t *testing.T) {
@@ -58,12 +269,16 @@ func TestCreateUser_Success(t *testing.T) {
 	hashedPassword := "hashedpassword123"
 
 	repo := &mockUserRepo{}
-	repo.On("CreateUser", mock.Anything, mock.Anything).Return(models.User{ID: 1}, nil)
+	repo.On("CreateUser", mock.Anything, mock.Anything).Return(models.User{ID: 1, Email: "test@example.com"}, nil)
+	repo.On("CreateUserToken", mock.Anything, int64(1), repository.TokenPurposeEmailVerification, mock.Anything, emailVerificationTTL).Return(nil)
 
 	hasher := &mockHasher{}
 	hasher.On("HashPassword", password).Return(hashedPassword, nil)
 
-	s := NewService(repo, hasher, nil)
+	m := &mockMailer{}
+	m.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	s := NewService(repo, hasher, nil, m, "https://app.example.com/", nil)
 	req := &RegisterParams{
 		Username: "testuser",
 		Email:    "test@example.com",
@@ -73,6 +288,9 @@ func TestCreateUser_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
+	m.AssertCalled(t, "Send", mock.Anything, mock.MatchedBy(func(msg mailer.Message) bool {
+		return msg.To == "test@example.com" && strings.Contains(msg.Body, "https://app.example.com/verify-email?token=")
+	}))
 
 	repo.AssertNumberOfCalls(t, "CreateUser", 1)
 	repo.AssertCalled(t, "CreateUser", mock.Anything, mock.MatchedBy(func(arg *repository.CreateUserParams) bool {
@@ -89,7 +307,7 @@ func TestCreateUser_HashError(t *testing.T) {
 	hasher := &mockHasher{}
 	hasher.On("HashPassword", password).Return("", fmt.Errorf("hash error"))
 
-	s := NewService(repo, hasher, nil)
+	s := NewService(repo, hasher, nil, nil, "", nil)
 	req := &RegisterParams{
 		Username: "testuser",
 		Email:    "test@example.com",
@@ -107,6 +325,7 @@ func TestAuthenticateUser_Success(t *testing.T) {
 	tokenString := "validtoken"
 
 	repo := &mockUserRepo{}
+	allowLogin(repo)
 	repo.On("GetUserForUsername", mock.Anything, mock.MatchedBy(func(arg string) bool {
 		return arg == "testuser"
 	})).Return(models.User{
@@ -117,18 +336,25 @@ func TestAuthenticateUser_Success(t *testing.T) {
 	hasher := &mockHasher{}
 	hasher.On("VerifyPassword", string(hashedPassword), password).Return(nil)
 
+	repo.On("CreateAuthSession", mock.Anything, int64(1), mock.Anything, refreshTokenTTL).Return(int64(7), nil)
+
 	jwtService := &mockJwtService{}
-	jwtService.On("GenerateJwt", int64(1)).Return(tokenString, nil)
+	jwtService.On("GenerateJwt", int64(1), int64(7)).Return(tokenString, nil)
 
-	s := NewService(repo, hasher, jwtService)
+	s := NewService(repo, hasher, jwtService, nil, "", nil)
 	req := &LoginParams{
 		Username: "testuser",
 		Password: password,
+		ClientIP: "203.0.113.7",
 	}
-	token, err := s.AuthenticateUser(context.Background(), req)
+	result, err := s.AuthenticateUser(context.Background(), req)
 
 	assert.Nil(t, err)
-	assert.Equal(t, tokenString, token)
+	tokens := result.Tokens
+	assert.Equal(t, tokenString, tokens.AccessToken)
+	repo.AssertCalled(t, "ClearLoginFailures", mock.Anything, "testuser")
+	assert.NotEmpty(t, tokens.RefreshToken)
+	repo.AssertCalled(t, "CreateAuthSession", mock.Anything, int64(1), token.Hash(tokens.RefreshToken), refreshTokenTTL)
 	repo.AssertNumberOfCalls(t, "GetUserForUsername", 1)
 	repo.AssertCalled(t, "GetUserForUsername", mock.Anything, mock.MatchedBy(func(arg string) bool {
 		return arg == "testuser"
@@ -139,19 +365,29 @@ func TestAuthenticateUser_UserNotFound(t *testing.T) {
 	password := "password123"
 
 	repo := &mockUserRepo{}
+	allowLogin(repo)
 	repo.On("GetUserForUsername", mock.Anything, mock.MatchedBy(func(arg string) bool {
 		return arg == "testuser"
-	})).Return(models.User{}, fmt.Errorf("user not found"))
+	})).Return(models.User{}, repository.ErrUserNotFound)
 
-	s := NewService(repo, nil, nil)
+	// The password is still checked against a dummy hash to keep timing even.
+	hasher := &mockHasher{}
+	hasher.On("HashPassword", mock.Anything).Return("dummy-hash", nil)
+	hasher.On("VerifyPassword", "dummy-hash", password).Return(fmt.Errorf("mismatch"))
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
 	req := &LoginParams{
 		Username: "testuser",
 		Password: password,
+		ClientIP: "203.0.113.7",
 	}
 	_, err := s.AuthenticateUser(context.Background(), req)
 
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
 	repo.AssertNumberOfCalls(t, "GetUserForUsername", 1)
+	hasher.AssertCalled(t, "VerifyPassword", "dummy-hash", password)
+	repo.AssertCalled(t, "RecordLoginFailure", mock.Anything, repository.LoginScopeUsername, "testuser", usernameThrottle.window)
+	repo.AssertCalled(t, "RecordLoginFailure", mock.Anything, repository.LoginScopeIP, "203.0.113.7", ipThrottle.window)
 }
 
 func TestAuthenticateUser_PasswordMismatchError(t *testing.T) {
@@ -159,6 +395,7 @@ func TestAuthenticateUser_PasswordMismatchError(t *testing.T) {
 	hashedPassword := []byte("test")
 
 	repo := &mockUserRepo{}
+	allowLogin(repo)
 	repo.On("GetUserForUsername", mock.Anything, mock.MatchedBy(func(arg string) bool {
 		return arg == "testuser"
 	})).Return(models.User{
@@ -169,7 +406,7 @@ func TestAuthenticateUser_PasswordMismatchError(t *testing.T) {
 	hasher := &mockHasher{}
 	hasher.On("VerifyPassword", string(hashedPassword), password).Return(fmt.Errorf("passwords do not match"))
 
-	s := NewService(repo, hasher, nil)
+	s := NewService(repo, hasher, nil, nil, "", nil)
 	req := &LoginParams{
 		Username: "testuser",
 		Password: password,
@@ -190,6 +427,7 @@ func TestAuthenticateUser_JwtError(t *testing.T) {
 	hashedPassword := []byte("test")
 
 	repo := &mockUserRepo{}
+	allowLogin(repo)
 	repo.On("GetUserForUsername", mock.Anything, mock.MatchedBy(func(arg string) bool {
 		return arg == "testuser"
 	})).Return(models.User{
@@ -200,10 +438,12 @@ func TestAuthenticateUser_JwtError(t *testing.T) {
 	hasher := &mockHasher{}
 	hasher.On("VerifyPassword", string(hashedPassword), password).Return(nil)
 
+	repo.On("CreateAuthSession", mock.Anything, int64(1), mock.Anything, refreshTokenTTL).Return(int64(7), nil)
+
 	jwtService := &mockJwtService{}
-	jwtService.On("GenerateJwt", int64(1)).Return("", fmt.Errorf("jwt generation error"))
+	jwtService.On("GenerateJwt", int64(1), int64(7)).Return("", fmt.Errorf("jwt generation error"))
 
-	s := NewService(repo, hasher, jwtService)
+	s := NewService(repo, hasher, jwtService, nil, "", nil)
 	req := &LoginParams{
 		Username: "testuser",
 		Password: password,
@@ -218,5 +458,614 @@ func TestAuthenticateUser_JwtError(t *testing.T) {
 	hasher.AssertNumberOfCalls(t, "VerifyPassword", 1)
 	hasher.AssertCalled(t, "VerifyPassword", string(hashedPassword), password)
 	jwtService.AssertNumberOfCalls(t, "GenerateJwt", 1)
-	jwtService.AssertCalled(t, "GenerateJwt", int64(1))
+	jwtService.AssertCalled(t, "GenerateJwt", int64(1), int64(7))
+}
+
+func TestRefreshSession_Success(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("RotateRefreshToken", mock.Anything, token.Hash("old-token"), mock.Anything, refreshTokenTTL).
+		Return(repository.AuthSession{ID: 7, UserID: 1}, nil)
+
+	jwtService := &mockJwtService{}
+	jwtService.On("GenerateJwt", int64(1), int64(7)).Return("access", nil)
+
+	s := NewService(repo, nil, jwtService, nil, "", nil)
+	tokens, err := s.RefreshSession(context.Background(), "old-token")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "access", tokens.AccessToken)
+	assert.NotEqual(t, "old-token", tokens.RefreshToken)
+	repo.AssertCalled(t, "RotateRefreshToken", mock.Anything, token.Hash("old-token"), token.Hash(tokens.RefreshToken), refreshTokenTTL)
+}
+
+func TestRefreshSession_Reused(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(repository.AuthSession{}, repository.ErrRefreshTokenReused)
+
+	jwtService := &mockJwtService{}
+
+	s := NewService(repo, nil, jwtService, nil, "", nil)
+	_, err := s.RefreshSession(context.Background(), "rotated-token")
+
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	jwtService.AssertNotCalled(t, "GenerateJwt", mock.Anything, mock.Anything)
+}
+
+func TestLogout(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("RevokeAuthSession", mock.Anything, int64(1), int64(7)).Return(nil)
+	repo.On("RevokeAllAuthSessions", mock.Anything, int64(1)).Return(nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	assert.Nil(t, s.Logout(context.Background(), 1, 7))
+	assert.Nil(t, s.LogoutAll(context.Background(), 1))
+	repo.AssertExpectations(t)
+}
+
+func TestIsSessionActive(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("IsAuthSessionActive", mock.Anything, int64(7)).Return(false, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	active, err := s.IsSessionActive(context.Background(), 7)
+
+	assert.Nil(t, err)
+	assert.False(t, active)
+}
+
+func TestCreateUser_MailFailureDoesNotFailRegistration(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("CreateUser", mock.Anything, mock.Anything).Return(models.User{ID: 1}, nil)
+	repo.On("CreateUserToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	hasher := &mockHasher{}
+	hasher.On("HashPassword", mock.Anything).Return("hashed", nil)
+
+	m := &mockMailer{}
+	m.On("Send", mock.Anything, mock.Anything).Return(fmt.Errorf("smtp down"))
+
+	s := NewService(repo, hasher, nil, m, "", nil)
+	err := s.CreateUser(context.Background(), &RegisterParams{Username: "u", Email: "u@example.com", Password: "password123"})
+	assert.Nil(t, err)
+}
+
+// mailedToken extracts the token from the link in a sent message.
+func mailedToken(t *testing.T, m *mockMailer) string {
+	t.Helper()
+	msg := m.Calls[len(m.Calls)-1].Arguments.Get(1).(mailer.Message)
+	start := strings.Index(msg.Body, "?token=")
+	end := strings.IndexAny(msg.Body[start:], "\n ")
+	values, err := url.ParseQuery(msg.Body[start+1 : start+end])
+	assert.NoError(t, err)
+	return values.Get("token")
+}
+
+func TestVerifyEmail(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("VerifyEmail", mock.Anything, token.Hash("abc")).Return(int64(1), nil)
+	repo.On("VerifyEmail", mock.Anything, mock.Anything).Return(int64(0), repository.ErrInvalidUserToken)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	assert.Nil(t, s.VerifyEmail(context.Background(), "abc"))
+	assert.ErrorIs(t, s.VerifyEmail(context.Background(), "used"), ErrInvalidToken)
+}
+
+func TestResendVerificationEmail_AlreadyVerified(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, EmailVerified: true}, nil)
+	m := &mockMailer{}
+
+	s := NewService(repo, nil, nil, m, "", nil)
+	assert.Nil(t, s.ResendVerificationEmail(context.Background(), 1))
+	m.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestRequestPasswordReset_SendsStoredToken(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserForEmail", mock.Anything, "test@example.com").Return(models.User{ID: 1, Email: "test@example.com"}, nil)
+	repo.On("CreateUserToken", mock.Anything, int64(1), repository.TokenPurposePasswordReset, mock.Anything, passwordResetTTL).Return(nil)
+	m := &mockMailer{}
+	m.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	s := NewService(repo, nil, nil, m, "https://app.example.com", nil)
+	assert.Nil(t, s.RequestPasswordReset(context.Background(), "test@example.com"))
+
+	raw := mailedToken(t, m)
+	assert.NotEmpty(t, raw)
+	// Only the hash of the mailed token is stored.
+	repo.AssertCalled(t, "CreateUserToken", mock.Anything, int64(1), repository.TokenPurposePasswordReset, token.Hash(raw), passwordResetTTL)
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserForEmail", mock.Anything, mock.Anything).Return(models.User{}, repository.ErrUserNotFound)
+	m := &mockMailer{}
+
+	s := NewService(repo, nil, nil, m, "", nil)
+	assert.Nil(t, s.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	m.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("ResetPassword", mock.Anything, token.Hash("reset"), "hashed").Return(int64(1), nil)
+	hasher := &mockHasher{}
+	hasher.On("HashPassword", "newpassword").Return("hashed", nil)
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	assert.Nil(t, s.ResetPassword(context.Background(), "reset", "newpassword"))
+	assert.ErrorIs(t, s.ResetPassword(context.Background(), "reset", "short"), ErrWeakPassword)
+	repo.AssertNumberOfCalls(t, "ResetPassword", 1)
+}
+
+func TestChangePassword(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, PwHash: "current-hash"}, nil)
+	repo.On("UpdatePassword", mock.Anything, int64(1), "new-hash", int64(7)).Return(nil)
+	hasher := &mockHasher{}
+	hasher.On("VerifyPassword", "current-hash", "current").Return(nil)
+	hasher.On("VerifyPassword", "current-hash", "wrong").Return(fmt.Errorf("mismatch"))
+	hasher.On("HashPassword", "newpassword").Return("new-hash", nil)
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	params := &ChangePasswordParams{UserID: 1, SessionID: 7, CurrentPassword: "current", NewPassword: "newpassword"}
+	assert.Nil(t, s.ChangePassword(context.Background(), params))
+
+	params.CurrentPassword = "wrong"
+	assert.ErrorIs(t, s.ChangePassword(context.Background(), params), ErrIncorrectPassword)
+	repo.AssertNumberOfCalls(t, "UpdatePassword", 1)
+}
+
+func TestAuthenticateUser_Locked(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("LoginLockoutRemaining", mock.Anything, "testuser", "203.0.113.7").Return(90*time.Second, nil)
+	hasher := &mockHasher{}
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	_, err := s.AuthenticateUser(context.Background(), &LoginParams{Username: "TestUser", Password: "x", ClientIP: "203.0.113.7"})
+
+	var throttled *LoginThrottledError
+	assert.ErrorAs(t, err, &throttled)
+	assert.Equal(t, 90*time.Second, throttled.RetryAfter)
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	repo.AssertNotCalled(t, "GetUserForUsername", mock.Anything, mock.Anything)
+	hasher.AssertNotCalled(t, "VerifyPassword", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateUser_LocksAfterRepeatedFailures(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("LoginLockoutRemaining", mock.Anything, mock.Anything, mock.Anything).Return(time.Duration(0), nil)
+	repo.On("GetUserForUsername", mock.Anything, "testuser").Return(models.User{ID: 1, PwHash: "hash"}, nil)
+	repo.On("RecordLoginFailure", mock.Anything, repository.LoginScopeUsername, "testuser", usernameThrottle.window).
+		Return(usernameThrottle.freeAttempts+2, nil)
+	repo.On("LockLogin", mock.Anything, repository.LoginScopeUsername, "testuser", 2*time.Second).Return(nil)
+	hasher := &mockHasher{}
+	hasher.On("VerifyPassword", "hash", "wrong").Return(fmt.Errorf("mismatch"))
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	_, err := s.AuthenticateUser(context.Background(), &LoginParams{Username: "testuser", Password: "wrong"})
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	repo.AssertCalled(t, "LockLogin", mock.Anything, repository.LoginScopeUsername, "testuser", 2*time.Second)
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	throttle := loginThrottle{freeAttempts: 3, baseDelay: time.Second, maxDelay: 10 * time.Second}
+	assert.Equal(t, time.Duration(0), throttle.lockout(3))
+	assert.Equal(t, time.Second, throttle.lockout(4))
+	assert.Equal(t, 4*time.Second, throttle.lockout(6))
+	assert.Equal(t, 10*time.Second, throttle.lockout(8))
+	assert.Equal(t, 10*time.Second, throttle.lockout(1000))
+}
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+func testTOTPCode(t *testing.T, at time.Time) string {
+	t.Helper()
+	code, err := totp.Code(testTOTPSecret, at)
+	assert.NoError(t, err)
+	return code
+}
+
+func TestAuthenticateUser_TwoFactorIssuesChallenge(t *testing.T) {
+	repo := &mockUserRepo{}
+	allowLogin(repo)
+	repo.On("GetUserForUsername", mock.Anything, "testuser").
+		Return(models.User{ID: 1, Username: "testuser", PwHash: "hash", TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+	repo.On("CreateUserToken", mock.Anything, int64(1), repository.TokenPurposeLoginChallenge, mock.Anything, loginChallengeTTL).Return(nil)
+	hasher := &mockHasher{}
+	hasher.On("VerifyPassword", "hash", "password123").Return(nil)
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	result, err := s.AuthenticateUser(context.Background(), &LoginParams{Username: "testuser", Password: "password123"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, result.Tokens)
+	assert.NotEmpty(t, result.ChallengeToken)
+	repo.AssertCalled(t, "CreateUserToken", mock.Anything, int64(1), repository.TokenPurposeLoginChallenge, token.Hash(result.ChallengeToken), loginChallengeTTL)
+	repo.AssertNotCalled(t, "CreateAuthSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "ClearLoginFailures", mock.Anything, mock.Anything)
+}
+
+func TestCompleteTwoFactorLogin(t *testing.T) {
+	challengeHash := token.Hash("challenge")
+	repo := &mockUserRepo{}
+	allowLogin(repo)
+	repo.On("GetUserTokenUser", mock.Anything, repository.TokenPurposeLoginChallenge, challengeHash).Return(int64(1), nil)
+	repo.On("GetUserByID", mock.Anything, int64(1)).
+		Return(models.User{ID: 1, Username: "testuser", TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+	repo.On("UseTOTPStep", mock.Anything, int64(1), totp.Step(testNow)).Return(true, nil)
+	repo.On("ConsumeUserToken", mock.Anything, repository.TokenPurposeLoginChallenge, challengeHash).Return(int64(1), nil)
+	repo.On("CreateAuthSession", mock.Anything, int64(1), mock.Anything, refreshTokenTTL).Return(int64(7), nil)
+	jwtService := &mockJwtService{}
+	jwtService.On("GenerateJwt", int64(1), int64(7)).Return("access", nil)
+
+	s := NewService(repo, nil, jwtService, nil, "", nil)
+	s.now = func() time.Time { return testNow }
+	tokens, err := s.CompleteTwoFactorLogin(context.Background(), &TwoFactorLoginParams{
+		ChallengeToken: "challenge",
+		Code:           testTOTPCode(t, testNow),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "access", tokens.AccessToken)
+	repo.AssertCalled(t, "ClearLoginFailures", mock.Anything, "testuser")
+}
+
+func TestCompleteTwoFactorLogin_ReplayedCodeCountsAsFailure(t *testing.T) {
+	repo := &mockUserRepo{}
+	allowLogin(repo)
+	repo.On("GetUserTokenUser", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+	repo.On("GetUserByID", mock.Anything, int64(1)).
+		Return(models.User{ID: 1, Username: "testuser", TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+	repo.On("UseTOTPStep", mock.Anything, int64(1), mock.Anything).Return(false, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	s.now = func() time.Time { return testNow }
+	_, err := s.CompleteTwoFactorLogin(context.Background(), &TwoFactorLoginParams{
+		ChallengeToken: "challenge",
+		Code:           testTOTPCode(t, testNow),
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidTOTPCode)
+	repo.AssertCalled(t, "RecordLoginFailure", mock.Anything, repository.LoginScopeUsername, "testuser", usernameThrottle.window)
+	repo.AssertNotCalled(t, "ConsumeUserToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, Username: "testuser"}, nil)
+	repo.On("SetTOTPSecret", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	enrollment, err := s.EnrollTOTP(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	repo.AssertCalled(t, "SetTOTPSecret", mock.Anything, int64(1), enrollment.Secret)
+}
+
+func TestEnrollTOTP_AlreadyEnabled(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	_, err := s.EnrollTOTP(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrTOTPAlreadyEnabled)
+	repo.AssertNotCalled(t, "SetTOTPSecret", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, TOTPSecret: testTOTPSecret}, nil)
+	repo.On("EnableTOTP", mock.Anything, int64(1), totp.Step(testNow), mock.Anything).Return(nil)
+	hasher := &mockHasher{}
+	hasher.On("HashPassword", mock.Anything).Return("hashed", nil)
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	s.now = func() time.Time { return testNow }
+
+	_, err := s.ConfirmTOTP(context.Background(), 1, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTOTPCode)
+
+	codes, err := s.ConfirmTOTP(context.Background(), 1, testTOTPCode(t, testNow))
+	assert.Nil(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	for _, code := range codes {
+		assert.Len(t, normalizeCode(code), recoveryCodeLength)
+		hasher.AssertCalled(t, "HashPassword", normalizeCode(code))
+	}
+	repo.AssertNumberOfCalls(t, "EnableTOTP", 1)
+}
+
+func TestDisableTOTP_WithRecoveryCode(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+	repo.On("GetRecoveryCodes", mock.Anything, int64(1)).Return([]repository.RecoveryCode{{ID: 4, Hash: "h4"}, {ID: 5, Hash: "h5"}}, nil)
+	repo.On("UseRecoveryCode", mock.Anything, int64(5)).Return(true, nil)
+	repo.On("DisableTOTP", mock.Anything, int64(1)).Return(nil)
+	hasher := &mockHasher{}
+	hasher.On("VerifyPassword", "h4", "abcdefghij").Return(fmt.Errorf("mismatch"))
+	hasher.On("VerifyPassword", "h5", "abcdefghij").Return(nil)
+
+	s := NewService(repo, hasher, nil, nil, "", nil)
+	err := s.DisableTOTP(context.Background(), 1, "ABCDE-FGHIJ")
+
+	assert.Nil(t, err)
+	repo.AssertCalled(t, "UseRecoveryCode", mock.Anything, int64(5))
+	repo.AssertCalled(t, "DisableTOTP", mock.Anything, int64(1))
+}
+
+var testIdentity = &oidc.Identity{
+	Issuer:            "https://accounts.example.com",
+	Subject:           "sub-1",
+	Email:             "jane@example.com",
+	EmailVerified:     true,
+	PreferredUsername: "Jane.Doe",
+}
+
+// oidcLogin sets up a pending "example" login whose code exchange yields identity.
+func oidcLogin(repo *mockUserRepo, identity *oidc.Identity) map[string]OIDCProvider {
+	repo.On("ConsumeOIDCAuthRequest", mock.Anything, token.Hash("state")).
+		Return(repository.OIDCAuthRequest{Provider: "example", CodeVerifier: "verifier", Nonce: "nonce"}, nil)
+	repo.On("CreateAuthSession", mock.Anything, mock.Anything, mock.Anything, refreshTokenTTL).Return(int64(7), nil)
+	provider := &mockOIDCProvider{}
+	provider.On("Exchange", mock.Anything, "code", "verifier", "nonce").Return(identity, nil)
+	return map[string]OIDCProvider{"example": provider}
+}
+
+func oidcJwt() *mockJwtService {
+	jwtService := &mockJwtService{}
+	jwtService.On("GenerateJwt", mock.Anything, int64(7)).Return("access", nil)
+	return jwtService
+}
+
+var testOIDCCallback = &OIDCLoginParams{Provider: "example", State: "state", Code: "code"}
+
+func TestStartOIDCLogin(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("CreateOIDCAuthRequest", mock.Anything, mock.Anything, mock.Anything, oidcStateTTL).Return(nil)
+	provider := &mockOIDCProvider{}
+	provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://accounts.example.com/authorize", nil)
+
+	s := NewService(repo, nil, nil, nil, "", map[string]OIDCProvider{"example": provider})
+	start, err := s.StartOIDCLogin(context.Background(), "example")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://accounts.example.com/authorize", start.AuthorizationURL)
+	call := provider.Calls[0]
+	assert.Equal(t, start.State, call.Arguments.String(1))
+	req := repository.OIDCAuthRequest{Provider: "example", Nonce: call.Arguments.String(2), CodeVerifier: call.Arguments.String(3)}
+	repo.AssertCalled(t, "CreateOIDCAuthRequest", mock.Anything, token.Hash(start.State), req, oidcStateTTL)
+
+	_, err = s.StartOIDCLogin(context.Background(), "other")
+	assert.ErrorIs(t, err, ErrUnknownOIDCProvider)
+}
+
+func TestCompleteOIDCLogin_LinkedIdentity(t *testing.T) {
+	repo := &mockUserRepo{}
+	providers := oidcLogin(repo, testIdentity)
+	repo.On("GetUserForIdentity", mock.Anything, testIdentity.Issuer, testIdentity.Subject).Return(models.User{ID: 3}, nil)
+
+	s := NewService(repo, nil, oidcJwt(), nil, "", providers)
+	result, err := s.CompleteOIDCLogin(context.Background(), testOIDCCallback)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "access", result.Tokens.AccessToken)
+	repo.AssertCalled(t, "CreateAuthSession", mock.Anything, int64(3), mock.Anything, refreshTokenTTL)
+}
+
+func TestCompleteOIDCLogin_LinksVerifiedEmail(t *testing.T) {
+	repo := &mockUserRepo{}
+	providers := oidcLogin(repo, testIdentity)
+	repo.On("GetUserForIdentity", mock.Anything, mock.Anything, mock.Anything).Return(models.User{}, repository.ErrUserNotFound)
+	repo.On("GetUserForEmail", mock.Anything, "jane@example.com").Return(models.User{ID: 3, EmailVerified: true}, nil)
+	repo.On("LinkIdentity", mock.Anything, int64(3), testIdentity.Issuer, testIdentity.Subject, "jane@example.com").Return(nil)
+
+	s := NewService(repo, nil, oidcJwt(), nil, "", providers)
+	_, err := s.CompleteOIDCLogin(context.Background(), testOIDCCallback)
+
+	assert.Nil(t, err)
+	repo.AssertCalled(t, "LinkIdentity", mock.Anything, int64(3), testIdentity.Issuer, testIdentity.Subject, "jane@example.com")
+}
+
+func TestCompleteOIDCLogin_UnverifiedLocalAccount(t *testing.T) {
+	repo := &mockUserRepo{}
+	providers := oidcLogin(repo, testIdentity)
+	repo.On("GetUserForIdentity", mock.Anything, mock.Anything, mock.Anything).Return(models.User{}, repository.ErrUserNotFound)
+	repo.On("GetUserForEmail", mock.Anything, "jane@example.com").Return(models.User{ID: 3}, nil)
+
+	s := NewService(repo, nil, nil, nil, "", providers)
+	_, err := s.CompleteOIDCLogin(context.Background(), testOIDCCallback)
+
+	assert.ErrorIs(t, err, ErrEmailInUse)
+	repo.AssertNotCalled(t, "LinkIdentity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCompleteOIDCLogin_CreatesUser(t *testing.T) {
+	identity := *testIdentity
+	identity.EmailVerified = false
+	repo := &mockUserRepo{}
+	providers := oidcLogin(repo, &identity)
+	repo.On("GetUserForIdentity", mock.Anything, mock.Anything, mock.Anything).Return(models.User{}, repository.ErrUserNotFound)
+	repo.On("CreateUserWithIdentity", mock.Anything, mock.MatchedBy(func(p *repository.CreateIdentityUserParams) bool {
+		return p.Username == "jane.doe"
+	})).Return(models.User{}, repository.ErrUsernameTaken).Once()
+	repo.On("CreateUserWithIdentity", mock.Anything, mock.Anything).Return(models.User{ID: 9}, nil)
+
+	s := NewService(repo, nil, oidcJwt(), nil, "", providers)
+	_, err := s.CompleteOIDCLogin(context.Background(), testOIDCCallback)
+
+	assert.Nil(t, err)
+	repo.AssertNumberOfCalls(t, "CreateUserWithIdentity", 2)
+	created := repo.Calls[len(repo.Calls)-2].Arguments.Get(1).(*repository.CreateIdentityUserParams)
+	assert.Regexp(t, `^jane\.doe-\d{4}$`, created.Username)
+	// The unverified email is not stored or used for linking.
+	assert.Empty(t, created.Email)
+	repo.AssertNotCalled(t, "GetUserForEmail", mock.Anything, mock.Anything)
+}
+
+func TestCompleteOIDCLogin_ProviderMismatch(t *testing.T) {
+	repo := &mockUserRepo{}
+	providers := oidcLogin(repo, testIdentity)
+	providers["other"] = &mockOIDCProvider{}
+
+	s := NewService(repo, nil, nil, nil, "", providers)
+	_, err := s.CompleteOIDCLogin(context.Background(), &OIDCLoginParams{Provider: "other", State: "state", Code: "code"})
+
+	assert.ErrorIs(t, err, ErrInvalidOIDCState)
+}
+
+func TestCompleteOIDCLogin_ExchangeFailure(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("ConsumeOIDCAuthRequest", mock.Anything, mock.Anything).
+		Return(repository.OIDCAuthRequest{Provider: "example", CodeVerifier: "verifier", Nonce: "nonce"}, nil)
+	provider := &mockOIDCProvider{}
+	provider.On("Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, oidc.ErrInvalidIDToken)
+
+	s := NewService(repo, nil, nil, nil, "", map[string]OIDCProvider{"example": provider})
+	_, err := s.CompleteOIDCLogin(context.Background(), testOIDCCallback)
+
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestOIDCUsername(t *testing.T) {
+	assert.Equal(t, "jane.doe", oidcUsername(&oidc.Identity{PreferredUsername: "Jane.Doe"}))
+	assert.Equal(t, "j_smithtag", oidcUsername(&oidc.Identity{Email: "J_Smith+tag@example.com"}))
+	assert.Equal(t, "user", oidcUsername(&oidc.Identity{PreferredUsername: "日本"}))
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("CreateAccessToken", mock.Anything, mock.Anything).Return(models.AccessToken{ID: 5, Name: "export"}, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	created, err := s.CreateAccessToken(context.Background(), &CreateAccessTokenParams{
+		UserID: 1,
+		Name:   " export ",
+		Scopes: []string{"sessions:read", "exercises:read", "sessions:read"},
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, AccessTokenPrefix))
+	repo.AssertCalled(t, "CreateAccessToken", mock.Anything, &repository.CreateAccessTokenParams{
+		UserID:    1,
+		Name:      "export",
+		TokenHash: token.Hash(created.Token),
+		Scopes:    []string{"exercises:read", "sessions:read"},
+		TTL:       defaultAccessTokenTTL,
+	})
+}
+
+func TestCreateAccessToken_Invalid(t *testing.T) {
+	s := NewService(&mockUserRepo{}, nil, nil, nil, "", nil)
+	cases := map[*CreateAccessTokenParams]error{
+		{Name: "", Scopes: []string{"sessions:read"}}:                                   ErrInvalidTokenName,
+		{Name: "x", Scopes: nil}:                                                        ErrInvalidScope,
+		{Name: "x", Scopes: []string{"users:write"}}:                                    ErrInvalidScope,
+		{Name: "x", Scopes: []string{"sessions:read"}, ExpiresIn: 400 * 24 * time.Hour}: ErrInvalidTokenExpiry,
+	}
+	for params, want := range cases {
+		_, err := s.CreateAccessToken(context.Background(), params)
+		assert.ErrorIs(t, err, want)
+	}
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("AuthenticateAccessToken", mock.Anything, token.Hash("wt_pat_good")).Return(int64(1), []string{"sessions:read"}, nil)
+	repo.On("AuthenticateAccessToken", mock.Anything, mock.Anything).Return(int64(0), nil, repository.ErrInvalidAccessToken)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	userID, scopes, ok, err := s.AuthenticateAccessToken(context.Background(), "wt_pat_good")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), userID)
+	assert.Equal(t, []string{"sessions:read"}, scopes)
+
+	_, _, ok, err = s.AuthenticateAccessToken(context.Background(), "wt_pat_revoked")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, ok, _ = s.AuthenticateAccessToken(context.Background(), "not-a-pat")
+	assert.False(t, ok)
+	repo.AssertNumberOfCalls(t, "AuthenticateAccessToken", 2)
+}
+
+func TestUpdatePreferences_ConvertsBodyweightToKilograms(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetPreferences", mock.Anything, int64(1)).Return(models.Preferences{WeightUnit: units.Pounds}, nil)
+	repo.On("UpdatePreferences", mock.Anything, mock.Anything).Return(models.Preferences{}, nil)
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1, Username: "lifter"}, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	bodyweight := 180.0
+	weekStart := "Sunday"
+	profile, err := s.UpdatePreferences(context.Background(), &UpdatePreferencesParams{
+		UserID:     1,
+		Bodyweight: &bodyweight,
+		WeekStart:  &weekStart,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "lifter", profile.Username)
+
+	wantBodyweight := 81.647
+	wantWeekStart := time.Sunday
+	repo.AssertCalled(t, "UpdatePreferences", mock.Anything, &repository.UpdatePreferencesParams{
+		UserID:     1,
+		Bodyweight: &wantBodyweight,
+		WeekStart:  &wantWeekStart,
+	})
+}
+
+func TestUpdatePreferences_UsesNewUnitForBodyweight(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("UpdatePreferences", mock.Anything, mock.Anything).Return(models.Preferences{}, nil)
+	repo.On("GetUserByID", mock.Anything, int64(1)).Return(models.User{ID: 1}, nil)
+	repo.On("GetPreferences", mock.Anything, int64(1)).Return(models.Preferences{WeightUnit: units.Kilograms}, nil)
+
+	s := NewService(repo, nil, nil, nil, "", nil)
+	unit := "lb"
+	bodyweight := 220.462
+	_, err := s.UpdatePreferences(context.Background(), &UpdatePreferencesParams{
+		UserID:     1,
+		WeightUnit: &unit,
+		Bodyweight: &bodyweight,
+	})
+	assert.Nil(t, err)
+
+	wantUnit := units.Pounds
+	wantBodyweight := 100.0
+	repo.AssertCalled(t, "UpdatePreferences", mock.Anything, &repository.UpdatePreferencesParams{
+		UserID:     1,
+		WeightUnit: &wantUnit,
+		Bodyweight: &wantBodyweight,
+	})
+}
+
+func TestUpdatePreferences_Invalid(t *testing.T) {
+	repo := &mockUserRepo{}
+	repo.On("GetPreferences", mock.Anything, int64(1)).Return(models.Preferences{WeightUnit: units.Kilograms}, nil)
+	s := NewService(repo, nil, nil, nil, "", nil)
+
+	stone, zero, heavy := "stone", 0.0, 600.0
+	rest, weekStart := 7200, "someday"
+	cases := map[*UpdatePreferencesParams]error{
+		{UserID: 1, WeightUnit: &stone}:        ErrInvalidWeightUnit,
+		{UserID: 1, Bodyweight: &zero}:         ErrInvalidBodyweight,
+		{UserID: 1, Bodyweight: &heavy}:        ErrInvalidBodyweight,
+		{UserID: 1, DefaultRestSeconds: &rest}: ErrInvalidRestTime,
+		{UserID: 1, WeekStart: &weekStart}:     ErrInvalidWeekStart,
+	}
+	for params, want := range cases {
+		_, err := s.UpdatePreferences(context.Background(), params)
+		assert.ErrorIs(t, err, want)
+	}
+	repo.AssertNotCalled(t, "UpdatePreferences", mock.Anything, mock.Anything)
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
+	"github.com/TBuckholz5/workouttracker/internal/util/token"
+)
+
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = repository.ErrInvalidRefreshToken
+	ErrRefreshTokenReused  = repository.ErrRefreshTokenReused
+)
+
+// startSession opens a new login session for the user and issues its first token pair.
+func (s *Service) startSession(reqContext context.Context, userID int64) (*TokenPair, error) {
+	refreshToken, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := s.repo.CreateAuthSession(reqContext, userID, token.Hash(refreshToken), refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(userID, sessionID, refreshToken)
+}
+
+func (s *Service) RefreshSession(reqContext context.Context, refreshToken string) (*TokenPair, error) {
+	next, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.repo.RotateRefreshToken(reqContext, token.Hash(refreshToken), token.Hash(next), refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(session.UserID, session.ID, next)
+}
+
+func (s *Service) Logout(reqContext context.Context, userID, sessionID int64) error {
+	return s.repo.RevokeAuthSession(reqContext, userID, sessionID)
+}
+
+// LogoutAll revokes every session of the user, logging out all devices.
+func (s *Service) LogoutAll(reqContext context.Context, userID int64) error {
+	return s.repo.RevokeAllAuthSessions(reqContext, userID)
+}
+
+func (s *Service) IsSessionActive(reqContext context.Context, sessionID int64) (bool, error) {
+	return s.repo.IsAuthSessionActive(reqContext, sessionID)
+}
+
+func (s *Service) tokenPair(userID, sessionID int64, refreshToken string) (*TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateJwt(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(jwt.AccessTokenTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/models"
+	"github.com/TBuckholz5/workouttracker/internal/domains/user/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/token"
+	"github.com/TBuckholz5/workouttracker/internal/util/totp"
+)
+
+const (
+	totpIssuer         = "Workout Tracker"
+	loginChallengeTTL  = 5 * time.Minute
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrInvalidTOTPCode = apperror.Validation("invalid_two_factor_code", "invalid two-factor code",
+		apperror.Field("code", "invalid", "is not a valid code"))
+	ErrTOTPAlreadyEnabled = apperror.Conflict("two_factor_enabled", "two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled    = apperror.Conflict("two_factor_not_enrolled", "two-factor authentication is not pending confirmation")
+	ErrTOTPNotEnabled     = apperror.Conflict("two_factor_not_enabled", "two-factor authentication is not enabled")
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// EnrollTOTP creates a new secret for the user. Two-factor authentication is
+// only enabled once ConfirmTOTP receives a code generated from it.
+func (s *Service) EnrollTOTP(reqContext context.Context, userID int64) (*TOTPEnrollment, error) {
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetTOTPSecret(reqContext, userID, secret); err != nil {
+		if errors.Is(err, repository.ErrTOTPState) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication and returns the user's
+// recovery codes. They are only stored hashed, so this is the one time they
+// can be shown.
+func (s *Service) ConfirmTOTP(reqContext context.Context, userID int64, code string) ([]string, error) {
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+	step, ok := totp.Validate(user.TOTPSecret, normalizeCode(code), s.now())
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(reqContext, userID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrTOTPState) {
+			return nil, ErrTOTPNotEnrolled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current
+// code or an unused recovery code.
+func (s *Service) DisableTOTP(reqContext context.Context, userID int64, code string) error {
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if err := s.verifySecondFactor(reqContext, user, code); err != nil {
+		return err
+	}
+	return s.repo.DisableTOTP(reqContext, userID)
+}
+
+// CompleteTwoFactorLogin trades the challenge issued by AuthenticateUser and
+// a second factor for a token pair. Wrong codes count towards the login
+// throttle, and the challenge stays usable until it expires or succeeds.
+func (s *Service) CompleteTwoFactorLogin(reqContext context.Context, params *TwoFactorLoginParams) (*TokenPair, error) {
+	challengeHash := token.Hash(params.ChallengeToken)
+	userID, err := s.repo.GetUserTokenUser(reqContext, repository.TokenPurposeLoginChallenge, challengeHash)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUserByID(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	login := &LoginParams{Username: user.Username, ClientIP: params.ClientIP}
+	if err := s.checkLoginLockout(reqContext, login); err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(reqContext, user, params.Code); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			if err := s.recordLoginFailure(reqContext, login); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if _, err := s.repo.ConsumeUserToken(reqContext, repository.TokenPurposeLoginChallenge, challengeHash); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ClearLoginFailures(reqContext, loginKey(user.Username)); err != nil {
+		return nil, err
+	}
+	return s.startSession(reqContext, user.ID)
+}
+
+func (s *Service) issueLoginChallenge(reqContext context.Context, userID int64) (*LoginResult, error) {
+	challenge, err := s.createUserToken(reqContext, userID, repository.TokenPurposeLoginChallenge, loginChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{ChallengeToken: challenge}, nil
+}
+
+// verifySecondFactor accepts either a TOTP code, each at most once, or an
+// unused recovery code.
+func (s *Service) verifySecondFactor(reqContext context.Context, user models.User, code string) error {
+	code = normalizeCode(code)
+	if len(code) == totp.Digits && isDigits(code) {
+		step, ok := totp.Validate(user.TOTPSecret, code, s.now())
+		if !ok {
+			return ErrInvalidTOTPCode
+		}
+		fresh, err := s.repo.UseTOTPStep(reqContext, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTOTPCode
+		}
+		return nil
+	}
+	return s.useRecoveryCode(reqContext, user.ID, code)
+}
+
+func (s *Service) useRecoveryCode(reqContext context.Context, userID int64, code string) error {
+	if len(code) != recoveryCodeLength {
+		return ErrInvalidTOTPCode
+	}
+	codes, err := s.repo.GetRecoveryCodes(reqContext, userID)
+	if err != nil {
+		return err
+	}
+	for _, recoveryCode := range codes {
+		if s.hasher.VerifyPassword(recoveryCode.Hash, code) != nil {
+			continue
+		}
+		used, err := s.repo.UseRecoveryCode(reqContext, recoveryCode.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTOTPCode
+		}
+		return nil
+	}
+	return ErrInvalidTOTPCode
+}
+
+// generateRecoveryCodes returns codes formatted for display along with the
+// hashes of their normalized form.
+func (s *Service) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := recoveryCodeEncoding.EncodeToString(b)[:recoveryCodeLength]
+		hashed, err := s.hasher.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashed)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeCode strips the separators users tend to type along with codes.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,46 @@ package v1
 
 import "github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
 
+// CreateWorkoutSessionRequest is a completed session, which unlike a started
+// one must contain at least one workout.
+type CreateWorkoutSessionRequest struct {
+	models.WorkoutSession
+	Workouts []models.Workout `json:"workouts" binding:"required"`
+}
+
 type CreateWorkoutSessionResponse struct {
 	Session models.WorkoutSession `json:"session"`
 }
+
+type GetWorkoutSessionResponse struct {
+	Session models.WorkoutSession `json:"session"`
+}
+
+type ListWorkoutSessionsResponse struct {
+	Sessions   []models.WorkoutSession `json:"sessions"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+type WorkoutSessionResponse struct {
+	Session models.WorkoutSession `json:"session"`
+}
+
+type UpdateWorkoutSessionRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Duration    *int    `json:"duration" binding:"min=0"`
+}
+
+type UpdateWorkoutSetRequest struct {
+	Reps    *int     `json:"reps" binding:"min=0"`
+	Weight  *float64 `json:"weight" binding:"min=0"`
+	SetType *string  `json:"set_type" binding:"oneof=normal dropset superset failure"`
+}
+
+type ReorderWorkoutSetsRequest struct {
+	SetIDs []int64 `json:"setIDs" binding:"required"`
+}
+
+type ListPersonalRecordsResponse struct {
+	Records []models.PersonalRecord `json:"records"`
+}
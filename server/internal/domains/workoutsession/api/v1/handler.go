@@ -3,38 +3,519 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/service"
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/auth"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/timeparse"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+	"github.com/TBuckholz5/workouttracker/internal/util/validate"
 )
 
 type Handler struct {
-	service service.WorkoutSessionService
+	service     service.WorkoutSessionService
+	weightUnits units.Resolver
 }
 
-func NewHandler(s service.WorkoutSessionService) *Handler {
-	return &Handler{service: s}
+func NewHandler(s service.WorkoutSessionService, weightUnits units.Resolver) *Handler {
+	return &Handler{service: s, weightUnits: weightUnits}
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var payload models.WorkoutSession
+	var payload CreateWorkoutSessionRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 	userID := r.Context().Value(auth.CtxKeyUserID)
 	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	newSession := payload.WorkoutSession
+	newSession.Workouts = payload.Workouts
+	newSession.UserID = userID.(int64)
+	unit, ok := h.weightUnit(w, r, newSession.UserID)
+	if !ok {
+		return
+	}
+	sessionToKilograms(&newSession, unit)
+	session, err := h.service.Create(r.Context(), &newSession)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	sessionFromKilograms(session, unit)
+	if err := json.NewEncoder(w).Encode(CreateWorkoutSessionResponse{
+		Session: *session,
+	}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
+
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	var payload models.WorkoutSession
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
 	payload.UserID = userID.(int64)
-	session, err := h.service.Create(r.Context(), &payload)
+	unit, ok := h.weightUnit(w, r, payload.UserID)
+	if !ok {
+		return
+	}
+	sessionToKilograms(&payload, unit)
+	session, err := h.service.Start(r.Context(), &payload)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) GetActive(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.GetActive(r.Context(), userID.(int64))
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) Finish(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
 	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.Finish(r.Context(), userID.(int64), sessionID)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+// ActiveSession adapts a handler that expects an {id} path value so it can be
+// mounted under /active, resolving the caller's in-progress session first.
+func (h *Handler) ActiveSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(auth.CtxKeyUserID)
+		if userID == nil {
+			apperror.Write(w, r, auth.ErrNoUserID)
+			return
+		}
+		session, err := h.service.GetActive(r.Context(), userID.(int64))
+		if err != nil {
+			apperror.Write(w, r, err)
+			return
+		}
+		r.SetPathValue("id", strconv.FormatInt(session.ID, 10))
+		next(w, r)
+	}
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.GetByID(r.Context(), userID.(int64), sessionID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	sessionFromKilograms(session, unit)
+	if err := json.NewEncoder(w).Encode(GetWorkoutSessionResponse{
+		Session: *session,
+	}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	if err := json.NewEncoder(w).Encode(CreateWorkoutSessionResponse{
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	queryParams := r.URL.Query()
+	params := service.ListSessionsParams{
+		UserID: userID.(int64),
+		Cursor: queryParams.Get("cursor"),
+		Limit:  10,
+	}
+	if val := queryParams.Get("limit"); val != "" {
+		parsedLimit, err := strconv.Atoi(val)
+		if err != nil || parsedLimit <= 0 {
+			apperror.Write(w, r, apperror.InvalidParameter("limit"))
+			return
+		}
+		params.Limit = min(parsedLimit, maxListLimit)
+	}
+	if val := queryParams.Get("from"); val != "" {
+		from, err := timeparse.Parse(val, false)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("from"))
+			return
+		}
+		params.From = &from
+	}
+	if val := queryParams.Get("to"); val != "" {
+		to, err := timeparse.Parse(val, true)
+		if err != nil {
+			apperror.Write(w, r, apperror.InvalidParameter("to"))
+			return
+		}
+		params.To = &to
+	}
+	unit, ok := h.weightUnit(w, r, params.UserID)
+	if !ok {
+		return
+	}
+	result, err := h.service.List(r.Context(), &params)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	for i := range result.Sessions {
+		sessionFromKilograms(&result.Sessions[i], unit)
+	}
+	if err := json.NewEncoder(w).Encode(ListWorkoutSessionsResponse{
+		Sessions:   result.Sessions,
+		NextCursor: result.NextCursor,
+	}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+const maxListLimit = 50
+
+func (h *Handler) UpdateSession(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	var payload UpdateWorkoutSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.UpdateSession(r.Context(), &service.UpdateSessionParams{
+		UserID:      userID.(int64),
+		SessionID:   sessionID,
+		Name:        payload.Name,
+		Description: payload.Description,
+		Duration:    payload.Duration,
+	})
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	if err := h.service.DeleteSession(r.Context(), userID.(int64), sessionID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) AddWorkout(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	var payload models.Workout
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	workoutToKilograms(&payload, unit)
+	session, err := h.service.AddWorkout(r.Context(), userID.(int64), sessionID, &payload)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) DeleteWorkout(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	workoutID, err := pathID(r, "workoutID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("workoutID"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.DeleteWorkout(r.Context(), userID.(int64), sessionID, workoutID)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) AddSet(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	workoutID, err := pathID(r, "workoutID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("workoutID"))
+		return
+	}
+	var payload models.WorkoutSet
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	payload.Weight = units.ToKilograms(payload.Weight, unit)
+	session, err := h.service.AddSet(r.Context(), userID.(int64), sessionID, workoutID, &payload)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) UpdateSet(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	workoutID, err := pathID(r, "workoutID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("workoutID"))
+		return
+	}
+	setID, err := pathID(r, "setID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("setID"))
+		return
+	}
+	var payload UpdateWorkoutSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	if payload.Weight != nil {
+		weight := units.ToKilograms(*payload.Weight, unit)
+		payload.Weight = &weight
+	}
+	session, err := h.service.UpdateSet(r.Context(), &service.UpdateSetParams{
+		UserID:    userID.(int64),
+		SessionID: sessionID,
+		WorkoutID: workoutID,
+		SetID:     setID,
+		Reps:      payload.Reps,
+		Weight:    payload.Weight,
+		SetType:   payload.SetType,
+	})
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) DeleteSet(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	workoutID, err := pathID(r, "workoutID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("workoutID"))
+		return
+	}
+	setID, err := pathID(r, "setID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("setID"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.DeleteSet(r.Context(), userID.(int64), sessionID, workoutID, setID)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) ReorderSets(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	sessionID, err := pathID(r, "id")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("id"))
+		return
+	}
+	workoutID, err := pathID(r, "workoutID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("workoutID"))
+		return
+	}
+	var payload ReorderWorkoutSetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		apperror.Write(w, r, apperror.ErrMalformedBody)
+		return
+	}
+	if err := validate.Struct(&payload); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	session, err := h.service.ReorderSets(r.Context(), userID.(int64), sessionID, workoutID, payload.SetIDs)
+	writeSessionResult(w, r, session, unit, err)
+}
+
+func (h *Handler) ListPersonalRecords(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(auth.CtxKeyUserID)
+	if userID == nil {
+		apperror.Write(w, r, auth.ErrNoUserID)
+		return
+	}
+	exerciseID, err := pathID(r, "exerciseID")
+	if err != nil {
+		apperror.Write(w, r, apperror.InvalidParameter("exerciseID"))
+		return
+	}
+	unit, ok := h.weightUnit(w, r, userID.(int64))
+	if !ok {
+		return
+	}
+	records, err := h.service.ListPersonalRecords(r.Context(), userID.(int64), exerciseID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	for i := range records {
+		recordFromKilograms(&records[i], unit)
+	}
+	if err := json.NewEncoder(w).Encode(ListPersonalRecordsResponse{Records: records}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(r.PathValue(name), 10, 64)
+}
+
+func writeSessionResult(w http.ResponseWriter, r *http.Request, session *models.WorkoutSession, unit units.WeightUnit, err error) {
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	sessionFromKilograms(session, unit)
+	if err := json.NewEncoder(w).Encode(WorkoutSessionResponse{
 		Session: *session,
 	}); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/units"
+)
+
+// weightUnit looks up the unit the user reads and writes weights in. On
+// failure it writes the error response and returns false.
+func (h *Handler) weightUnit(w http.ResponseWriter, r *http.Request, userID int64) (units.WeightUnit, bool) {
+	unit, err := h.weightUnits.WeightUnit(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return "", false
+	}
+	return unit, true
+}
+
+// Sets are stored in kilograms; requests and responses use the user's unit.
+
+func workoutToKilograms(workout *models.Workout, unit units.WeightUnit) {
+	for i := range workout.Sets {
+		workout.Sets[i].Weight = units.ToKilograms(workout.Sets[i].Weight, unit)
+	}
+}
+
+func sessionToKilograms(session *models.WorkoutSession, unit units.WeightUnit) {
+	for i := range session.Workouts {
+		workoutToKilograms(&session.Workouts[i], unit)
+	}
+}
+
+func sessionFromKilograms(session *models.WorkoutSession, unit units.WeightUnit) {
+	for i := range session.Workouts {
+		sets := session.Workouts[i].Sets
+		for j := range sets {
+			sets[j].Weight = units.FromKilograms(sets[j].Weight, unit)
+		}
+	}
+}
+
+// recordFromKilograms converts the weight of a record and, unless it counts
+// reps, its value.
+func recordFromKilograms(record *models.PersonalRecord, unit units.WeightUnit) {
+	record.Weight = units.FromKilograms(record.Weight, unit)
+	if record.RecordType != models.RecordTypeRepsAtWeight {
+		record.Value = units.FromKilograms(record.Value, unit)
+	}
+}
@@ -4,25 +4,47 @@ import "time"
 
 type WorkoutSet struct {
 	ID       int64   `json:"id,omitempty"`
-	Reps     int     `json:"reps"`
-	Weight   float64 `json:"weight"`
-	SetType  string  `json:"set_type"`
-	SetOrder int     `json:"set_order"`
+	Reps     int     `json:"reps" binding:"min=0"`
+	Weight   float64 `json:"weight" binding:"min=0"`
+	SetType  string  `json:"set_type" binding:"required,oneof=normal dropset superset failure"`
+	SetOrder int     `json:"set_order" binding:"min=0"`
+	// PersonalRecords lists the record types this set currently holds.
+	PersonalRecords []string `json:"personalRecords,omitempty"`
 }
 
 type Workout struct {
 	ID          int64        `json:"id,omitempty"`
-	ExerciseID  int64        `json:"exerciseID"`
+	ExerciseID  int64        `json:"exerciseID" binding:"required"`
 	Description string       `json:"description,omitempty"`
 	Sets        []WorkoutSet `json:"sets"`
 }
 
 type WorkoutSession struct {
-	ID          int64     `json:"id,omitempty"`
-	UserID      int64     `json:"userID,omitempty"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Duration    int       `json:"duration,omitempty"`
-	Workouts    []Workout `json:"workouts"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID          int64      `json:"id,omitempty"`
+	UserID      int64      `json:"userID,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Duration    int        `json:"duration,omitempty" binding:"min=0"`
+	Workouts    []Workout  `json:"workouts"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+const (
+	RecordTypeMaxWeight    = "max_weight"
+	RecordTypeRepsAtWeight = "reps_at_weight"
+	RecordTypeEstimated1RM = "estimated_1rm"
+	RecordTypeSetVolume    = "set_volume"
+)
+
+type PersonalRecord struct {
+	ID           int64     `json:"id,omitempty"`
+	ExerciseID   int64     `json:"exerciseID"`
+	WorkoutSetID int64     `json:"workoutSetID"`
+	RecordType   string    `json:"recordType"`
+	Value        float64   `json:"value"`
+	Weight       float64   `json:"weight"`
+	Reps         int       `json:"reps"`
+	AchievedAt   time.Time `json:"achievedAt"`
 }
@@ -13,6 +13,8 @@ type WorkoutSet struct {
 	SetOrder  int       `db:"set_order"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+	// RecordTypes lists the personal records the set currently holds.
+	RecordTypes []string `db:"record_types"`
 }
 
 type Workout struct {
@@ -26,11 +28,26 @@ type Workout struct {
 }
 
 type WorkoutSession struct {
-	ID          int64     `db:"id"`
-	Name        string    `db:"name"`
-	UserID      int64     `db:"user_id"`
-	Description string    `db:"description"`
-	Duration    int       `db:"duration"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID          int64      `db:"id"`
+	Name        string     `db:"name"`
+	UserID      int64      `db:"user_id"`
+	Description string     `db:"description"`
+	Duration    int        `db:"duration"`
+	StartedAt   time.Time  `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+type PersonalRecord struct {
+	ID           int64     `db:"id"`
+	UserID       int64     `db:"user_id"`
+	ExerciseID   int64     `db:"exercise_id"`
+	WorkoutSetID int64     `db:"workout_set_id"`
+	RecordType   string    `db:"record_type"`
+	Value        float64   `db:"value"`
+	Weight       float64   `db:"weight"`
+	Reps         int       `db:"reps"`
+	AchievedAt   time.Time `db:"achieved_at"`
+	CreatedAt    time.Time `db:"created_at"`
 }
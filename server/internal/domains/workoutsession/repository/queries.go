@@ -1,8 +1,14 @@
 package repository
 
-const createSessionQuery = `INSERT INTO sessions (name, user_id, description, duration)
-	VALUES ($1, $2, $3, $4)
-	RETURNING id, name, user_id, description, duration, created_at, updated_at;`
+// createSessionQuery records an already completed session, back-dating
+// started_at by the client supplied duration in seconds.
+const createSessionQuery = `INSERT INTO sessions (name, user_id, description, duration, started_at, finished_at)
+	VALUES ($1, $2, $3, $4::int, NOW() - ($4::int * INTERVAL '1 second'), NOW())
+	RETURNING id, name, user_id, COALESCE(description, ''), COALESCE(duration, 0), started_at, finished_at, created_at, updated_at;`
+
+const startSessionQuery = `INSERT INTO sessions (name, user_id, description, duration, started_at)
+	VALUES ($1, $2, $3, 0, NOW())
+	RETURNING id, name, user_id, COALESCE(description, ''), COALESCE(duration, 0), started_at, finished_at, created_at, updated_at;`
 
 const createWorkoutQuery = `INSERT INTO workouts (exercise_id, description, session_id)
 	VALUES ($1, $2, $3)
@@ -11,3 +17,80 @@ const createWorkoutQuery = `INSERT INTO workouts (exercise_id, description, sess
 const createSetQuery = `INSERT INTO workout_sets (workout_id, reps, weight, set_type, set_order)
 	VALUES ($1, $2, $3, $4, $5)
 	RETURNING id, reps, weight, set_type, set_order, workout_id, created_at, updated_at;`
+
+const getSessionByIDQuery = `SELECT id, name, user_id, COALESCE(description, ''), COALESCE(duration, 0), started_at, finished_at, created_at, updated_at
+	FROM sessions
+	WHERE id = $1 AND user_id = $2;`
+
+const listSessionsQuery = `SELECT id, name, user_id, COALESCE(description, ''), COALESCE(duration, 0), started_at, finished_at, created_at, updated_at
+	FROM sessions
+	WHERE user_id = $1
+		AND finished_at IS NOT NULL
+		AND ($2::timestamp IS NULL OR created_at >= $2)
+		AND ($3::timestamp IS NULL OR created_at < $3)
+		AND ($4::timestamp IS NULL OR (created_at, id) < ($4, $5))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $6;`
+
+const getWorkoutsForSessionsQuery = `SELECT id, exercise_id, COALESCE(description, ''), session_id, created_at, updated_at
+	FROM workouts
+	WHERE session_id = ANY($1)
+	ORDER BY session_id, id;`
+
+const getSetsForWorkoutsQuery = `SELECT id, reps, COALESCE(weight, 0), set_type, set_order, workout_id, created_at, updated_at,
+		ARRAY(SELECT pr.record_type FROM personal_records pr WHERE pr.workout_set_id = workout_sets.id ORDER BY pr.id)
+	FROM workout_sets
+	WHERE workout_id = ANY($1)
+	ORDER BY workout_id, set_order, id;`
+
+// updateSessionQuery moves started_at along with a changed duration, like
+// createSessionQuery does, so that a later finishSessionQuery or any reader of
+// the timestamps agrees with it. Active sessions count back from now.
+const updateSessionQuery = `UPDATE sessions
+	SET name = COALESCE($3, name),
+		description = COALESCE($4, description),
+		duration = COALESCE($5::int, duration),
+		started_at = CASE WHEN $5::int IS NULL THEN started_at
+			ELSE COALESCE(finished_at, NOW()) - ($5::int * INTERVAL '1 second') END,
+		updated_at = NOW()
+	WHERE id = $1 AND user_id = $2;`
+
+const deleteSessionQuery = `DELETE FROM sessions WHERE id = $1 AND user_id = $2 RETURNING finished_at IS NOT NULL;`
+
+const touchSessionQuery = `UPDATE sessions SET updated_at = NOW() WHERE id = $1 AND user_id = $2 RETURNING finished_at IS NOT NULL;`
+
+const deleteWorkoutQuery = `DELETE FROM workouts WHERE id = $1 AND session_id = $2 RETURNING exercise_id;`
+
+const lockWorkoutQuery = `SELECT exercise_id FROM workouts WHERE id = $1 AND session_id = $2 FOR UPDATE;`
+
+const nextSetOrderQuery = `SELECT COALESCE(MAX(set_order), 0) + 1 FROM workout_sets WHERE workout_id = $1;`
+
+const setOrderTakenQuery = `SELECT EXISTS (SELECT 1 FROM workout_sets WHERE workout_id = $1 AND set_order = $2);`
+
+const updateSetQuery = `UPDATE workout_sets
+	SET reps = COALESCE($3, reps),
+		weight = COALESCE($4, weight),
+		set_type = COALESCE($5::set_type, set_type),
+		updated_at = NOW()
+	WHERE id = $1 AND workout_id = $2;`
+
+const deleteSetQuery = `DELETE FROM workout_sets WHERE id = $1 AND workout_id = $2;`
+
+const countSetsQuery = `SELECT COUNT(*) FROM workout_sets WHERE workout_id = $1;`
+
+const reorderSetsQuery = `UPDATE workout_sets AS ws
+	SET set_order = o.ord, updated_at = NOW()
+	FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, ord)
+	WHERE ws.id = o.id AND ws.workout_id = $1;`
+
+const getActiveSessionQuery = `SELECT id, name, user_id, COALESCE(description, ''), COALESCE(duration, 0), started_at, finished_at, created_at, updated_at
+	FROM sessions
+	WHERE user_id = $1 AND finished_at IS NULL;`
+
+const finishSessionQuery = `UPDATE sessions
+	SET finished_at = NOW(),
+		duration = EXTRACT(EPOCH FROM NOW() - started_at)::int,
+		updated_at = NOW()
+	WHERE id = $1 AND user_id = $2 AND finished_at IS NULL;`
+
+const sessionIsFinishedQuery = `SELECT finished_at IS NOT NULL FROM sessions WHERE id = $1 AND user_id = $2;`
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// lockPersonalRecordsQuery serializes rebuilds of the same user and exercise,
+// so concurrent edits can't interleave their deletes and inserts.
+const lockPersonalRecordsQuery = `SELECT pg_advisory_xact_lock(hashtextextended('personal_records:' || $1::text || ':' || $2::text, 0));`
+
+const getExerciseHistoryQuery = `SELECT s.id, s.created_at, s.finished_at, ws.id, ws.reps, COALESCE(ws.weight, 0)
+	FROM sessions s
+	JOIN workouts w ON w.session_id = s.id
+	JOIN workout_sets ws ON ws.workout_id = w.id
+	WHERE s.user_id = $1 AND w.exercise_id = $2 AND s.finished_at IS NOT NULL
+	ORDER BY s.finished_at, s.id, w.id, ws.set_order, ws.id;`
+
+const deletePersonalRecordsQuery = `DELETE FROM personal_records WHERE user_id = $1 AND exercise_id = $2;`
+
+const createPersonalRecordQuery = `INSERT INTO personal_records (user_id, exercise_id, workout_set_id, record_type, value, weight, reps, achieved_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+const listPersonalRecordsQuery = `SELECT id, user_id, exercise_id, workout_set_id, record_type, value, weight, reps, achieved_at, created_at
+	FROM personal_records
+	WHERE user_id = $1 AND exercise_id = $2
+	ORDER BY achieved_at, id;`
+
+const getSessionExercisesQuery = `SELECT DISTINCT exercise_id FROM workouts WHERE session_id = $1;`
+
+// RebuildPersonalRecords replaces the records of an exercise with ones
+// replayed from its whole history. Records depend on every earlier set, so
+// any change to a finished session's sets rebuilds them in its transaction.
+func RebuildPersonalRecords(ctx context.Context, tx pgx.Tx, userID int64, exerciseID int64) ([]*PersonalRecord, error) {
+	if _, err := tx.Exec(ctx, lockPersonalRecordsQuery, userID, exerciseID); err != nil {
+		return nil, fmt.Errorf("failed to lock personal records: %w", err)
+	}
+	sessions, err := getExerciseHistory(ctx, tx, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, deletePersonalRecordsQuery, userID, exerciseID); err != nil {
+		return nil, fmt.Errorf("failed to delete personal records: %w", err)
+	}
+	records := replayPersonalRecords(sessions)
+	for _, pr := range records {
+		_, err := tx.Exec(ctx, createPersonalRecordQuery,
+			pr.UserID,
+			pr.ExerciseID,
+			pr.WorkoutSetID,
+			pr.RecordType,
+			pr.Value,
+			pr.Weight,
+			pr.Reps,
+			pr.AchievedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create personal record: %w", err)
+		}
+	}
+	return records, nil
+}
+
+// rebuildSessionRecords rebuilds the records of every exercise in exerciseIDs.
+func rebuildSessionRecords(ctx context.Context, tx pgx.Tx, userID int64, exerciseIDs []int64) ([]*PersonalRecord, error) {
+	var records []*PersonalRecord
+	for _, exerciseID := range exerciseIDs {
+		rebuilt, err := RebuildPersonalRecords(ctx, tx, userID, exerciseID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rebuilt...)
+	}
+	return records, nil
+}
+
+func getExerciseHistory(ctx context.Context, tx pgx.Tx, userID int64, exerciseID int64) ([]*models.WorkoutSession, error) {
+	rows, err := tx.Query(ctx, getExerciseHistoryQuery, userID, exerciseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise history: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.WorkoutSession, 0)
+	var session *models.WorkoutSession
+	for rows.Next() {
+		var (
+			sessionID int64
+			current   models.WorkoutSession
+			set       models.WorkoutSet
+		)
+		if err := rows.Scan(&sessionID, &current.CreatedAt, &current.FinishedAt, &set.ID, &set.Reps, &set.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan exercise history row: %w", err)
+		}
+		if session == nil || session.ID != sessionID {
+			current.ID = sessionID
+			current.UserID = userID
+			current.Workouts = []models.Workout{{ExerciseID: exerciseID}}
+			session = &current
+			sessions = append(sessions, session)
+		}
+		session.Workouts[0].Sets = append(session.Workouts[0].Sets, set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get exercise history: %w", err)
+	}
+	return sessions, nil
+}
+
+func getSessionExercises(ctx context.Context, tx pgx.Tx, sessionID int64) ([]int64, error) {
+	rows, err := tx.Query(ctx, getSessionExercisesQuery, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session exercises: %w", err)
+	}
+	defer rows.Close()
+
+	exerciseIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session exercise row: %w", err)
+		}
+		exerciseIDs = append(exerciseIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get session exercises: %w", err)
+	}
+	return exerciseIDs, nil
+}
+
+func (r *Repository) ListPersonalRecords(ctx context.Context, userID int64, exerciseID int64) ([]*PersonalRecord, error) {
+	rows, err := r.pool.Query(ctx, listPersonalRecordsQuery, userID, exerciseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*PersonalRecord, 0)
+	for rows.Next() {
+		var pr PersonalRecord
+		err := rows.Scan(
+			&pr.ID,
+			&pr.UserID,
+			&pr.ExerciseID,
+			&pr.WorkoutSetID,
+			&pr.RecordType,
+			&pr.Value,
+			&pr.Weight,
+			&pr.Reps,
+			&pr.AchievedAt,
+			&pr.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan personal record row: %w", err)
+		}
+		records = append(records, &pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list personal records: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"slices"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/onerepmax"
+)
+
+// weightReps is one (weight, reps) pair logged for an exercise.
+type weightReps struct {
+	weight float64
+	reps   int
+}
+
+// exerciseBests tracks the best performances of one exercise seen so far.
+type exerciseBests struct {
+	maxWeight  float64
+	best1RM    float64
+	bestVolume float64
+	// frontier holds the (weight, reps) pairs no other logged pair matches or
+	// beats on both weight and reps, used to decide whether a set beats all
+	// previous sets at the same or a heavier weight. Dominated pairs could
+	// never decide that, so they are dropped to keep replays linear.
+	frontier []weightReps
+}
+
+func (b *exerciseBests) add(weight float64, reps int) {
+	b.maxWeight = max(b.maxWeight, weight)
+	b.best1RM = max(b.best1RM, onerepmax.Estimate(onerepmax.Epley, weight, reps))
+	b.bestVolume = max(b.bestVolume, weight*float64(reps))
+	if !b.isRepsRecord(weight, reps) {
+		return
+	}
+	b.frontier = slices.DeleteFunc(b.frontier, func(prev weightReps) bool {
+		return weight >= prev.weight && reps >= prev.reps
+	})
+	b.frontier = append(b.frontier, weightReps{weight: weight, reps: reps})
+}
+
+func (b *exerciseBests) isRepsRecord(weight float64, reps int) bool {
+	for _, prev := range b.frontier {
+		if prev.weight >= weight && prev.reps >= reps {
+			return false
+		}
+	}
+	return true
+}
+
+// detectPersonalRecords compares every set in session against the bests of
+// the sessions before it and returns the records it sets, adding its sets to
+// bests. For heaviest weight, estimated 1RM and set volume only the best set
+// per exercise in the session is recorded; reps-at-weight records are kept
+// for every set that improves on history.
+func detectPersonalRecords(session *models.WorkoutSession, bests map[int64]*exerciseBests) []*PersonalRecord {
+	achievedAt := session.CreatedAt
+	if session.FinishedAt != nil {
+		achievedAt = *session.FinishedAt
+	}
+
+	type recordKey struct {
+		exerciseID int64
+		recordType string
+	}
+	sessionBests := make(map[recordKey]*PersonalRecord)
+	var order []recordKey
+	var records []*PersonalRecord
+	newRecord := func(exerciseID int64, set models.WorkoutSet, recordType string, value float64) *PersonalRecord {
+		return &PersonalRecord{
+			UserID:       session.UserID,
+			ExerciseID:   exerciseID,
+			WorkoutSetID: set.ID,
+			RecordType:   recordType,
+			Value:        value,
+			Weight:       set.Weight,
+			Reps:         set.Reps,
+			AchievedAt:   achievedAt,
+		}
+	}
+	keepBest := func(exerciseID int64, set models.WorkoutSet, recordType string, value float64) {
+		key := recordKey{exerciseID, recordType}
+		if _, ok := sessionBests[key]; !ok {
+			order = append(order, key)
+		}
+		sessionBests[key] = newRecord(exerciseID, set, recordType, value)
+	}
+
+	for _, workout := range session.Workouts {
+		b := bests[workout.ExerciseID]
+		if b == nil {
+			b = &exerciseBests{}
+			bests[workout.ExerciseID] = b
+		}
+		for _, set := range workout.Sets {
+			if set.Reps <= 0 || set.Weight < 0 {
+				continue
+			}
+			if b.isRepsRecord(set.Weight, set.Reps) {
+				records = append(records, newRecord(workout.ExerciseID, set, models.RecordTypeRepsAtWeight, float64(set.Reps)))
+			}
+			if set.Weight > 0 {
+				if set.Weight > b.maxWeight {
+					keepBest(workout.ExerciseID, set, models.RecordTypeMaxWeight, set.Weight)
+				}
+				if oneRM := onerepmax.Estimate(onerepmax.Epley, set.Weight, set.Reps); oneRM > b.best1RM {
+					keepBest(workout.ExerciseID, set, models.RecordTypeEstimated1RM, oneRM)
+				}
+				if volume := set.Weight * float64(set.Reps); volume > b.bestVolume {
+					keepBest(workout.ExerciseID, set, models.RecordTypeSetVolume, volume)
+				}
+			}
+			b.add(set.Weight, set.Reps)
+		}
+	}
+	for _, key := range order {
+		records = append(records, sessionBests[key])
+	}
+	return records
+}
+
+// replayPersonalRecords derives the records held by the given sessions,
+// oldest first, by comparing each session against the ones before it.
+func replayPersonalRecords(sessions []*models.WorkoutSession) []*PersonalRecord {
+	bests := make(map[int64]*exerciseBests)
+	var records []*PersonalRecord
+	for _, session := range sessions {
+		records = append(records, detectPersonalRecords(session, bests)...)
+	}
+	return records
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// exerciseSession builds a finished session logging sets of exercise 1, as
+// getExerciseHistory returns them.
+func exerciseSession(id int64, sets ...models.WorkoutSet) *models.WorkoutSession {
+	return &models.WorkoutSession{
+		ID:       id,
+		UserID:   42,
+		Workouts: []models.Workout{{ExerciseID: 1, Sets: sets}},
+	}
+}
+
+func recordsBySet(records []*PersonalRecord) map[int64][]string {
+	bySet := map[int64][]string{}
+	for _, pr := range records {
+		bySet[pr.WorkoutSetID] = append(bySet[pr.WorkoutSetID], pr.RecordType)
+	}
+	return bySet
+}
+
+func TestReplayPersonalRecords_EditedSetLosesRecord(t *testing.T) {
+	first := exerciseSession(1, models.WorkoutSet{ID: 10, Reps: 5, Weight: 100})
+	second := exerciseSession(2, models.WorkoutSet{ID: 20, Reps: 3, Weight: 110})
+
+	before := recordsBySet(replayPersonalRecords([]*models.WorkoutSession{first, second}))
+	assert.ElementsMatch(t, []string{
+		models.RecordTypeRepsAtWeight,
+		models.RecordTypeMaxWeight,
+		models.RecordTypeEstimated1RM,
+	}, before[20])
+
+	second.Workouts[0].Sets[0].Weight = 90
+	after := recordsBySet(replayPersonalRecords([]*models.WorkoutSession{first, second}))
+
+	assert.Empty(t, after[20])
+	assert.ElementsMatch(t, []string{
+		models.RecordTypeRepsAtWeight,
+		models.RecordTypeMaxWeight,
+		models.RecordTypeEstimated1RM,
+		models.RecordTypeSetVolume,
+	}, after[10])
+}
+
+func TestReplayPersonalRecords_DeletedSetHandsRecordToNext(t *testing.T) {
+	first := exerciseSession(1, models.WorkoutSet{ID: 10, Reps: 5, Weight: 100})
+	second := exerciseSession(2, models.WorkoutSet{ID: 20, Reps: 5, Weight: 100})
+
+	before := recordsBySet(replayPersonalRecords([]*models.WorkoutSession{first, second}))
+	assert.Len(t, before[10], 4)
+	assert.Empty(t, before[20])
+
+	after := recordsBySet(replayPersonalRecords([]*models.WorkoutSession{second}))
+
+	assert.Empty(t, after[10])
+	assert.ElementsMatch(t, []string{
+		models.RecordTypeRepsAtWeight,
+		models.RecordTypeMaxWeight,
+		models.RecordTypeEstimated1RM,
+		models.RecordTypeSetVolume,
+	}, after[20])
+}
+
+func TestDetectPersonalRecords_FirstSessionKeepsBestPerType(t *testing.T) {
+	session := &models.WorkoutSession{
+		UserID: 42,
+		Workouts: []models.Workout{{
+			ExerciseID: 1,
+			Sets: []models.WorkoutSet{
+				{ID: 1, Reps: 10, Weight: 60},
+				{ID: 2, Reps: 5, Weight: 80},
+				{ID: 3, Reps: 0, Weight: 100},
+			},
+		}},
+	}
+
+	records := detectPersonalRecords(session, map[int64]*exerciseBests{})
+
+	byType := map[string][]int64{}
+	for _, pr := range records {
+		byType[pr.RecordType] = append(byType[pr.RecordType], pr.WorkoutSetID)
+	}
+	assert.Equal(t, []int64{1, 2}, byType[models.RecordTypeRepsAtWeight])
+	assert.Equal(t, []int64{2}, byType[models.RecordTypeMaxWeight])
+	assert.Equal(t, []int64{2}, byType[models.RecordTypeEstimated1RM])
+	assert.Equal(t, []int64{1}, byType[models.RecordTypeSetVolume])
+}
+
+func TestDetectPersonalRecords_DominatedByHeavierHistory(t *testing.T) {
+	session := &models.WorkoutSession{
+		UserID: 42,
+		Workouts: []models.Workout{{
+			ExerciseID: 1,
+			Sets:       []models.WorkoutSet{{ID: 1, Reps: 8, Weight: 90}},
+		}},
+	}
+	bests := map[int64]*exerciseBests{1: {}}
+	bests[1].add(100, 8)
+
+	records := detectPersonalRecords(session, bests)
+
+	assert.Empty(t, records)
+}
+
+func TestExerciseBests_KeepsOnlyTheFrontier(t *testing.T) {
+	b := &exerciseBests{}
+	b.add(100, 5)
+	b.add(90, 5)
+	b.add(100, 3)
+	b.add(80, 10)
+	b.add(100, 6)
+
+	assert.ElementsMatch(t, []weightReps{{weight: 100, reps: 6}, {weight: 80, reps: 10}}, b.frontier)
+	assert.False(t, b.isRepsRecord(100, 6))
+	assert.False(t, b.isRepsRecord(70, 10))
+	assert.True(t, b.isRepsRecord(90, 7))
+}
@@ -2,14 +2,82 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrSessionNotFound = apperror.NotFound("session_not_found", "workout session not found")
+	ErrWorkoutNotFound = apperror.NotFound("workout_not_found", "workout not found")
+	ErrSetNotFound     = apperror.NotFound("set_not_found", "workout set not found")
+	ErrInvalidSetOrder = apperror.Validation("invalid_set_order", "set order must list every set of the workout exactly once",
+		apperror.Field("setIDs", "invalid", "must list every set of the workout exactly once"))
+	ErrDuplicateSetOrder = apperror.Validation("duplicate_set_order", "another set of the workout already has this order",
+		apperror.Field("set_order", "taken", "is already used by another set of the workout"))
+	ErrActiveSessionExists = apperror.Conflict("active_session_exists", "user already has an active workout session")
+	ErrSessionNotActive    = apperror.Conflict("session_not_active", "workout session is already finished")
+)
+
+type ListSessionsParams struct {
+	UserID int64
+	From   *time.Time
+	To     *time.Time
+	// BeforeCreatedAt and BeforeID form the keyset cursor: only sessions strictly
+	// older than this (created_at, id) pair are returned.
+	BeforeCreatedAt *time.Time
+	BeforeID        int64
+	Limit           int
+}
+
+type UpdateSessionParams struct {
+	UserID      int64
+	SessionID   int64
+	Name        *string
+	Description *string
+	Duration    *int
+}
+
+type UpdateSetParams struct {
+	UserID    int64
+	SessionID int64
+	WorkoutID int64
+	SetID     int64
+	Reps      *int
+	Weight    *float64
+	SetType   *string
+}
+
 type WorkoutSessionRepository interface {
 	Create(ctx context.Context, session *models.WorkoutSession) (*WorkoutSession, []*Workout, []*WorkoutSet, error)
+	Start(ctx context.Context, session *models.WorkoutSession) (*WorkoutSession, []*Workout, []*WorkoutSet, error)
+	GetActive(ctx context.Context, userID int64) (*WorkoutSession, []*Workout, []*WorkoutSet, error)
+	Finish(ctx context.Context, userID int64, sessionID int64) error
+	GetByID(ctx context.Context, userID int64, sessionID int64) (*WorkoutSession, []*Workout, []*WorkoutSet, error)
+	List(ctx context.Context, params *ListSessionsParams) ([]*WorkoutSession, []*Workout, []*WorkoutSet, error)
+	UpdateSession(ctx context.Context, params *UpdateSessionParams) error
+	DeleteSession(ctx context.Context, userID int64, sessionID int64) error
+	AddWorkout(ctx context.Context, userID int64, sessionID int64, workout *models.Workout) error
+	DeleteWorkout(ctx context.Context, userID int64, sessionID int64, workoutID int64) error
+	AddSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, set *models.WorkoutSet) error
+	UpdateSet(ctx context.Context, params *UpdateSetParams) error
+	DeleteSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, setID int64) error
+	ReorderSets(ctx context.Context, userID int64, sessionID int64, workoutID int64, setIDs []int64) error
+	ListPersonalRecords(ctx context.Context, userID int64, exerciseID int64) ([]*PersonalRecord, error)
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 type Repository struct {
@@ -31,26 +99,177 @@ func (r *Repository) Create(ctx context.Context, session *models.WorkoutSession)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var newSession WorkoutSession
-	err = tx.QueryRow(ctx, createSessionQuery, session.Name, session.UserID, session.Description, session.Duration).Scan(
-		&newSession.ID,
-		&newSession.Name,
-		&newSession.UserID,
-		&newSession.Description,
-		&newSession.Duration,
-		&newSession.CreatedAt,
-		&newSession.UpdatedAt,
-	)
+	newSession, err := scanSession(tx.QueryRow(ctx, createSessionQuery, session.Name, session.UserID, session.Description, session.Duration))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	newWorkouts := make([]*Workout, len(session.Workouts))
-	var newSets []*WorkoutSet
-	for i, w := range session.Workouts {
-		var workout Workout
+	newWorkouts, newSets, err := insertWorkouts(ctx, tx, newSession.ID, session.Workouts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	records, err := rebuildSessionRecords(ctx, tx, session.UserID, workoutExercises(newWorkouts))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	attachRecordTypes(newSets, records)
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return newSession, newWorkouts, newSets, nil
+}
+
+// Start opens an in-progress session. The partial unique index on
+// sessions(user_id) WHERE finished_at IS NULL enforces one per user.
+func (r *Repository) Start(ctx context.Context, session *models.WorkoutSession) (*WorkoutSession, []*Workout, []*WorkoutSet, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	newSession, err := scanSession(tx.QueryRow(ctx, startSessionQuery, session.Name, session.UserID, session.Description))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return nil, nil, nil, ErrActiveSessionExists
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	newWorkouts, newSets, err := insertWorkouts(ctx, tx, newSession.ID, session.Workouts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return newSession, newWorkouts, newSets, nil
+}
+
+func (r *Repository) GetActive(ctx context.Context, userID int64) (*WorkoutSession, []*Workout, []*WorkoutSet, error) {
+	session, err := scanSession(r.pool.QueryRow(ctx, getActiveSessionQuery, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get active session: %w", err)
+	}
+
+	workouts, sets, err := getWorkoutsAndSets(ctx, r.pool, []int64{session.ID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return session, workouts, sets, nil
+}
+
+// Finish closes an in-progress session and derives its duration from the
+// server-side start and finish timestamps. The session's sets now count
+// towards personal records, so those are rebuilt in the same transaction.
+func (r *Repository) Finish(ctx context.Context, userID int64, sessionID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, finishSessionQuery, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to finish session: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		exerciseIDs, err := getSessionExercises(ctx, tx, sessionID)
+		if err != nil {
+			return err
+		}
+		if _, err := rebuildSessionRecords(ctx, tx, userID, exerciseIDs); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	var finished bool
+	err = tx.QueryRow(ctx, sessionIsFinishedQuery, sessionID, userID).Scan(&finished)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finish session: %w", err)
+	}
+	return ErrSessionNotActive
+}
 
-		err := tx.QueryRow(ctx, createWorkoutQuery, w.ExerciseID, w.Description, newSession.ID).Scan(
+func (r *Repository) GetByID(ctx context.Context, userID int64, sessionID int64) (*WorkoutSession, []*Workout, []*WorkoutSet, error) {
+	session, err := scanSession(r.pool.QueryRow(ctx, getSessionByIDQuery, sessionID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	workouts, sets, err := getWorkoutsAndSets(ctx, r.pool, []int64{session.ID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return session, workouts, sets, nil
+}
+
+func (r *Repository) List(ctx context.Context, params *ListSessionsParams) ([]*WorkoutSession, []*Workout, []*WorkoutSet, error) {
+	rows, err := r.pool.Query(ctx, listSessionsQuery,
+		params.UserID,
+		params.From,
+		params.To,
+		params.BeforeCreatedAt,
+		params.BeforeID,
+		params.Limit,
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*WorkoutSession, 0)
+	sessionIDs := make([]int64, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		sessions = append(sessions, session)
+		sessionIDs = append(sessionIDs, session.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return sessions, []*Workout{}, []*WorkoutSet{}, nil
+	}
+
+	workouts, sets, err := getWorkoutsAndSets(ctx, r.pool, sessionIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sessions, workouts, sets, nil
+}
+
+// getWorkoutsAndSets loads every workout belonging to sessionIDs, ordered by
+// insertion, together with their sets ordered by set_order.
+func getWorkoutsAndSets(ctx context.Context, q querier, sessionIDs []int64) ([]*Workout, []*WorkoutSet, error) {
+	rows, err := q.Query(ctx, getWorkoutsForSessionsQuery, sessionIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get workouts: %w", err)
+	}
+	workouts := make([]*Workout, 0)
+	workoutIDs := make([]int64, 0)
+	for rows.Next() {
+		var workout Workout
+		err := rows.Scan(
 			&workout.ID,
 			&workout.ExerciseID,
 			&workout.Description,
@@ -59,31 +278,341 @@ func (r *Repository) Create(ctx context.Context, session *models.WorkoutSession)
 			&workout.UpdatedAt,
 		)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to create workout: %w", err)
-		}
-		newWorkouts[i] = &workout
-
-		for _, s := range w.Sets {
-			var set WorkoutSet
-			err := tx.QueryRow(ctx, createSetQuery, workout.ID, s.Reps, s.Weight, s.SetType, s.SetOrder).Scan(
-				&set.ID,
-				&set.Reps,
-				&set.Weight,
-				&set.SetType,
-				&set.SetOrder,
-				&set.WorkoutID,
-				&set.CreatedAt,
-				&set.UpdatedAt,
-			)
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("failed to create workout set: %w", err)
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan workout row: %w", err)
+		}
+		workouts = append(workouts, &workout)
+		workoutIDs = append(workoutIDs, workout.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get workouts: %w", err)
+	}
+
+	rows, err = q.Query(ctx, getSetsForWorkoutsQuery, workoutIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get workout sets: %w", err)
+	}
+	defer rows.Close()
+	sets := make([]*WorkoutSet, 0)
+	for rows.Next() {
+		var set WorkoutSet
+		err := rows.Scan(
+			&set.ID,
+			&set.Reps,
+			&set.Weight,
+			&set.SetType,
+			&set.SetOrder,
+			&set.WorkoutID,
+			&set.CreatedAt,
+			&set.UpdatedAt,
+			&set.RecordTypes,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan workout set row: %w", err)
+		}
+		sets = append(sets, &set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get workout sets: %w", err)
+	}
+	return workouts, sets, nil
+}
+
+func (r *Repository) UpdateSession(ctx context.Context, params *UpdateSessionParams) error {
+	tag, err := r.pool.Exec(ctx, updateSessionQuery,
+		params.SessionID,
+		params.UserID,
+		params.Name,
+		params.Description,
+		params.Duration,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// DeleteSession removes a session, rebuilding the records its sets held or
+// prevented.
+func (r *Repository) DeleteSession(ctx context.Context, userID int64, sessionID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	exerciseIDs, err := getSessionExercises(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+	var finished bool
+	err = tx.QueryRow(ctx, deleteSessionQuery, sessionID, userID).Scan(&finished)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if finished {
+		if _, err := rebuildSessionRecords(ctx, tx, userID, exerciseIDs); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) AddWorkout(ctx context.Context, userID int64, sessionID int64, workout *models.Workout) error {
+	return r.withSessionTx(ctx, userID, sessionID, func(tx pgx.Tx, finished bool) error {
+		if _, _, err := insertWorkout(ctx, tx, sessionID, workout); err != nil {
+			return err
+		}
+		if !finished {
+			return nil
+		}
+		_, err := RebuildPersonalRecords(ctx, tx, userID, workout.ExerciseID)
+		return err
+	})
+}
+
+func (r *Repository) DeleteWorkout(ctx context.Context, userID int64, sessionID int64, workoutID int64) error {
+	return r.withSessionTx(ctx, userID, sessionID, func(tx pgx.Tx, finished bool) error {
+		var exerciseID int64
+		err := tx.QueryRow(ctx, deleteWorkoutQuery, workoutID, sessionID).Scan(&exerciseID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWorkoutNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete workout: %w", err)
+		}
+		if !finished {
+			return nil
+		}
+		_, err = RebuildPersonalRecords(ctx, tx, userID, exerciseID)
+		return err
+	})
+}
+
+func (r *Repository) AddSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, set *models.WorkoutSet) error {
+	return r.withWorkoutTx(ctx, userID, sessionID, workoutID, func(tx pgx.Tx) error {
+		setOrder := set.SetOrder
+		if setOrder == 0 {
+			if err := tx.QueryRow(ctx, nextSetOrderQuery, workoutID).Scan(&setOrder); err != nil {
+				return fmt.Errorf("failed to compute set order: %w", err)
+			}
+		} else {
+			var taken bool
+			if err := tx.QueryRow(ctx, setOrderTakenQuery, workoutID, setOrder).Scan(&taken); err != nil {
+				return fmt.Errorf("failed to check set order: %w", err)
+			}
+			if taken {
+				return ErrDuplicateSetOrder
 			}
-			newSets = append(newSets, &set)
 		}
+		if _, err := tx.Exec(ctx, createSetQuery, workoutID, set.Reps, set.Weight, set.SetType, setOrder); err != nil {
+			return fmt.Errorf("failed to create workout set: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) UpdateSet(ctx context.Context, params *UpdateSetParams) error {
+	return r.withWorkoutTx(ctx, params.UserID, params.SessionID, params.WorkoutID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, updateSetQuery,
+			params.SetID,
+			params.WorkoutID,
+			params.Reps,
+			params.Weight,
+			params.SetType,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update workout set: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrSetNotFound
+		}
+		return nil
+	})
+}
+
+func (r *Repository) DeleteSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, setID int64) error {
+	return r.withWorkoutTx(ctx, userID, sessionID, workoutID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, deleteSetQuery, setID, workoutID)
+		if err != nil {
+			return fmt.Errorf("failed to delete workout set: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrSetNotFound
+		}
+		return nil
+	})
+}
+
+func (r *Repository) ReorderSets(ctx context.Context, userID int64, sessionID int64, workoutID int64, setIDs []int64) error {
+	return r.withWorkoutTx(ctx, userID, sessionID, workoutID, func(tx pgx.Tx) error {
+		var count int
+		if err := tx.QueryRow(ctx, countSetsQuery, workoutID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count workout sets: %w", err)
+		}
+		if count != len(setIDs) {
+			return ErrInvalidSetOrder
+		}
+		tag, err := tx.Exec(ctx, reorderSetsQuery, workoutID, setIDs)
+		if err != nil {
+			return fmt.Errorf("failed to reorder workout sets: %w", err)
+		}
+		if tag.RowsAffected() != int64(len(setIDs)) {
+			return ErrInvalidSetOrder
+		}
+		return nil
+	})
+}
+
+// withSessionTx runs fn in a transaction after confirming that userID owns
+// sessionID. The session row is locked and its updated_at bumped. fn is told
+// whether the session is finished, i.e. whether its sets count towards
+// personal records.
+func (r *Repository) withSessionTx(ctx context.Context, userID int64, sessionID int64, fn func(tx pgx.Tx, finished bool) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
+	var finished bool
+	err = tx.QueryRow(ctx, touchSessionQuery, sessionID, userID).Scan(&finished)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock session: %w", err)
+	}
+	if err := fn(tx, finished); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withWorkoutTx is withSessionTx with the additional guarantee that workoutID
+// belongs to sessionID. Once fn changed the sets of a finished session, the
+// records of the workout's exercise are rebuilt.
+func (r *Repository) withWorkoutTx(ctx context.Context, userID int64, sessionID int64, workoutID int64, fn func(tx pgx.Tx) error) error {
+	return r.withSessionTx(ctx, userID, sessionID, func(tx pgx.Tx, finished bool) error {
+		var exerciseID int64
+		err := tx.QueryRow(ctx, lockWorkoutQuery, workoutID, sessionID).Scan(&exerciseID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWorkoutNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock workout: %w", err)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if !finished {
+			return nil
+		}
+		_, err = RebuildPersonalRecords(ctx, tx, userID, exerciseID)
+		return err
+	})
+}
+
+func workoutExercises(workouts []*Workout) []int64 {
+	exerciseIDs := make([]int64, 0, len(workouts))
+	for _, workout := range workouts {
+		if !slices.Contains(exerciseIDs, workout.ExerciseID) {
+			exerciseIDs = append(exerciseIDs, workout.ExerciseID)
+		}
+	}
+	return exerciseIDs
+}
+
+// attachRecordTypes flags newly inserted sets with the records they hold.
+func attachRecordTypes(sets []*WorkoutSet, records []*PersonalRecord) {
+	bySet := make(map[int64][]string)
+	for _, pr := range records {
+		bySet[pr.WorkoutSetID] = append(bySet[pr.WorkoutSetID], pr.RecordType)
+	}
+	for _, set := range sets {
+		set.RecordTypes = bySet[set.ID]
+	}
+}
+
+func insertWorkouts(ctx context.Context, q querier, sessionID int64, workouts []models.Workout) ([]*Workout, []*WorkoutSet, error) {
+	newWorkouts := make([]*Workout, len(workouts))
+	var newSets []*WorkoutSet
+	for i, w := range workouts {
+		workout, sets, err := insertWorkout(ctx, q, sessionID, &w)
+		if err != nil {
+			return nil, nil, err
+		}
+		newWorkouts[i] = workout
+		newSets = append(newSets, sets...)
+	}
+	return newWorkouts, newSets, nil
+}
+
+func insertWorkout(ctx context.Context, q querier, sessionID int64, w *models.Workout) (*Workout, []*WorkoutSet, error) {
+	var workout Workout
+	err := q.QueryRow(ctx, createWorkoutQuery, w.ExerciseID, w.Description, sessionID).Scan(
+		&workout.ID,
+		&workout.ExerciseID,
+		&workout.Description,
+		&workout.SessionId,
+		&workout.CreatedAt,
+		&workout.UpdatedAt,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create workout: %w", err)
+	}
+
+	sets := make([]*WorkoutSet, 0, len(w.Sets))
+	for _, s := range w.Sets {
+		var set WorkoutSet
+		err := q.QueryRow(ctx, createSetQuery, workout.ID, s.Reps, s.Weight, s.SetType, s.SetOrder).Scan(
+			&set.ID,
+			&set.Reps,
+			&set.Weight,
+			&set.SetType,
+			&set.SetOrder,
+			&set.WorkoutID,
+			&set.CreatedAt,
+			&set.UpdatedAt,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create workout set: %w", err)
+		}
+		sets = append(sets, &set)
+	}
+	return &workout, sets, nil
+}
+
+const uniqueViolationCode = "23505"
+
+func scanSession(row pgx.Row) (*WorkoutSession, error) {
+	var session WorkoutSession
+	err := row.Scan(
+		&session.ID,
+		&session.Name,
+		&session.UserID,
+		&session.Description,
+		&session.Duration,
+		&session.StartedAt,
+		&session.FinishedAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
 	}
-	return &newSession, newWorkouts, newSets, nil
+	return &session, nil
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+)
+
+type ListSessionsParams struct {
+	UserID int64
+	From   *time.Time
+	To     *time.Time
+	Cursor string
+	Limit  int
+}
+
+type ListSessionsResult struct {
+	Sessions   []models.WorkoutSession
+	NextCursor string
+}
+
+type UpdateSessionParams struct {
+	UserID      int64
+	SessionID   int64
+	Name        *string
+	Description *string
+	Duration    *int
+}
+
+type UpdateSetParams struct {
+	UserID    int64
+	SessionID int64
+	WorkoutID int64
+	SetID     int64
+	Reps      *int
+	Weight    *float64
+	SetType   *string
+}
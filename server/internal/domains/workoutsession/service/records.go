@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+)
+
+func (s *Service) ListPersonalRecords(reqContext context.Context, userID int64, exerciseID int64) ([]models.PersonalRecord, error) {
+	repositoryRecords, err := s.repo.ListPersonalRecords(reqContext, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]models.PersonalRecord, 0, len(repositoryRecords))
+	for _, pr := range repositoryRecords {
+		records = append(records, models.PersonalRecord{
+			ID:           pr.ID,
+			ExerciseID:   pr.ExerciseID,
+			WorkoutSetID: pr.WorkoutSetID,
+			RecordType:   pr.RecordType,
+			Value:        pr.Value,
+			Weight:       pr.Weight,
+			Reps:         pr.Reps,
+			AchievedAt:   pr.AchievedAt,
+		})
+	}
+	return records, nil
+}
@@ -3,29 +3,229 @@ package service
 import (
 	"context"
 
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/TBuckholz5/workouttracker/internal/util/metrics"
+)
+
+var (
+	ErrSessionNotFound     = repository.ErrSessionNotFound
+	ErrWorkoutNotFound     = repository.ErrWorkoutNotFound
+	ErrSetNotFound         = repository.ErrSetNotFound
+	ErrInvalidSetOrder     = repository.ErrInvalidSetOrder
+	ErrActiveSessionExists = repository.ErrActiveSessionExists
+	ErrSessionNotActive    = repository.ErrSessionNotActive
+	ErrInvalidCursor       = apperror.Validation("invalid_cursor", "invalid pagination cursor",
+		apperror.Field("cursor", "invalid", "is not a cursor from a previous page"))
 )
 
 type WorkoutSessionService interface {
 	Create(reqContext context.Context, session *models.WorkoutSession) (*models.WorkoutSession, error)
+	Start(reqContext context.Context, session *models.WorkoutSession) (*models.WorkoutSession, error)
+	GetActive(reqContext context.Context, userID int64) (*models.WorkoutSession, error)
+	Finish(reqContext context.Context, userID int64, sessionID int64) (*models.WorkoutSession, error)
+	ListPersonalRecords(reqContext context.Context, userID int64, exerciseID int64) ([]models.PersonalRecord, error)
+	GetByID(reqContext context.Context, userID int64, sessionID int64) (*models.WorkoutSession, error)
+	List(reqContext context.Context, params *ListSessionsParams) (*ListSessionsResult, error)
+	UpdateSession(reqContext context.Context, params *UpdateSessionParams) (*models.WorkoutSession, error)
+	DeleteSession(reqContext context.Context, userID int64, sessionID int64) error
+	AddWorkout(reqContext context.Context, userID int64, sessionID int64, workout *models.Workout) (*models.WorkoutSession, error)
+	DeleteWorkout(reqContext context.Context, userID int64, sessionID int64, workoutID int64) (*models.WorkoutSession, error)
+	AddSet(reqContext context.Context, userID int64, sessionID int64, workoutID int64, set *models.WorkoutSet) (*models.WorkoutSession, error)
+	UpdateSet(reqContext context.Context, params *UpdateSetParams) (*models.WorkoutSession, error)
+	DeleteSet(reqContext context.Context, userID int64, sessionID int64, workoutID int64, setID int64) (*models.WorkoutSession, error)
+	ReorderSets(reqContext context.Context, userID int64, sessionID int64, workoutID int64, setIDs []int64) (*models.WorkoutSession, error)
 }
 
 type Service struct {
-	repo repository.WorkoutSessionRepository
+	repo      repository.WorkoutSessionRepository
+	exercises policy.ExercisePolicy
 }
 
-func NewService(r repository.WorkoutSessionRepository) *Service {
+func NewService(r repository.WorkoutSessionRepository, exercises policy.ExercisePolicy) *Service {
 	return &Service{
-		repo: r,
+		repo:      r,
+		exercises: exercises,
 	}
 }
 
 func (s *Service) Create(reqContext context.Context, session *models.WorkoutSession) (*models.WorkoutSession, error) {
+	if err := s.checkWorkouts(reqContext, session.UserID, session.Workouts, sessionWorkoutPath); err != nil {
+		return nil, err
+	}
 	repositorySession, repositoryWorkouts, repositorySets, err := s.repo.Create(reqContext, session)
 	if err != nil {
 		return nil, err
 	}
 	serviceSession := repositoryToModels(repositorySession, repositoryWorkouts, repositorySets)
+	countSession(serviceSession.Workouts)
+	return serviceSession, nil
+}
+
+func (s *Service) Start(reqContext context.Context, session *models.WorkoutSession) (*models.WorkoutSession, error) {
+	if err := s.checkWorkouts(reqContext, session.UserID, session.Workouts, sessionWorkoutPath); err != nil {
+		return nil, err
+	}
+	repositorySession, repositoryWorkouts, repositorySets, err := s.repo.Start(reqContext, session)
+	if err != nil {
+		return nil, err
+	}
+	serviceSession := repositoryToModels(repositorySession, repositoryWorkouts, repositorySets)
+	countSession(serviceSession.Workouts)
 	return serviceSession, nil
 }
+
+func (s *Service) GetActive(reqContext context.Context, userID int64) (*models.WorkoutSession, error) {
+	repositorySession, repositoryWorkouts, repositorySets, err := s.repo.GetActive(reqContext, userID)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryToModels(repositorySession, repositoryWorkouts, repositorySets), nil
+}
+
+func (s *Service) Finish(reqContext context.Context, userID int64, sessionID int64) (*models.WorkoutSession, error) {
+	if err := s.repo.Finish(reqContext, userID, sessionID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+func (s *Service) GetByID(reqContext context.Context, userID int64, sessionID int64) (*models.WorkoutSession, error) {
+	repositorySession, repositoryWorkouts, repositorySets, err := s.repo.GetByID(reqContext, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryToModels(repositorySession, repositoryWorkouts, repositorySets), nil
+}
+
+func (s *Service) List(reqContext context.Context, params *ListSessionsParams) (*ListSessionsResult, error) {
+	repoParams := &repository.ListSessionsParams{
+		UserID: params.UserID,
+		From:   params.From,
+		To:     params.To,
+		// Fetch one extra row to know whether another page exists.
+		Limit: params.Limit + 1,
+	}
+	if params.Cursor != "" {
+		createdAt, id, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		repoParams.BeforeCreatedAt = &createdAt
+		repoParams.BeforeID = id
+	}
+	repositorySessions, repositoryWorkouts, repositorySets, err := s.repo.List(reqContext, repoParams)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListSessionsResult{}
+	if len(repositorySessions) > params.Limit {
+		repositorySessions = repositorySessions[:params.Limit]
+		result.NextCursor = encodeCursor(repositorySessions[len(repositorySessions)-1])
+	}
+	result.Sessions = repositoryListToModels(repositorySessions, repositoryWorkouts, repositorySets)
+	return result, nil
+}
+
+// Mutations below return the full session as it looks after the change so
+// clients do not need a second round trip.
+
+func (s *Service) UpdateSession(reqContext context.Context, params *UpdateSessionParams) (*models.WorkoutSession, error) {
+	err := s.repo.UpdateSession(reqContext, &repository.UpdateSessionParams{
+		UserID:      params.UserID,
+		SessionID:   params.SessionID,
+		Name:        params.Name,
+		Description: params.Description,
+		Duration:    params.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, params.UserID, params.SessionID)
+}
+
+func (s *Service) DeleteSession(reqContext context.Context, userID int64, sessionID int64) error {
+	return s.repo.DeleteSession(reqContext, userID, sessionID)
+}
+
+func (s *Service) AddWorkout(reqContext context.Context, userID int64, sessionID int64, workout *models.Workout) (*models.WorkoutSession, error) {
+	if err := s.checkWorkouts(reqContext, userID, []models.Workout{*workout}, singleWorkoutPath); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddWorkout(reqContext, userID, sessionID, workout); err != nil {
+		return nil, err
+	}
+	metrics.SetsLogged.Add(float64(len(workout.Sets)))
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+func (s *Service) DeleteWorkout(reqContext context.Context, userID int64, sessionID int64, workoutID int64) (*models.WorkoutSession, error) {
+	if err := s.repo.DeleteWorkout(reqContext, userID, sessionID, workoutID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+func (s *Service) AddSet(reqContext context.Context, userID int64, sessionID int64, workoutID int64, set *models.WorkoutSet) (*models.WorkoutSession, error) {
+	if fieldErr, ok := checkWeight("weight", set.Weight); !ok {
+		return nil, validationError([]apperror.FieldError{fieldErr})
+	}
+	if err := s.repo.AddSet(reqContext, userID, sessionID, workoutID, set); err != nil {
+		return nil, err
+	}
+	metrics.SetsLogged.Inc()
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+func (s *Service) UpdateSet(reqContext context.Context, params *UpdateSetParams) (*models.WorkoutSession, error) {
+	if params.Weight != nil {
+		if fieldErr, ok := checkWeight("weight", *params.Weight); !ok {
+			return nil, validationError([]apperror.FieldError{fieldErr})
+		}
+	}
+	err := s.repo.UpdateSet(reqContext, &repository.UpdateSetParams{
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		WorkoutID: params.WorkoutID,
+		SetID:     params.SetID,
+		Reps:      params.Reps,
+		Weight:    params.Weight,
+		SetType:   params.SetType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, params.UserID, params.SessionID)
+}
+
+func (s *Service) DeleteSet(reqContext context.Context, userID int64, sessionID int64, workoutID int64, setID int64) (*models.WorkoutSession, error) {
+	if err := s.repo.DeleteSet(reqContext, userID, sessionID, workoutID, setID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+func (s *Service) ReorderSets(reqContext context.Context, userID int64, sessionID int64, workoutID int64, setIDs []int64) (*models.WorkoutSession, error) {
+	seen := make(map[int64]bool, len(setIDs))
+	for _, id := range setIDs {
+		if seen[id] {
+			return nil, ErrInvalidSetOrder
+		}
+		seen[id] = true
+	}
+	if err := s.repo.ReorderSets(reqContext, userID, sessionID, workoutID, setIDs); err != nil {
+		return nil, err
+	}
+	return s.GetByID(reqContext, userID, sessionID)
+}
+
+// countSession counts a new session and the sets it was saved with.
+func countSession(workouts []models.Workout) {
+	metrics.SessionsCreated.Inc()
+	for _, workout := range workouts {
+		metrics.SetsLogged.Add(float64(len(workout.Sets)))
+	}
+}
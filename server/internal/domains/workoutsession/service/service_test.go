@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/repository"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,9 +26,107 @@ func (m *MockWorkoutSessionRepository) Create(ctx context.Context, session *mode
 		args.Error(3)
 }
 
+func (m *MockWorkoutSessionRepository) Start(ctx context.Context, session *models.WorkoutSession) (*repository.WorkoutSession, []*repository.Workout, []*repository.WorkoutSet, error) {
+	args := m.Called(ctx, session)
+	return args.Get(0).(*repository.WorkoutSession),
+		args.Get(1).([]*repository.Workout),
+		args.Get(2).([]*repository.WorkoutSet),
+		args.Error(3)
+}
+
+func (m *MockWorkoutSessionRepository) GetActive(ctx context.Context, userID int64) (*repository.WorkoutSession, []*repository.Workout, []*repository.WorkoutSet, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*repository.WorkoutSession),
+		args.Get(1).([]*repository.Workout),
+		args.Get(2).([]*repository.WorkoutSet),
+		args.Error(3)
+}
+
+func (m *MockWorkoutSessionRepository) Finish(ctx context.Context, userID int64, sessionID int64) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) ListPersonalRecords(ctx context.Context, userID int64, exerciseID int64) ([]*repository.PersonalRecord, error) {
+	args := m.Called(ctx, userID, exerciseID)
+	return args.Get(0).([]*repository.PersonalRecord), args.Error(1)
+}
+
+type MockExercisePolicy struct {
+	mock.Mock
+}
+
+func (m *MockExercisePolicy) CanUse(ctx context.Context, userID int64, refs ...policy.Reference) error {
+	args := m.Called(ctx, userID, refs)
+	return args.Error(0)
+}
+
+// allowExercises is an exercise policy for tests that don't exercise it.
+type allowExercises struct{}
+
+func (allowExercises) CanUse(context.Context, int64, ...policy.Reference) error {
+	return nil
+}
+
+func (m *MockWorkoutSessionRepository) GetByID(ctx context.Context, userID int64, sessionID int64) (*repository.WorkoutSession, []*repository.Workout, []*repository.WorkoutSet, error) {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Get(0).(*repository.WorkoutSession),
+		args.Get(1).([]*repository.Workout),
+		args.Get(2).([]*repository.WorkoutSet),
+		args.Error(3)
+}
+
+func (m *MockWorkoutSessionRepository) List(ctx context.Context, params *repository.ListSessionsParams) ([]*repository.WorkoutSession, []*repository.Workout, []*repository.WorkoutSet, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]*repository.WorkoutSession),
+		args.Get(1).([]*repository.Workout),
+		args.Get(2).([]*repository.WorkoutSet),
+		args.Error(3)
+}
+
+func (m *MockWorkoutSessionRepository) UpdateSession(ctx context.Context, params *repository.UpdateSessionParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) DeleteSession(ctx context.Context, userID int64, sessionID int64) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) AddWorkout(ctx context.Context, userID int64, sessionID int64, workout *models.Workout) error {
+	args := m.Called(ctx, userID, sessionID, workout)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) DeleteWorkout(ctx context.Context, userID int64, sessionID int64, workoutID int64) error {
+	args := m.Called(ctx, userID, sessionID, workoutID)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) AddSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, set *models.WorkoutSet) error {
+	args := m.Called(ctx, userID, sessionID, workoutID, set)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) UpdateSet(ctx context.Context, params *repository.UpdateSetParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) DeleteSet(ctx context.Context, userID int64, sessionID int64, workoutID int64, setID int64) error {
+	args := m.Called(ctx, userID, sessionID, workoutID, setID)
+	return args.Error(0)
+}
+
+func (m *MockWorkoutSessionRepository) ReorderSets(ctx context.Context, userID int64, sessionID int64, workoutID int64, setIDs []int64) error {
+	args := m.Called(ctx, userID, sessionID, workoutID, setIDs)
+	return args.Error(0)
+}
+
 func TestService_Create_Success(t *testing.T) {
 	mockRepo := new(MockWorkoutSessionRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, allowExercises{})
 	ctx := context.Background()
 
 	inputSession := &models.WorkoutSession{
@@ -104,7 +205,7 @@ func TestService_Create_Success(t *testing.T) {
 
 func TestService_Create_RepositoryError(t *testing.T) {
 	mockRepo := new(MockWorkoutSessionRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, allowExercises{})
 	ctx := context.Background()
 
 	inputSession := &models.WorkoutSession{
@@ -130,7 +231,7 @@ func TestService_Create_RepositoryError(t *testing.T) {
 
 func TestService_Create_EmptyWorkouts(t *testing.T) {
 	mockRepo := new(MockWorkoutSessionRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, allowExercises{})
 	ctx := context.Background()
 
 	inputSession := &models.WorkoutSession{
@@ -164,3 +265,463 @@ func TestService_Create_EmptyWorkouts(t *testing.T) {
 	assert.Len(t, result.Workouts, 0)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestService_Create_RejectsInvalidSets(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	exercises := new(MockExercisePolicy)
+	service := NewService(mockRepo, exercises)
+	ctx := context.Background()
+
+	inputSession := &models.WorkoutSession{
+		Name:   "Leg day",
+		UserID: 42,
+		Workouts: []models.Workout{
+			{ExerciseID: 1, Sets: []models.WorkoutSet{
+				{Reps: 5, Weight: 100, SetType: "normal", SetOrder: 1},
+				{Reps: 5, Weight: 120000, SetType: "normal", SetOrder: 1},
+			}},
+		},
+	}
+
+	result, err := service.Create(ctx, inputSession)
+
+	assert.Nil(t, result)
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.KindValidation, appErr.Kind)
+	assert.Equal(t, []string{
+		"workouts[0].sets[1].weight",
+		"workouts[0].sets[1].set_order",
+	}, fieldNames(appErr.Fields))
+	exercises.AssertNotCalled(t, "CanUse", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_ChecksExercisesBeforeWriting(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	exercises := new(MockExercisePolicy)
+	service := NewService(mockRepo, exercises)
+	ctx := context.Background()
+
+	inputSession := &models.WorkoutSession{
+		Name:   "Leg day",
+		UserID: 42,
+		Workouts: []models.Workout{
+			{ExerciseID: 1, Sets: []models.WorkoutSet{{Reps: 5, Weight: 100, SetType: "normal", SetOrder: 1}}},
+			{ExerciseID: 7, Sets: []models.WorkoutSet{{Reps: 8, Weight: 60, SetType: "normal", SetOrder: 1}}},
+		},
+	}
+	exercises.On("CanUse", ctx, int64(42), []policy.Reference{
+		{Field: "workouts[0].exerciseID", ExerciseID: 1},
+		{Field: "workouts[1].exerciseID", ExerciseID: 7},
+	}).Return(policy.ErrExerciseForbidden)
+
+	result, err := service.Create(ctx, inputSession)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, policy.ErrExerciseForbidden)
+	exercises.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_AddWorkout_UnknownExercise(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	exercises := new(MockExercisePolicy)
+	service := NewService(mockRepo, exercises)
+	ctx := context.Background()
+
+	unknown := apperror.Unprocessable(policy.ErrUnknownExercise.Code, policy.ErrUnknownExercise.Message,
+		apperror.Field("exerciseID", "unknown", "does not exist"))
+	exercises.On("CanUse", ctx, int64(42), []policy.Reference{{Field: "exerciseID", ExerciseID: 99}}).Return(unknown)
+
+	result, err := service.AddWorkout(ctx, 42, 1, &models.Workout{ExerciseID: 99})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, policy.ErrUnknownExercise)
+	mockRepo.AssertNotCalled(t, "AddWorkout", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_UpdateSet_RejectsWeightOutOfRange(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+
+	weight := -5.0
+	result, err := service.UpdateSet(context.Background(), &UpdateSetParams{
+		UserID: 42, SessionID: 1, WorkoutID: 2, SetID: 3, Weight: &weight,
+	})
+
+	assert.Nil(t, result)
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []string{"weight"}, fieldNames(appErr.Fields))
+	mockRepo.AssertNotCalled(t, "UpdateSet", mock.Anything, mock.Anything)
+}
+
+func TestCheckWeight_Boundaries(t *testing.T) {
+	for _, tc := range []struct {
+		weight float64
+		ok     bool
+	}{
+		{0, true},
+		{maxSetWeight, true},
+		{99999.9994, true},
+		{99999.9996, false},
+		{100000, false},
+		{-0.001, false},
+	} {
+		_, ok := checkWeight("weight", tc.weight)
+		assert.Equal(t, tc.ok, ok, "weight %v", tc.weight)
+	}
+}
+
+func fieldNames(fields []apperror.FieldError) []string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Field)
+	}
+	return names
+}
+
+func TestService_GetByID_PreservesOrdering(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	repoSession := &repository.WorkoutSession{ID: 7, UserID: 42, Name: "Push A"}
+	repoWorkouts := []*repository.Workout{
+		{ID: 20, ExerciseID: 3, SessionId: 7},
+		{ID: 10, ExerciseID: 1, SessionId: 7},
+	}
+	repoSets := []*repository.WorkoutSet{
+		{ID: 3, WorkoutID: 20, Reps: 8, SetOrder: 2},
+		{ID: 1, WorkoutID: 20, Reps: 10, SetOrder: 1},
+		{ID: 2, WorkoutID: 10, Reps: 5, SetOrder: 1},
+	}
+	mockRepo.On("GetByID", ctx, int64(42), int64(7)).Return(repoSession, repoWorkouts, repoSets, nil)
+
+	result, err := service.GetByID(ctx, 42, 7)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Workouts, 2)
+	assert.Equal(t, int64(20), result.Workouts[0].ID)
+	assert.Equal(t, int64(10), result.Workouts[1].ID)
+	assert.Equal(t, 1, result.Workouts[0].Sets[0].SetOrder)
+	assert.Equal(t, 2, result.Workouts[0].Sets[1].SetOrder)
+	assert.Len(t, result.Workouts[1].Sets, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetByID_NotFound(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, int64(42), int64(7)).Return(
+		(*repository.WorkoutSession)(nil),
+		([]*repository.Workout)(nil),
+		([]*repository.WorkoutSet)(nil),
+		repository.ErrSessionNotFound)
+
+	result, err := service.GetByID(ctx, 42, 7)
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_List_ReturnsNextCursor(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	newest := time.Date(2025, 11, 30, 10, 0, 0, 0, time.UTC)
+	repoSessions := []*repository.WorkoutSession{
+		{ID: 3, UserID: 42, CreatedAt: newest},
+		{ID: 2, UserID: 42, CreatedAt: newest.Add(-time.Hour)},
+		{ID: 1, UserID: 42, CreatedAt: newest.Add(-2 * time.Hour)},
+	}
+	repoWorkouts := []*repository.Workout{
+		{ID: 30, SessionId: 3},
+		{ID: 10, SessionId: 1},
+	}
+	repoSets := []*repository.WorkoutSet{
+		{ID: 300, WorkoutID: 30, SetOrder: 1},
+		{ID: 100, WorkoutID: 10, SetOrder: 1},
+	}
+	mockRepo.On("List", ctx, mock.MatchedBy(func(p *repository.ListSessionsParams) bool {
+		return p.UserID == 42 && p.Limit == 3 && p.BeforeCreatedAt == nil
+	})).Return(repoSessions, repoWorkouts, repoSets, nil)
+
+	result, err := service.List(ctx, &ListSessionsParams{UserID: 42, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Sessions, 2)
+	assert.Equal(t, int64(3), result.Sessions[0].ID)
+	assert.Equal(t, int64(2), result.Sessions[1].ID)
+	assert.Len(t, result.Sessions[0].Workouts, 1)
+	assert.Len(t, result.Sessions[1].Workouts, 0)
+	assert.NotEmpty(t, result.NextCursor)
+
+	createdAt, id, err := decodeCursor(result.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), id)
+	assert.True(t, createdAt.Equal(newest.Add(-time.Hour)))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_List_UsesCursor(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	cursorTime := time.Date(2025, 11, 30, 9, 0, 0, 0, time.UTC)
+	cursor := encodeCursor(&repository.WorkoutSession{ID: 2, CreatedAt: cursorTime})
+	mockRepo.On("List", ctx, mock.MatchedBy(func(p *repository.ListSessionsParams) bool {
+		return p.BeforeID == 2 && p.BeforeCreatedAt != nil && p.BeforeCreatedAt.Equal(cursorTime)
+	})).Return([]*repository.WorkoutSession{}, []*repository.Workout{}, []*repository.WorkoutSet{}, nil)
+
+	result, err := service.List(ctx, &ListSessionsParams{UserID: 42, Cursor: cursor, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Sessions, 0)
+	assert.Empty(t, result.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_List_InvalidCursor(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+
+	result, err := service.List(context.Background(), &ListSessionsParams{UserID: 42, Cursor: "not-a-cursor", Limit: 2})
+
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestService_UpdateSet_ReturnsUpdatedSession(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	weight := 100.0
+	mockRepo.On("UpdateSet", ctx, mock.MatchedBy(func(p *repository.UpdateSetParams) bool {
+		return p.UserID == 42 && p.SessionID == 7 && p.WorkoutID == 10 && p.SetID == 1 && *p.Weight == weight && p.Reps == nil
+	})).Return(nil)
+	mockRepo.On("GetByID", ctx, int64(42), int64(7)).Return(
+		&repository.WorkoutSession{ID: 7, UserID: 42},
+		[]*repository.Workout{{ID: 10, SessionId: 7}},
+		[]*repository.WorkoutSet{{ID: 1, WorkoutID: 10, Weight: weight, SetOrder: 1}},
+		nil)
+
+	result, err := service.UpdateSet(ctx, &UpdateSetParams{UserID: 42, SessionID: 7, WorkoutID: 10, SetID: 1, Weight: &weight})
+
+	assert.NoError(t, err)
+	assert.Equal(t, weight, result.Workouts[0].Sets[0].Weight)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteWorkout_NotOwned(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("DeleteWorkout", ctx, int64(42), int64(7), int64(10)).Return(repository.ErrSessionNotFound)
+
+	result, err := service.DeleteWorkout(ctx, 42, 7, 10)
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_DeleteSession(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("DeleteSession", ctx, int64(42), int64(7)).Return(nil)
+
+	err := service.DeleteSession(ctx, 42, 7)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ReorderSets_RejectsDuplicates(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+
+	result, err := service.ReorderSets(context.Background(), 42, 7, 10, []int64{1, 2, 1})
+
+	assert.ErrorIs(t, err, ErrInvalidSetOrder)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "ReorderSets", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ReorderSets_Success(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("ReorderSets", ctx, int64(42), int64(7), int64(10), []int64{2, 1}).Return(nil)
+	mockRepo.On("GetByID", ctx, int64(42), int64(7)).Return(
+		&repository.WorkoutSession{ID: 7, UserID: 42},
+		[]*repository.Workout{{ID: 10, SessionId: 7}},
+		[]*repository.WorkoutSet{
+			{ID: 2, WorkoutID: 10, SetOrder: 1},
+			{ID: 1, WorkoutID: 10, SetOrder: 2},
+		},
+		nil)
+
+	result, err := service.ReorderSets(ctx, 42, 7, 10, []int64{2, 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Workouts[0].Sets[0].ID)
+	assert.Equal(t, int64(1), result.Workouts[0].Sets[1].ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Start_Success(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	startedAt := time.Date(2025, 12, 5, 18, 0, 0, 0, time.UTC)
+	inputSession := &models.WorkoutSession{Name: "Push A", UserID: 42}
+	mockRepo.On("Start", ctx, inputSession).Return(
+		&repository.WorkoutSession{ID: 9, UserID: 42, Name: "Push A", StartedAt: startedAt},
+		[]*repository.Workout{},
+		[]*repository.WorkoutSet{},
+		nil)
+
+	result, err := service.Start(ctx, inputSession)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), result.ID)
+	assert.Equal(t, startedAt, *result.StartedAt)
+	assert.Nil(t, result.FinishedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Start_ActiveSessionExists(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	inputSession := &models.WorkoutSession{Name: "Push A", UserID: 42}
+	mockRepo.On("Start", ctx, inputSession).Return(
+		(*repository.WorkoutSession)(nil),
+		([]*repository.Workout)(nil),
+		([]*repository.WorkoutSet)(nil),
+		repository.ErrActiveSessionExists)
+
+	result, err := service.Start(ctx, inputSession)
+
+	assert.ErrorIs(t, err, ErrActiveSessionExists)
+	assert.Nil(t, result)
+}
+
+func TestService_Finish_ReturnsFinishedSession(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	startedAt := time.Date(2025, 12, 5, 18, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(75 * time.Minute)
+	mockRepo.On("Finish", ctx, int64(42), int64(9)).Return(nil)
+	mockRepo.On("GetByID", ctx, int64(42), int64(9)).Return(
+		&repository.WorkoutSession{ID: 9, UserID: 42, Duration: 4500, StartedAt: startedAt, FinishedAt: &finishedAt},
+		[]*repository.Workout{},
+		[]*repository.WorkoutSet{},
+		nil)
+
+	result, err := service.Finish(ctx, 42, 9)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4500, result.Duration)
+	assert.Equal(t, finishedAt, *result.FinishedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Finish_AlreadyFinished(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	mockRepo.On("Finish", ctx, int64(42), int64(9)).Return(repository.ErrSessionNotActive)
+
+	result, err := service.Finish(ctx, 42, 9)
+
+	assert.ErrorIs(t, err, ErrSessionNotActive)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Create_FlagsPersonalRecords(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	inputSession := &models.WorkoutSession{Name: "Heavy day", UserID: 42}
+	mockRepo.On("Create", ctx, inputSession).Return(
+		&repository.WorkoutSession{ID: 5, UserID: 42},
+		[]*repository.Workout{{ID: 50, ExerciseID: 1, SessionId: 5}},
+		[]*repository.WorkoutSet{
+			{ID: 500, WorkoutID: 50, Reps: 5, Weight: 100, SetOrder: 1},
+			{ID: 501, WorkoutID: 50, Reps: 3, Weight: 110, SetOrder: 2, RecordTypes: []string{
+				models.RecordTypeRepsAtWeight,
+				models.RecordTypeMaxWeight,
+				models.RecordTypeEstimated1RM,
+			}},
+		},
+		nil)
+
+	result, err := service.Create(ctx, inputSession)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Workouts[0].Sets[0].PersonalRecords)
+	assert.ElementsMatch(t, []string{
+		models.RecordTypeRepsAtWeight,
+		models.RecordTypeMaxWeight,
+		models.RecordTypeEstimated1RM,
+	}, result.Workouts[0].Sets[1].PersonalRecords)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Create_PersonalRecordFailureFailsCreate(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	inputSession := &models.WorkoutSession{Name: "Heavy day", UserID: 42}
+	mockRepo.On("Create", ctx, inputSession).Return(
+		(*repository.WorkoutSession)(nil),
+		([]*repository.Workout)(nil),
+		([]*repository.WorkoutSet)(nil),
+		errors.New("failed to rebuild personal records"))
+
+	result, err := service.Create(ctx, inputSession)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestService_ListPersonalRecords(t *testing.T) {
+	mockRepo := new(MockWorkoutSessionRepository)
+	service := NewService(mockRepo, allowExercises{})
+	ctx := context.Background()
+
+	achievedAt := time.Date(2025, 12, 7, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("ListPersonalRecords", ctx, int64(42), int64(1)).Return([]*repository.PersonalRecord{
+		{ID: 1, UserID: 42, ExerciseID: 1, WorkoutSetID: 500, RecordType: models.RecordTypeMaxWeight, Value: 110, Weight: 110, Reps: 3, AchievedAt: achievedAt},
+	}, nil)
+
+	records, err := service.ListPersonalRecords(ctx, 42, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, models.RecordTypeMaxWeight, records[0].RecordType)
+	assert.Equal(t, achievedAt, records[0].AchievedAt)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,32 +1,50 @@
 package service
 
 import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
 	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/repository"
 )
 
 func repositoryToModels(session *repository.WorkoutSession, workouts []*repository.Workout, sets []*repository.WorkoutSet) *models.WorkoutSession {
 	workoutMap := make(map[int64]*models.Workout)
+	modelWorkouts := make([]*models.Workout, 0, len(workouts))
 	for _, workout := range workouts {
-		workoutMap[workout.ID] = &models.Workout{
+		modelWorkout := &models.Workout{
 			ID:          workout.ID,
 			ExerciseID:  workout.ExerciseID,
 			Description: workout.Description,
 			Sets:        []models.WorkoutSet{},
 		}
+		workoutMap[workout.ID] = modelWorkout
+		modelWorkouts = append(modelWorkouts, modelWorkout)
 	}
 	for _, set := range sets {
-		workoutMap[set.WorkoutID].Sets = append(workoutMap[set.WorkoutID].Sets, models.WorkoutSet{
-			ID:       set.ID,
-			Reps:     set.Reps,
-			Weight:   set.Weight,
-			SetType:  set.SetType,
-			SetOrder: set.SetOrder,
+		workout, ok := workoutMap[set.WorkoutID]
+		if !ok {
+			continue
+		}
+		workout.Sets = append(workout.Sets, models.WorkoutSet{
+			ID:              set.ID,
+			Reps:            set.Reps,
+			Weight:          set.Weight,
+			SetType:         set.SetType,
+			SetOrder:        set.SetOrder,
+			PersonalRecords: set.RecordTypes,
 		})
 	}
-	var modelWorkouts []models.Workout
-	for _, workout := range workoutMap {
-		modelWorkouts = append(modelWorkouts, *workout)
+	var sessionWorkouts []models.Workout
+	for _, workout := range modelWorkouts {
+		sort.SliceStable(workout.Sets, func(i, j int) bool {
+			return workout.Sets[i].SetOrder < workout.Sets[j].SetOrder
+		})
+		sessionWorkouts = append(sessionWorkouts, *workout)
 	}
 	modelSession := &models.WorkoutSession{
 		ID:          session.ID,
@@ -34,8 +52,59 @@ func repositoryToModels(session *repository.WorkoutSession, workouts []*reposito
 		Name:        session.Name,
 		Description: session.Description,
 		Duration:    session.Duration,
-		Workouts:    modelWorkouts,
+		Workouts:    sessionWorkouts,
+		FinishedAt:  session.FinishedAt,
 		CreatedAt:   session.CreatedAt,
 	}
+	if !session.StartedAt.IsZero() {
+		modelSession.StartedAt = &session.StartedAt
+	}
 	return modelSession
 }
+
+// repositoryListToModels groups workouts and sets under their sessions while
+// preserving the order of sessions as returned by the repository.
+func repositoryListToModels(sessions []*repository.WorkoutSession, workouts []*repository.Workout, sets []*repository.WorkoutSet) []models.WorkoutSession {
+	workoutsBySession := make(map[int64][]*repository.Workout)
+	sessionByWorkout := make(map[int64]int64)
+	for _, workout := range workouts {
+		workoutsBySession[workout.SessionId] = append(workoutsBySession[workout.SessionId], workout)
+		sessionByWorkout[workout.ID] = workout.SessionId
+	}
+	setsBySession := make(map[int64][]*repository.WorkoutSet)
+	for _, set := range sets {
+		sessionID := sessionByWorkout[set.WorkoutID]
+		setsBySession[sessionID] = append(setsBySession[sessionID], set)
+	}
+	modelSessions := make([]models.WorkoutSession, 0, len(sessions))
+	for _, session := range sessions {
+		modelSessions = append(modelSessions, *repositoryToModels(session, workoutsBySession[session.ID], setsBySession[session.ID]))
+	}
+	return modelSessions
+}
+
+// encodeCursor produces an opaque pagination cursor pointing at the given session.
+func encodeCursor(session *repository.WorkoutSession) string {
+	raw := fmt.Sprintf("%d:%d", session.CreatedAt.UnixMicro(), session.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	createdAtPart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(createdAtPart, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.UnixMicro(createdAt).UTC(), id, nil
+}
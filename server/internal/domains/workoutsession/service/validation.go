@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/TBuckholz5/workouttracker/internal/domains/exercise/policy"
+	"github.com/TBuckholz5/workouttracker/internal/domains/workoutsession/models"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+)
+
+// maxSetWeight is the heaviest weight a set may record, in kilograms. It is
+// the largest value workout_sets.weight, a DECIMAL(8,3), can store.
+const maxSetWeight = 99999.999
+
+// checkWorkouts enforces the rules on logged workouts that request tags can't
+// express: weights in range once converted to kilograms and set orders unique
+// within a workout. Only then is the exercise policy asked whether the user
+// may log every referenced exercise. prefix returns the field path of the
+// i-th workout, so errors point at the offending request field.
+func (s *Service) checkWorkouts(reqContext context.Context, userID int64, workouts []models.Workout, prefix func(i int) string) error {
+	var fields []apperror.FieldError
+	refs := make([]policy.Reference, 0, len(workouts))
+	for i, workout := range workouts {
+		path := prefix(i)
+		orders := make(map[int]bool, len(workout.Sets))
+		for j, set := range workout.Sets {
+			setPath := fmt.Sprintf("%ssets[%d].", path, j)
+			if fieldErr, ok := checkWeight(setPath+"weight", set.Weight); !ok {
+				fields = append(fields, fieldErr)
+			}
+			if orders[set.SetOrder] {
+				fields = append(fields, apperror.Field(setPath+"set_order", "duplicate", "is already used by another set of the workout"))
+			}
+			orders[set.SetOrder] = true
+		}
+		refs = append(refs, policy.Reference{Field: path + "exerciseID", ExerciseID: workout.ExerciseID})
+	}
+	if err := validationError(fields); err != nil {
+		return err
+	}
+	return s.exercises.CanUse(reqContext, userID, refs...)
+}
+
+// checkWeight compares the weight as the column will store it, rounded to
+// three decimals, so converted pound values just below the limit still fit.
+func checkWeight(field string, weight float64) (apperror.FieldError, bool) {
+	if weight < 0 || math.Round(weight*1000)/1000 > maxSetWeight {
+		return apperror.Field(field, "range", fmt.Sprintf("must be between 0 and %g kg", maxSetWeight)), false
+	}
+	return apperror.FieldError{}, true
+}
+
+func validationError(fields []apperror.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return apperror.Validation("validation_failed", "request failed validation", fields...)
+}
+
+func sessionWorkoutPath(i int) string {
+	return fmt.Sprintf("workouts[%d].", i)
+}
+
+func singleWorkoutPath(int) string {
+	return ""
+}
@@ -2,25 +2,61 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/logging"
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
 	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
 )
 
+// AccessTokenPrefix starts every personal access token; anything else in the
+// Authorization header is treated as a JWT.
+const AccessTokenPrefix = "wt_pat_"
+
 type ctxKey struct {
 	name string
 }
 
-var CtxKeyUserID = ctxKey{"userID"}
+var (
+	CtxKeyUserID    = ctxKey{"userID"}
+	CtxKeySessionID = ctxKey{"sessionID"}
+	// CtxKeyScopes holds the scopes of a personal access token. It is unset
+	// for logins, which have full access.
+	CtxKeyScopes = ctxKey{"scopes"}
+)
+
+var (
+	ErrUnauthenticated = apperror.Unauthorized("unauthenticated", "missing or invalid credentials")
+	// ErrNoUserID means a handler that needs a user was registered without
+	// the auth middleware, so it is reported as an internal error.
+	ErrNoUserID = errors.New("request has no authenticated user")
+)
+
+// SessionValidator reports whether a login session is still valid, so that
+// access tokens stop working as soon as their session is revoked.
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID int64) (bool, error)
+}
+
+// AccessTokenAuthenticator resolves personal access tokens. ok is false for
+// tokens that aren't valid access tokens.
+type AccessTokenAuthenticator interface {
+	AuthenticateAccessToken(ctx context.Context, token string) (userID int64, scopes []string, ok bool, err error)
+}
 
 type AuthMiddleware struct {
-	JwtService jwt.JwtService
+	JwtService   jwt.JwtService
+	Sessions     SessionValidator
+	AccessTokens AccessTokenAuthenticator
 }
 
-func NewAuthMiddleware(jwtService jwt.JwtService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService jwt.JwtService, sessions SessionValidator, accessTokens AccessTokenAuthenticator) *AuthMiddleware {
 	return &AuthMiddleware{
-		JwtService: jwtService,
+		JwtService:   jwtService,
+		Sessions:     sessions,
+		AccessTokens: accessTokens,
 	}
 }
 
@@ -29,16 +65,49 @@ func (a *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 		authHeader := r.Header.Get("Authorization")
 		const prefix = "Bearer "
 		if !strings.HasPrefix(authHeader, prefix) {
-			w.WriteHeader(http.StatusUnauthorized)
+			apperror.Write(w, r, ErrUnauthenticated)
+			return
 		}
 		authHeader = strings.TrimSpace(strings.TrimPrefix(authHeader, prefix))
-		userID, err := a.JwtService.ValidateJwt(authHeader)
+		if a.AccessTokens != nil && strings.HasPrefix(authHeader, AccessTokenPrefix) {
+			a.serveAccessToken(w, r, next, authHeader)
+			return
+		}
+		claims, err := a.JwtService.ValidateJwt(authHeader)
+		if err != nil {
+			apperror.Write(w, r, ErrUnauthenticated)
+			return
+		}
+		active, err := a.Sessions.IsSessionActive(r.Context(), claims.SessionID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			apperror.Write(w, r, err)
+			return
+		}
+		if !active {
+			apperror.Write(w, r, ErrUnauthenticated)
+			return
 		}
-		ctx := context.WithValue(r.Context(), CtxKeyUserID, userID)
+		ctx := logging.SetUserID(r.Context(), claims.UserID)
+		ctx = context.WithValue(ctx, CtxKeyUserID, claims.UserID)
+		ctx = context.WithValue(ctx, CtxKeySessionID, claims.SessionID)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (a *AuthMiddleware) serveAccessToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	userID, scopes, ok, err := a.AccessTokens.AuthenticateAccessToken(r.Context(), token)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if !ok {
+		apperror.Write(w, r, ErrUnauthenticated)
+		return
+	}
+	ctx := logging.SetUserID(r.Context(), userID)
+	ctx = context.WithValue(ctx, CtxKeyUserID, userID)
+	ctx = context.WithValue(ctx, CtxKeyScopes, scopes)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
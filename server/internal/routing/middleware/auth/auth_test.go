@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAccessTokens struct{}
+
+func (stubAccessTokens) AuthenticateAccessToken(ctx context.Context, token string) (int64, []string, bool, error) {
+	if token != AccessTokenPrefix+"valid" {
+		return 0, nil, false, nil
+	}
+	return 1, []string{"sessions:read"}, true, nil
+}
+
+type stubSessions struct{}
+
+func (stubSessions) IsSessionActive(ctx context.Context, sessionID int64) (bool, error) {
+	return true, nil
+}
+
+func serve(handler http.Handler, method, token string) int {
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAccessTokenScopes(t *testing.T) {
+	jwtService := jwt.NewJwtService([]byte("secret"))
+	auth := NewAuthMiddleware(jwtService, stubSessions{}, stubAccessTokens{})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// Listed innermost first, as routing.RegisterRoute applies them.
+	handler := auth.Wrap(NewScopeMiddleware("sessions").Wrap(ok))
+	sessionOnly := auth.Wrap(NewSessionOnlyMiddleware().Wrap(ok))
+
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, AccessTokenPrefix+"valid"))
+	assert.Equal(t, http.StatusForbidden, serve(handler, http.MethodPost, AccessTokenPrefix+"valid"))
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, http.MethodGet, AccessTokenPrefix+"revoked"))
+	assert.Equal(t, http.StatusForbidden, serve(sessionOnly, http.MethodGet, AccessTokenPrefix+"valid"))
+
+	login, err := jwtService.GenerateJwt(1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodPost, login))
+	assert.Equal(t, http.StatusOK, serve(sessionOnly, http.MethodPost, login))
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+)
+
+var (
+	ErrInsufficientScope = apperror.Forbidden("insufficient_scope", "access token lacks the required scope")
+	ErrSessionRequired   = apperror.Forbidden("session_required", "this endpoint needs an interactive login")
+)
+
+// HasScope reports whether the request may use scope. Requests authenticated
+// by a login session have every scope.
+func HasScope(ctx context.Context, scope string) bool {
+	scopes, ok := ctx.Value(CtxKeyScopes).([]string)
+	if !ok {
+		return true
+	}
+	return slices.Contains(scopes, scope)
+}
+
+// ScopeMiddleware requires "<resource>:read" for GET and HEAD requests and
+// "<resource>:write" for everything else. It must run after AuthMiddleware,
+// so it goes before it in a middleware list.
+type ScopeMiddleware struct {
+	resource string
+}
+
+func NewScopeMiddleware(resource string) *ScopeMiddleware {
+	return &ScopeMiddleware{resource: resource}
+}
+
+func (s *ScopeMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := s.resource + ":write"
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			scope = s.resource + ":read"
+		}
+		if !HasScope(r.Context(), scope) {
+			apperror.Write(w, r, ErrInsufficientScope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionOnlyMiddleware rejects personal access tokens, for account
+// management endpoints that need an interactive login.
+type SessionOnlyMiddleware struct{}
+
+func NewSessionOnlyMiddleware() *SessionOnlyMiddleware {
+	return &SessionOnlyMiddleware{}
+}
+
+func (s *SessionOnlyMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(CtxKeySessionID) == nil {
+			apperror.Write(w, r, ErrSessionRequired)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
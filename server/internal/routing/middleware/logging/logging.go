@@ -1,19 +1,172 @@
 package logging
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
 )
 
-type LoggingMiddleware struct{}
+// RequestIDHeader carries the request ID. An ID sent by the client or a proxy
+// is kept, otherwise one is generated; either way it is echoed back.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type ctxKey struct{}
+
+// requestInfo collects what is only known deeper in the handler chain, so the
+// access log written once the request is done can include it.
+type requestInfo struct {
+	id      string
+	prefix  string
+	route   string
+	userID  int64
+	hasUser bool
+}
+
+type LoggingMiddleware struct {
+	logger *slog.Logger
+}
 
-func NewLoggingMiddleware() *LoggingMiddleware {
-	return &LoggingMiddleware{}
+func NewLoggingMiddleware(logger *slog.Logger) *LoggingMiddleware {
+	return &LoggingMiddleware{logger: logger}
 }
 
 func (l *LoggingMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		next.ServeHTTP(w, r)
-		log.Default().Printf("%s %s %s", r.RemoteAddr, r.Method, r.URL)
+		start := time.Now()
+		info := &requestInfo{id: requestID(r.Header.Get(RequestIDHeader))}
+		w.Header().Set(RequestIDHeader, info.id)
+
+		ctx := context.WithValue(r.Context(), ctxKey{}, info)
+		ctx = logger.WithLogger(ctx, l.logger.With("request_id", info.id))
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", info.route,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+		}
+		if info.hasUser {
+			attrs = append(attrs, "user_id", info.userID)
+		}
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.FromContext(ctx).Log(ctx, level, "request", attrs...)
 	})
 }
+
+// RequestID returns the ID of the request being served, or "" outside of
+// the middleware.
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// AddRoutePrefix records the prefix of a router group the request was passed
+// through.
+func AddRoutePrefix(ctx context.Context, prefix string) {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		info.prefix += prefix
+	}
+}
+
+// SetRoute records the pattern, like "GET /{id}", of the route that handles
+// the request. Prefixes of the enclosing groups are prepended, so the access
+// log shows the full pattern rather than the raw, high-cardinality path.
+func SetRoute(ctx context.Context, pattern string) {
+	info, ok := ctx.Value(ctxKey{}).(*requestInfo)
+	if !ok {
+		return
+	}
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	info.route = strings.TrimSpace(method + " " + info.prefix + path)
+}
+
+// Route returns the full route pattern recorded by SetRoute, or "" when no
+// route matched.
+func Route(ctx context.Context) string {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.route
+	}
+	return ""
+}
+
+// SetUserID records the authenticated user for the access log and adds it to
+// the request logger.
+func SetUserID(ctx context.Context, userID int64) context.Context {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		info.userID, info.hasUser = userID, true
+	}
+	return logger.With(ctx, "user_id", userID)
+}
+
+func requestID(header string) string {
+	if validRequestID(header) {
+		return header
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID only accepts short IDs of URL-safe characters, so a client
+// can't inject arbitrary text into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(t *testing.T, handler http.Handler, requestID string) (*httptest.ResponseRecorder, []map[string]any) {
+	t.Helper()
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "json", "info")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/exercise/12", nil)
+	if requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	rec := httptest.NewRecorder()
+	NewLoggingMiddleware(l).Wrap(handler).ServeHTTP(rec, req)
+
+	var entries []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var entry map[string]any
+		assert.NoError(t, dec.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return rec, entries
+}
+
+func TestWrap_LogsRouteUserAndResponse(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddRoutePrefix(r.Context(), "/api/v1")
+		AddRoutePrefix(r.Context(), "/exercise")
+		SetRoute(r.Context(), "GET /{id}")
+		ctx := SetUserID(r.Context(), 42)
+		logger.FromContext(ctx).Info("loading exercise")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	})
+
+	rec, entries := serve(t, handler, "req-1")
+
+	assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "loading exercise", entries[0]["msg"])
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, float64(42), entries[0]["user_id"])
+
+	access := entries[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "req-1", access["request_id"])
+	assert.Equal(t, "GET /api/v1/exercise/{id}", access["route"])
+	assert.Equal(t, "/api/v1/exercise/12", access["path"])
+	assert.Equal(t, float64(http.StatusNotFound), access["status"])
+	assert.Equal(t, float64(len("missing")), access["bytes"])
+	assert.Equal(t, float64(42), access["user_id"])
+}
+
+func TestWrap_GeneratesRequestID(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rec, entries := serve(t, ok, "")
+	generated := rec.Header().Get(RequestIDHeader)
+	assert.Len(t, generated, 32)
+	assert.Equal(t, generated, entries[0]["request_id"])
+	assert.NotContains(t, entries[0], "user_id")
+
+	rec, _ = serve(t, ok, "bad id\nforged=entry")
+	assert.Len(t, rec.Header().Get(RequestIDHeader), 32)
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests no route handled, so unknown paths don't
+// create a series each.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware counts requests and their latency per route pattern and
+// status. It reads the pattern recorded for the logging middleware, so it
+// must be wrapped by it.
+type MetricsMiddleware struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewMetricsMiddleware(registerer prometheus.Registerer) *MetricsMiddleware {
+	m := &MetricsMiddleware{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "workouttracker",
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route pattern and status.",
+		}, []string{"route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "workouttracker",
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route pattern and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+	}
+	registerer.MustRegister(m.requests, m.duration)
+	return m
+}
+
+func (m *MetricsMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := logging.Route(r.Context())
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(rec.status)
+		m.requests.WithLabelValues(route, status).Inc()
+		m.duration.WithLabelValues(route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
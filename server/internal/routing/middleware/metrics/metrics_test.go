@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap_CountsByRoutePattern(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetricsMiddleware(registry)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /exercise/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logging.SetRoute(r.Context(), r.Pattern)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	handler := logging.NewLoggingMiddleware(slog.New(slog.DiscardHandler)).Wrap(m.Wrap(mux))
+
+	for _, path := range []string{"/exercise/1", "/exercise/2", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("GET /exercise/{id}", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(unmatchedRoute, "404")))
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP workouttracker_http_requests_total HTTP requests by route pattern and status.
+# TYPE workouttracker_http_requests_total counter
+workouttracker_http_requests_total{route="GET /exercise/{id}",status="404"} 2
+workouttracker_http_requests_total{route="unmatched",status="404"} 1
+`), "workouttracker_http_requests_total"))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.duration))
+}
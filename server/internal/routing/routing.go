@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/TBuckholz5/workouttracker/internal/routing/middleware"
+	"github.com/TBuckholz5/workouttracker/internal/routing/middleware/logging"
 )
 
 type Config struct {
@@ -18,17 +19,26 @@ type Config struct {
 }
 
 func RegisterRoute(config Config) {
-	handler := config.Handler
+	pattern := fmt.Sprintf("%s %s", config.Method, config.Route)
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.SetRoute(r.Context(), pattern)
+		config.Handler.ServeHTTP(w, r)
+	}))
 	for _, mw := range config.Middlewares {
 		handler = mw.Wrap(handler)
 	}
 
-	config.Mux.Handle(fmt.Sprintf("%s %s", config.Method, config.Route), handler)
+	config.Mux.Handle(pattern, handler)
 }
 
 func RegisterRouterGroup(config Config) *http.ServeMux {
 	mux := http.NewServeMux()
-	handler := http.StripPrefix(strings.TrimSuffix(config.GroupRoute, "/"), mux)
+	prefix := strings.TrimSuffix(config.GroupRoute, "/")
+	stripped := http.StripPrefix(prefix, mux)
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.AddRoutePrefix(r.Context(), prefix)
+		stripped.ServeHTTP(w, r)
+	}))
 	for _, mw := range config.Middlewares {
 		handler = mw.Wrap(handler)
 	}
@@ -0,0 +1,126 @@
+// Package apperror defines the errors services return for expected failures,
+// such as a missing record or invalid input, and writes them as RFC 7807
+// problem details. Any other error is treated as an internal failure.
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindRateLimited
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindUnprocessable
+)
+
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError points at the part of the request that caused an error.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Code is a stable identifier clients can switch on, e.g. "username_taken".
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so an error built with extra fields still
+// matches the sentinel it was derived from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message, Fields: fields}
+}
+
+func RateLimited(code, message string) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Message: message}
+}
+
+func TooLarge(code, message string) *Error {
+	return &Error{Kind: KindTooLarge, Code: code, Message: message}
+}
+
+func UnsupportedMediaType(code, message string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Code: code, Message: message}
+}
+
+// Unprocessable is for well-formed requests that refer to something that
+// can't be used, such as an unknown ID.
+func Unprocessable(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message, Fields: fields}
+}
+
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+// Errors shared by all handlers for requests that can't be parsed.
+var ErrMalformedBody = Validation("malformed_body", "request body is not valid JSON")
+
+// InvalidParameter reports a path or query parameter that can't be parsed.
+func InvalidParameter(name string) *Error {
+	return Validation("invalid_parameter", fmt.Sprintf("%s is invalid", name),
+		Field(name, "invalid", "has an invalid value"))
+}
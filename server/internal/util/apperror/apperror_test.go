@@ -0,0 +1,55 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_IsMatchesByCode(t *testing.T) {
+	sentinel := Conflict("username_taken", "username is already taken")
+	withField := Conflict("username_taken", "username is already taken", Field("username", "taken", "is taken"))
+
+	assert.ErrorIs(t, fmt.Errorf("register: %w", withField), sentinel)
+	assert.False(t, errors.Is(withField, NotFound("user_not_found", "user not found")))
+}
+
+func TestWrite_DomainError(t *testing.T) {
+	err := Conflict("username_taken", "username is already taken", Field("username", "taken", "is already taken"))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/user/register", nil)
+
+	Write(rec, req, fmt.Errorf("create user: %w", err))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, Problem{
+		Type:     "about:blank",
+		Title:    "Conflict",
+		Status:   http.StatusConflict,
+		Detail:   "username is already taken",
+		Instance: "/api/v1/user/register",
+		Code:     "username_taken",
+		Errors:   []FieldError{{Field: "username", Code: "taken", Message: "is already taken"}},
+	}, problem)
+}
+
+func TestWrite_UnexpectedErrorHidesDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/routine/list", nil)
+
+	Write(rec, req, errors.New("connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, problem.Detail, "connection refused")
+}
@@ -0,0 +1,48 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/logger"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code and Errors are extension
+// members carrying the stable error code and field-level details.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+var errInternal = &Error{Kind: KindInternal, Code: "internal_error", Message: "the server failed to handle the request"}
+
+// Write responds with the problem for err. Errors that are not an *Error are
+// logged and reported as a generic internal error, so their details don't
+// leak to clients.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		logger.FromContext(r.Context()).Error("unhandled error", "method", r.Method, "path", r.URL.Path, "error", err)
+		appErr = errInternal
+	}
+	status := appErr.Kind.Status()
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appErr.Message,
+		Instance: r.URL.Path,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	})
+}
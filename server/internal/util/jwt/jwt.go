@@ -9,53 +9,94 @@ import (
 
 const ISSUER = "workout-tracker"
 
+// AccessTokenTTL is kept short; clients renew access tokens with a refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
+// Claims identifies the user and the login session an access token was issued for.
+type Claims struct {
+	UserID    int64
+	SessionID int64
+}
+
 type JwtService interface {
-	GenerateJwt(userID int64) (string, error)
-	ValidateJwt(tokenString string) (int64, error)
+	GenerateJwt(userID, sessionID int64) (string, error)
+	ValidateJwt(tokenString string) (Claims, error)
 }
 
 type Jwt struct {
-	secret []byte
+	keys *KeySet
 }
 
+// NewJwtService signs with a single shared HS256 secret.
 func NewJwtService(jwtSecret []byte) *Jwt {
+	return NewJwtServiceWithKeys(NewHMACKeySet(jwtSecret))
+}
+
+func NewJwtServiceWithKeys(keys *KeySet) *Jwt {
 	return &Jwt{
-		secret: jwtSecret,
+		keys: keys,
 	}
 }
 
-func (j *Jwt) GenerateJwt(userID int64) (string, error) {
+func (j *Jwt) GenerateJwt(userID, sessionID int64) (string, error) {
 	claims := jwt.MapClaims{
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"exp": time.Now().Add(AccessTokenTTL).Unix(),
 		"sub": userID,
+		"sid": sessionID,
 		"iss": ISSUER,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key := j.keys.active
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
 
-	signedToken, err := token.SignedString(j.secret)
+	signedToken, err := token.SignedString(key.signingKey)
 	if err != nil {
 		return "", err
 	}
 	return signedToken, nil
 }
 
-func (j *Jwt) ValidateJwt(tokenString string) (int64, error) {
+func (j *Jwt) ValidateJwt(tokenString string) (Claims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return j.secret, nil
-	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keys.verificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// Pin the algorithm to the key so a public key can never be used as an HMAC secret.
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods([]string{
+		jwt.SigningMethodHS256.Alg(),
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
 	if err != nil {
-		return 0, err
+		return Claims{}, err
 	}
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		return 0, fmt.Errorf("invalid token claims")
+		return Claims{}, fmt.Errorf("invalid token claims")
 	}
 	if claims["iss"] != ISSUER {
-		return 0, fmt.Errorf("invalid token issuer")
+		return Claims{}, fmt.Errorf("invalid token issuer")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Now().Unix() > int64(exp) {
+		return Claims{}, fmt.Errorf("token has expired")
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return Claims{}, fmt.Errorf("invalid token subject")
 	}
-	if time.Now().Unix() > int64(claims["exp"].(float64)) {
-		return 0, fmt.Errorf("token has expired")
+	sid, ok := claims["sid"].(float64)
+	if !ok {
+		return Claims{}, fmt.Errorf("token is not bound to a session")
 	}
-	return int64(claims["sub"].(float64)), nil
+	return Claims{UserID: int64(sub), SessionID: int64(sid)}, nil
 }
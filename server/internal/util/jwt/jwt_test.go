@@ -0,0 +1,208 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, dir, kid string, key any) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(dir, kid+".pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+// testKeyDir writes an old RSA key and a new Ed25519 key. The old key file is
+// written last, so file times would wrongly pick it as the newest.
+func testKeyDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writeKey(t, dir, "new-ed", edKey)
+	writeKey(t, dir, "old-rsa", rsaKey)
+	return dir
+}
+
+func TestHMAC_RoundTrip(t *testing.T) {
+	svc := NewJwtService([]byte("secret"))
+	token, err := svc.GenerateJwt(1, 7)
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateJwt(token)
+	require.NoError(t, err)
+	assert.Equal(t, Claims{UserID: 1, SessionID: 7}, claims)
+
+	_, err = NewJwtService([]byte("other")).ValidateJwt(token)
+	assert.Error(t, err)
+	assert.Empty(t, NewHMACKeySet([]byte("secret")).JWKS().Keys)
+}
+
+func TestKeySet_SignsWithActiveKey(t *testing.T) {
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed"}, nil)
+	require.NoError(t, err)
+	svc := NewJwtServiceWithKeys(set)
+
+	signed, err := svc.GenerateJwt(1, 7)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new-ed", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	claims, err := svc.ValidateJwt(signed)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), claims.UserID)
+}
+
+func TestKeySet_RequiresActiveKID(t *testing.T) {
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+
+	_, err = NewKeySet(keys, Rotation{}, nil)
+	assert.Error(t, err)
+	_, err = NewKeySet(keys, Rotation{ActiveKID: "missing"}, nil)
+	assert.Error(t, err)
+}
+
+func TestKeySet_GracePeriod(t *testing.T) {
+	rotatedAt := time.Now()
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+
+	oldSet, err := NewKeySet(keys, Rotation{ActiveKID: "old-rsa"}, nil)
+	require.NoError(t, err)
+	oldToken, err := NewJwtServiceWithKeys(oldSet).GenerateJwt(1, 7)
+	require.NoError(t, err)
+
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed", RotatedAt: rotatedAt, GracePeriod: time.Hour}, nil)
+	require.NoError(t, err)
+	svc := NewJwtServiceWithKeys(set)
+
+	_, err = svc.ValidateJwt(oldToken)
+	assert.NoError(t, err, "old key verifies during the grace period")
+	assert.Len(t, set.JWKS().Keys, 2)
+
+	set.now = func() time.Time { return rotatedAt.Add(2 * time.Hour) }
+	_, err = svc.ValidateJwt(oldToken)
+	assert.Error(t, err, "old key is retired after the grace period")
+	jwks := set.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "new-ed", jwks.Keys[0].Kid)
+}
+
+func TestKeySet_KeepsOldKeysWithoutRotationTime(t *testing.T) {
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+	oldSet, err := NewKeySet(keys, Rotation{ActiveKID: "old-rsa"}, nil)
+	require.NoError(t, err)
+	oldToken, err := NewJwtServiceWithKeys(oldSet).GenerateJwt(1, 7)
+	require.NoError(t, err)
+
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed", GracePeriod: time.Hour}, nil)
+	require.NoError(t, err)
+	set.now = func() time.Time { return time.Now().Add(30 * 24 * time.Hour) }
+
+	_, err = NewJwtServiceWithKeys(set).ValidateJwt(oldToken)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_LegacyHMACTokens(t *testing.T) {
+	legacyToken, err := NewJwtService([]byte("secret")).GenerateJwt(1, 7)
+	require.NoError(t, err)
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+
+	withLegacy, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed"}, []byte("secret"))
+	require.NoError(t, err)
+	_, err = NewJwtServiceWithKeys(withLegacy).ValidateJwt(legacyToken)
+	assert.NoError(t, err)
+
+	withoutLegacy, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed"}, nil)
+	require.NoError(t, err)
+	_, err = NewJwtServiceWithKeys(withoutLegacy).ValidateJwt(legacyToken)
+	assert.Error(t, err)
+}
+
+func TestKeySet_RetiresLegacySecret(t *testing.T) {
+	rotatedAt := time.Now()
+	legacyToken, err := NewJwtService([]byte("secret")).GenerateJwt(1, 7)
+	require.NoError(t, err)
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed", RotatedAt: rotatedAt, GracePeriod: time.Hour}, []byte("secret"))
+	require.NoError(t, err)
+	svc := NewJwtServiceWithKeys(set)
+
+	_, err = svc.ValidateJwt(legacyToken)
+	assert.NoError(t, err, "legacy secret verifies during the grace period")
+
+	set.now = func() time.Time { return rotatedAt.Add(2 * time.Hour) }
+	_, err = svc.ValidateJwt(legacyToken)
+	assert.Error(t, err, "legacy secret is retired after the grace period")
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed"}, nil)
+	require.NoError(t, err)
+
+	// An HS256 token claiming the kid of an asymmetric key must not verify.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"sub": 1,
+		"sid": 7,
+		"iss": ISSUER,
+	})
+	forged.Header["kid"] = "new-ed"
+	signed, err := forged.SignedString([]byte("guess"))
+	require.NoError(t, err)
+	_, err = NewJwtServiceWithKeys(set).ValidateJwt(signed)
+	assert.Error(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	keys, err := LoadKeyDir(testKeyDir(t))
+	require.NoError(t, err)
+	set, err := NewKeySet(keys, Rotation{ActiveKID: "new-ed"}, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(set).ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var body JWKS
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Keys, 2)
+	assert.Equal(t, JWK{Kty: "OKP", Kid: "new-ed", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: body.Keys[0].X}, body.Keys[0])
+	assert.Equal(t, "RSA", body.Keys[1].Kty)
+	assert.Equal(t, "AQAB", body.Keys[1].E)
+}
+
+func TestParsePEMKey_RejectsSmallRSAKeys(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(small)
+	require.NoError(t, err)
+	_, err = ParsePEMKey("small", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.Error(t, err)
+}
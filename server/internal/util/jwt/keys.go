@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+// Key is a single signing or verification key identified by its kid.
+type Key struct {
+	ID         string
+	method     jwt.SigningMethod
+	signingKey any
+	verifyKey  any
+}
+
+func (k *Key) canSign() bool {
+	return k.signingKey != nil
+}
+
+// Rotation names the key that signs new tokens. Other keys keep verifying,
+// so tokens issued before a rotation stay valid until they expire; when
+// RotatedAt is set they are retired GracePeriod after it, otherwise once
+// their files are removed. Both come from config rather than file times,
+// which copies and remounts reset.
+type Rotation struct {
+	ActiveKID   string
+	RotatedAt   time.Time
+	GracePeriod time.Duration
+}
+
+// KeySet holds the key used to sign new tokens and the keys still accepted
+// when verifying.
+type KeySet struct {
+	active   *Key
+	keys     map[string]*Key
+	retireAt time.Time
+	// legacy verifies HS256 tokens issued before keys carried a kid.
+	legacy *Key
+	now    func() time.Time
+}
+
+// NewHMACKeySet signs and verifies with a single shared HS256 secret.
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &Key{method: jwt.SigningMethodHS256, signingKey: secret, verifyKey: secret}
+	return &KeySet{
+		active: key,
+		keys:   map[string]*Key{},
+		legacy: key,
+		now:    time.Now,
+	}
+}
+
+// NewKeySet builds a key set from asymmetric keys. The key with
+// rotation.ActiveKID signs new tokens. A non-empty legacySecret keeps HS256
+// tokens without a kid verifiable while deployments migrate away from the
+// shared secret, until the grace period after rotation.RotatedAt ends.
+func NewKeySet(keys []*Key, rotation Rotation, legacySecret []byte) (*KeySet, error) {
+	set := &KeySet{keys: map[string]*Key{}, now: time.Now}
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	if rotation.ActiveKID == "" {
+		return nil, errors.New("the active key id must be set when signing keys are configured")
+	}
+	set.active = set.keys[rotation.ActiveKID]
+	if set.active == nil || !set.active.canSign() {
+		return nil, fmt.Errorf("active key %q not found or has no private key", rotation.ActiveKID)
+	}
+	if !rotation.RotatedAt.IsZero() {
+		set.retireAt = rotation.RotatedAt.Add(rotation.GracePeriod)
+	}
+	if len(legacySecret) > 0 {
+		set.legacy = &Key{method: jwt.SigningMethodHS256, signingKey: legacySecret, verifyKey: legacySecret}
+	}
+	return set, nil
+}
+
+// verificationKey returns the key for a token's kid, or false if the key is
+// unknown or retired past its grace period. Tokens without a kid use the
+// legacy secret, which retires along with the other non-active keys.
+func (s *KeySet) verificationKey(kid string) (*Key, bool) {
+	key := s.legacy
+	if kid != "" {
+		key = s.keys[kid]
+	}
+	if key == nil || s.retired(key) {
+		return nil, false
+	}
+	return key, true
+}
+
+func (s *KeySet) retired(key *Key) bool {
+	return key != s.active && !s.retireAt.IsZero() && s.now().After(s.retireAt)
+}
+
+// publicKeys lists the asymmetric keys that currently verify, the active key
+// first.
+func (s *KeySet) publicKeys() []*Key {
+	var keys []*Key
+	for kid := range s.keys {
+		if key, ok := s.verificationKey(kid); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == s.active) != (keys[j] == s.active) {
+			return keys[i] == s.active
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// LoadKeyDir loads every .pem file in dir. The file name without extension
+// becomes the kid.
+func LoadKeyDir(dir string) ([]*Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key directory: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .pem keys found in %s", dir)
+	}
+	return LoadKeyFiles(paths)
+}
+
+func LoadKeyFiles(paths []string) ([]*Key, error) {
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := ParsePEMKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParsePEMKey parses an RSA or Ed25519 key. Private keys may be PKCS#8 or
+// PKCS#1; a PUBLIC KEY block yields a verification-only key.
+func ParsePEMKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key := &Key{ID: kid}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.signingKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.signingKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if rsaKey, ok := key.verifyKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
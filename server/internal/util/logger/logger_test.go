@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_JSONWithLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "json", "warn")
+	assert.NoError(t, err)
+
+	l.Info("dropped")
+	l.Warn("kept", "user_id", 42)
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "kept", entry["msg"])
+	assert.Equal(t, float64(42), entry["user_id"])
+}
+
+func TestNew_RejectsUnknownSettings(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", "info")
+	assert.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, "text", "loud")
+	assert.Error(t, err)
+}
+
+func TestWith_AddsAttributesToContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "text", "info")
+	assert.NoError(t, err)
+
+	ctx := With(WithLogger(context.Background(), l), "request_id", "abc")
+	FromContext(ctx).Info("hello")
+
+	assert.Contains(t, buf.String(), "request_id=abc")
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatRejectsHeaderInjection(t *testing.T) {
+	_, err := format("from@example.com", Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "hi"})
+	assert.Error(t, err)
+}
+
+func TestFileMailerWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, "from@example.com")
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{To: "to@example.com", Subject: "Hello", Body: "line one\nline two\n"})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "To: to@example.com\r\n")
+	assert.Contains(t, content, "Subject: Hello\r\n")
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\nline one\r\nline two\r\n"))
+}
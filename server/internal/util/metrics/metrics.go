@@ -0,0 +1,53 @@
+// Package metrics holds the Prometheus collectors of the server. Domain
+// counters live here so services can count events without threading a
+// registry through every constructor.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const Namespace = "workouttracker"
+
+var ErrUnauthorized = apperror.Unauthorized("unauthenticated", "missing or invalid metrics token")
+
+var (
+	SessionsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "sessions_created_total",
+		Help:      "Workout sessions logged or started.",
+	})
+	SetsLogged = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "sets_logged_total",
+		Help:      "Workout sets saved, including those of new sessions and workouts.",
+	})
+	FailedLogins = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "failed_logins_total",
+		Help:      "Logins rejected for a wrong password or second factor.",
+	})
+)
+
+// Handler serves the default registry. When token is set, scrapers must send
+// it as a bearer token.
+func Handler(token string) http.Handler {
+	handler := promhttp.Handler()
+	if token == "" {
+		return handler
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			apperror.Write(w, r, ErrUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(handler http.Handler, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Token(t *testing.T) {
+	SessionsCreated.Inc()
+	protected := Handler("secret")
+
+	assert.Equal(t, http.StatusUnauthorized, scrape(protected, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, scrape(protected, "Bearer wrong").Code)
+
+	rec := scrape(protected, "Bearer secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "workouttracker_sessions_created_total")
+
+	assert.Equal(t, http.StatusOK, scrape(Handler(""), "").Code)
+}
@@ -0,0 +1,195 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockProvider is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that checks the PKCE verifier against the challenge it was given.
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu         sync.Mutex
+	challenges map[string]string // code -> S256 challenge
+	claims     jwtlib.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &mockProvider{t: t, key: key, challenges: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		p.mu.Lock()
+		challenge := p.challenges[r.Form.Get("code")]
+		claims := p.claims
+		p.mu.Unlock()
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if challenge == "" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		tok := jwtlib.NewWithClaims(jwtlib.SigningMethodRS256, claims)
+		tok.Header["kid"] = "test"
+		idToken, err := tok.SignedString(key)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize plays the user approving the login: it records the PKCE
+// challenge from the authorization URL and returns the issued code.
+func (p *mockProvider) authorize(authURL string, claims jwtlib.MapClaims) string {
+	u, err := url.Parse(authURL)
+	require.NoError(p.t, err)
+	q := u.Query()
+	assert.Equal(p.t, "S256", q.Get("code_challenge_method"))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.challenges["code-1"] = q.Get("code_challenge")
+	p.claims = claims
+	return "code-1"
+}
+
+func (p *mockProvider) claimsFor(nonce string) jwtlib.MapClaims {
+	return jwtlib.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            "user-42",
+		"aud":            "client-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "jane@example.com",
+		"email_verified": "true",
+		"name":           "Jane",
+	}
+}
+
+func newTestProvider(p *mockProvider) *Provider {
+	return NewProvider(Config{
+		Issuer:       p.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	})
+}
+
+func TestExchange(t *testing.T) {
+	mock := newMockProvider(t)
+	provider := newTestProvider(mock)
+	verifier := GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+	u, _ := url.Parse(authURL)
+	assert.Equal(t, mock.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "state-1", u.Query().Get("state"))
+	assert.Equal(t, "nonce-1", u.Query().Get("nonce"))
+	assert.Empty(t, u.Query().Get("code_verifier"))
+
+	code := mock.authorize(authURL, mock.claimsFor("nonce-1"))
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{
+		Issuer:        mock.server.URL,
+		Subject:       "user-42",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane",
+	}, identity)
+}
+
+func TestExchange_WrongVerifier(t *testing.T) {
+	mock := newMockProvider(t)
+	provider := newTestProvider(mock)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", GenerateVerifier())
+	require.NoError(t, err)
+	code := mock.authorize(authURL, mock.claimsFor("nonce"))
+
+	_, err = provider.Exchange(context.Background(), code, GenerateVerifier(), "nonce")
+	assert.ErrorIs(t, err, ErrExchangeFailed)
+}
+
+func TestExchange_RejectsInvalidIDTokens(t *testing.T) {
+	mock := newMockProvider(t)
+	cases := map[string]func(jwtlib.MapClaims){
+		"nonce":    func(c jwtlib.MapClaims) { c["nonce"] = "other" },
+		"audience": func(c jwtlib.MapClaims) { c["aud"] = "client-2" },
+		"issuer":   func(c jwtlib.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":  func(c jwtlib.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			provider := newTestProvider(mock)
+			verifier := GenerateVerifier()
+			authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", verifier)
+			require.NoError(t, err)
+			claims := mock.claimsFor("nonce")
+			mutate(claims)
+			code := mock.authorize(authURL, claims)
+
+			_, err = provider.Exchange(context.Background(), code, verifier, "nonce")
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestDiscoveryIsRetried(t *testing.T) {
+	mock := newMockProvider(t)
+	provider := NewProvider(Config{Issuer: mock.server.URL + "/missing", ClientID: "client-1"})
+
+	_, err := provider.AuthCodeURL(context.Background(), "s", "n", GenerateVerifier())
+	assert.Error(t, err)
+	assert.Nil(t, provider.oauth)
+}
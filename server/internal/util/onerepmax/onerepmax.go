@@ -0,0 +1,46 @@
+package onerepmax
+
+import (
+	"fmt"
+	"math"
+)
+
+type Formula string
+
+const (
+	Epley    Formula = "epley"
+	Brzycki  Formula = "brzycki"
+	Lombardi Formula = "lombardi"
+)
+
+func ParseFormula(name string) (Formula, error) {
+	switch Formula(name) {
+	case "":
+		return Epley, nil
+	case Epley, Brzycki, Lombardi:
+		return Formula(name), nil
+	default:
+		return "", fmt.Errorf("unknown one rep max formula: %s", name)
+	}
+}
+
+// Estimate returns the estimated one-rep max for a set of reps at weight. A
+// single rep is always its own 1RM, and zero reps estimate nothing.
+func Estimate(formula Formula, weight float64, reps int) float64 {
+	if reps <= 0 || weight <= 0 {
+		return 0
+	}
+	if reps == 1 {
+		return weight
+	}
+	r := float64(reps)
+	switch formula {
+	case Brzycki:
+		// The formula diverges at 37 reps; clamp so very high rep sets stay finite.
+		return weight * 36 / (37 - min(r, 36))
+	case Lombardi:
+		return weight * math.Pow(r, 0.10)
+	default:
+		return weight * (1 + r/30)
+	}
+}
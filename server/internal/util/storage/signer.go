@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// maxURLWindow caps how long the same object keeps the same signed URL.
+const maxURLWindow = time.Hour
+
+// URLSigner produces expiring, HMAC signed links for stored objects so they
+// can be fetched without an Authorization header, e.g. by image widgets.
+type URLSigner struct {
+	secret   []byte
+	basePath string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewURLSigner(secret []byte, basePath string, ttl time.Duration) *URLSigner {
+	return &URLSigner{
+		secret:   secret,
+		basePath: basePath,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// URL signs key with an expiry rounded to a window of the TTL, at most an
+// hour, so responses within a window repeat the same URL and caches hit. The
+// expiry counts from the end of the window, so a link stays valid for at
+// least the TTL.
+func (s *URLSigner) URL(key string) string {
+	window := min(s.ttl, maxURLWindow)
+	expiresAt := s.now().Truncate(window).Add(window + s.ttl)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("sig", s.sign(key, expires))
+	return s.basePath + key + "?" + query.Encode()
+}
+
+func (s *URLSigner) Verify(key, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *URLSigner) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
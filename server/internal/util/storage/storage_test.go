@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3 compatible server using
+// path-style addressing.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			body = decodeAWSChunked(body)
+		}
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodHead, http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.body)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(obj.body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked strips the signed chunk framing that clients use for
+// streaming uploads over plain HTTP.
+func decodeAWSChunked(body []byte) []byte {
+	var out []byte
+	for len(body) > 0 {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := bytes.Cut(header, []byte(";"))
+		size, err := strconv.ParseInt(string(sizeHex), 16, 64)
+		if err != nil || size == 0 {
+			break
+		}
+		out = append(out, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+	return out
+}
+
+func exerciseStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+	data := []byte("image-bytes")
+
+	require.NoError(t, s.Put(ctx, "exercises/1/a.png", bytes.NewReader(data), int64(len(data)), "image/png"))
+
+	rc, contentType, err := s.Get(ctx, "exercises/1/a.png")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.Equal(t, data, got)
+	assert.Equal(t, "image/png", contentType)
+
+	require.NoError(t, s.Delete(ctx, "exercises/1/a.png"))
+	_, _, err = s.Get(ctx, "exercises/1/a.png")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	exerciseStorage(t, s)
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	err = s.Put(context.Background(), "../outside.png", strings.NewReader("x"), 1, "image/png")
+	assert.Error(t, err)
+	_, _, err = s.Get(context.Background(), "/etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestS3Storage(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: map[string]fakeObject{}})
+	defer server.Close()
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "images",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+	})
+	require.NoError(t, err)
+	exerciseStorage(t, s)
+}
+
+func TestURLSigner_StableWithinWindow(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"), "/api/v1/media/", time.Hour)
+	now := time.Date(2025, 12, 20, 10, 5, 0, 0, time.UTC)
+	signer.now = func() time.Time { return now }
+
+	first := signer.URL("exercises/1/a.png")
+	now = now.Add(50 * time.Minute)
+	assert.Equal(t, first, signer.URL("exercises/1/a.png"))
+
+	parsed, err := url.Parse(first)
+	require.NoError(t, err)
+	expires := parsed.Query().Get("expires")
+	assert.NoError(t, signer.Verify("exercises/1/a.png", expires, parsed.Query().Get("sig")))
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, expiresAt, now.Add(time.Hour).Unix(), "valid for at least the TTL")
+
+	now = now.Add(10 * time.Minute)
+	assert.NotEqual(t, first, signer.URL("exercises/1/a.png"))
+}
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"), "/api/v1/media/", time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	signer.now = func() time.Time { return now }
+
+	signed := signer.URL("exercises/1/a.png")
+	assert.True(t, strings.HasPrefix(signed, "/api/v1/media/exercises/1/a.png?"))
+
+	expires := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+	sig := signer.sign("exercises/1/a.png", expires)
+	assert.NoError(t, signer.Verify("exercises/1/a.png", expires, sig))
+	assert.ErrorIs(t, signer.Verify("exercises/2/a.png", expires, sig), ErrInvalidSignature)
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, signer.Verify("exercises/1/a.png", expires, sig), ErrInvalidSignature)
+}
@@ -0,0 +1,20 @@
+package timeparse
+
+import "time"
+
+// Parse accepts either an RFC 3339 timestamp or a plain YYYY-MM-DD date.
+// When endOfDay is set, a plain date is moved to the start of the next day so
+// it can be used as an exclusive upper bound.
+func Parse(val string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, val)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
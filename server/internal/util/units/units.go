@@ -0,0 +1,56 @@
+// Package units converts weights between kilograms, the unit everything is
+// stored in, and the unit a user prefers to lift in.
+package units
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+type WeightUnit string
+
+const (
+	Kilograms WeightUnit = "kg"
+	Pounds    WeightUnit = "lb"
+)
+
+const poundsPerKilogram = 2.20462262185
+
+var ErrUnknownWeightUnit = errors.New("weight unit must be kg or lb")
+
+func ParseWeightUnit(name string) (WeightUnit, error) {
+	switch WeightUnit(name) {
+	case Kilograms, Pounds:
+		return WeightUnit(name), nil
+	default:
+		return "", ErrUnknownWeightUnit
+	}
+}
+
+// ToKilograms converts a weight entered in unit. The result keeps three
+// decimals, enough for a two-decimal pound value to read back unchanged.
+func ToKilograms(weight float64, unit WeightUnit) float64 {
+	if unit == Pounds {
+		weight /= poundsPerKilogram
+	}
+	return round(weight, 1000)
+}
+
+// FromKilograms converts a stored weight for display in unit, rounded to two
+// decimals.
+func FromKilograms(weight float64, unit WeightUnit) float64 {
+	if unit == Pounds {
+		weight *= poundsPerKilogram
+	}
+	return round(weight, 100)
+}
+
+func round(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
+
+// Resolver looks up the unit a user reads and writes weights in.
+type Resolver interface {
+	WeightUnit(ctx context.Context, userID int64) (WeightUnit, error)
+}
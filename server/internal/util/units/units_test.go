@@ -0,0 +1,32 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWeightUnit(t *testing.T) {
+	unit, err := ParseWeightUnit("lb")
+	assert.NoError(t, err)
+	assert.Equal(t, Pounds, unit)
+
+	_, err = ParseWeightUnit("stone")
+	assert.ErrorIs(t, err, ErrUnknownWeightUnit)
+}
+
+func TestToKilograms(t *testing.T) {
+	assert.Equal(t, 100.0, ToKilograms(100, Kilograms))
+	assert.Equal(t, 102.058, ToKilograms(225, Pounds))
+}
+
+func TestFromKilograms(t *testing.T) {
+	assert.Equal(t, 100.0, FromKilograms(100, Kilograms))
+	assert.Equal(t, 220.46, FromKilograms(100, Pounds))
+}
+
+func TestPoundsRoundTrip(t *testing.T) {
+	for _, lb := range []float64{2.5, 45, 135, 225, 315.25, 999.99} {
+		assert.Equal(t, lb, FromKilograms(ToKilograms(lb, Pounds), Pounds), "%v lb", lb)
+	}
+}
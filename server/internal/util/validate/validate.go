@@ -0,0 +1,179 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+)
+
+// Struct checks the `binding` tags of a decoded request and reports every
+// violation as a field error named after the JSON path, e.g.
+// "workouts[0].sets[1].reps". Nested structs and slices of structs are
+// checked too. Supported rules:
+//
+//	required     non-zero value, non-empty string or slice
+//	email        a bare email address
+//	min=N, max=N length of strings and slices, value of numbers
+//	oneof=a b c  one of the listed strings
+//
+// Nil pointers skip every rule except required.
+func Struct(v any) error {
+	var fields []apperror.FieldError
+	checkValue(reflect.ValueOf(v), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	return apperror.Validation("validation_failed", "request failed validation", fields...)
+}
+
+func checkValue(val reflect.Value, path string, fields *[]apperror.FieldError) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		checkStruct(val, path, fields)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			checkValue(val.Index(i), fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	}
+}
+
+func checkStruct(val reflect.Value, path string, fields *[]apperror.FieldError) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			// Embedded structs are flattened into the parent, as in encoding/json.
+			checkValue(val.Field(i), path, fields)
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		fieldVal := val.Field(i)
+		if tag := field.Tag.Get("binding"); tag != "" {
+			if fieldErr, ok := checkRules(fieldVal, name, tag); !ok {
+				*fields = append(*fields, fieldErr)
+				continue
+			}
+		}
+		checkValue(fieldVal, name, fields)
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// checkRules applies the rules of one tag and returns the first violation.
+func checkRules(val reflect.Value, name, tag string) (apperror.FieldError, bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		rule, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if rule == "required" {
+			if isEmpty(val) {
+				return apperror.Field(name, "required", "is required"), false
+			}
+			continue
+		}
+		if val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				return apperror.FieldError{}, true
+			}
+			val = val.Elem()
+		}
+		switch rule {
+		case "email":
+			if !isEmail(val.String()) {
+				return apperror.Field(name, "email", "must be a valid email address"), false
+			}
+		case "min", "max":
+			if fieldErr, ok := checkBound(val, name, rule, arg); !ok {
+				return fieldErr, false
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			if !slices.Contains(options, val.String()) {
+				return apperror.Field(name, "oneof", "must be one of: "+strings.Join(options, ", ")), false
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q on %s", rule, name))
+		}
+	}
+	return apperror.FieldError{}, true
+}
+
+func checkBound(val reflect.Value, name, rule, arg string) (apperror.FieldError, bool) {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: bad %s argument %q on %s", rule, arg, name))
+	}
+	var actual float64
+	var unit string
+	switch val.Kind() {
+	case reflect.String:
+		actual, unit = float64(utf8.RuneCountInString(val.String())), "character"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual, unit = float64(val.Len()), "item"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = val.Float()
+	default:
+		panic(fmt.Sprintf("validate: %s does not apply to %s", rule, name))
+	}
+	verb := "must be"
+	if unit != "" {
+		verb = "must have"
+		if limit != 1 {
+			unit += "s"
+		}
+		unit = " " + unit
+	}
+	if rule == "min" && actual < limit {
+		return apperror.Field(name, "min", fmt.Sprintf("%s at least %s%s", verb, arg, unit)), false
+	}
+	if rule == "max" && actual > limit {
+		return apperror.Field(name, "max", fmt.Sprintf("%s at most %s%s", verb, arg, unit)), false
+	}
+	return apperror.FieldError{}, true
+}
+
+func isEmpty(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(val.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return val.Len() == 0
+	default:
+		return val.IsZero()
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,73 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TBuckholz5/workouttracker/internal/util/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSet struct {
+	Reps    int     `json:"reps" binding:"min=0"`
+	SetType string  `json:"set_type" binding:"required,oneof=normal failure"`
+	Note    *string `json:"note" binding:"max=5"`
+}
+
+type testWorkout struct {
+	ExerciseID int64     `json:"exerciseID" binding:"required"`
+	Sets       []testSet `json:"sets"`
+}
+
+type testRequest struct {
+	Email    string        `json:"email" binding:"required,email"`
+	Password string        `json:"password" binding:"required,min=8"`
+	Workouts []testWorkout `json:"workouts" binding:"required,min=1"`
+}
+
+func fieldErrors(t *testing.T, err error) []apperror.FieldError {
+	t.Helper()
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("expected an apperror, got %v", err)
+	}
+	assert.Equal(t, apperror.KindValidation, appErr.Kind)
+	return appErr.Fields
+}
+
+func TestStruct_Valid(t *testing.T) {
+	assert.NoError(t, Struct(&testRequest{
+		Email:    "lifter@example.com",
+		Password: "correct horse",
+		Workouts: []testWorkout{{ExerciseID: 1, Sets: []testSet{{Reps: 0, SetType: "normal"}}}},
+	}))
+}
+
+func TestStruct_ReportsEveryField(t *testing.T) {
+	long := "too long"
+	err := Struct(&testRequest{
+		Email:    "Lifter <lifter@example.com>",
+		Password: "short",
+		Workouts: []testWorkout{
+			{ExerciseID: 1, Sets: []testSet{{SetType: "normal"}, {Reps: -1, SetType: "warmup", Note: &long}}},
+			{},
+		},
+	})
+
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "email", Code: "email", Message: "must be a valid email address"},
+		{Field: "password", Code: "min", Message: "must have at least 8 characters"},
+		{Field: "workouts[0].sets[1].reps", Code: "min", Message: "must be at least 0"},
+		{Field: "workouts[0].sets[1].set_type", Code: "oneof", Message: "must be one of: normal, failure"},
+		{Field: "workouts[0].sets[1].note", Code: "max", Message: "must have at most 5 characters"},
+		{Field: "workouts[1].exerciseID", Code: "required", Message: "is required"},
+	}, fieldErrors(t, err))
+}
+
+func TestStruct_RequiredSlice(t *testing.T) {
+	err := Struct(&testRequest{Email: "lifter@example.com", Password: "correct horse"})
+
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "workouts", Code: "required", Message: "is required"},
+	}, fieldErrors(t, err))
+}
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE workouts
+DROP CONSTRAINT workouts_session_id_fkey,
+ADD CONSTRAINT workouts_session_id_fkey FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE workouts
+DROP CONSTRAINT workouts_session_id_fkey,
+ADD CONSTRAINT workouts_session_id_fkey FOREIGN KEY (session_id) REFERENCES sessions(id);
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE routines (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE routine_exercises (
+    id BIGSERIAL PRIMARY KEY,
+    routine_id BIGINT NOT NULL REFERENCES routines(id) ON DELETE CASCADE,
+    exercise_id BIGINT NOT NULL REFERENCES exercises(id),
+    description TEXT,
+    position INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE routine_sets (
+    id BIGSERIAL PRIMARY KEY,
+    routine_exercise_id BIGINT NOT NULL REFERENCES routine_exercises(id) ON DELETE CASCADE,
+    target_reps INT NOT NULL,
+    target_weight DECIMAL(6,2),
+    set_type set_type NOT NULL DEFAULT 'normal',
+    set_order INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX routines_user_id_idx ON routines (user_id);
+
+-- +goose Down
+DROP TABLE routine_sets;
+DROP TABLE routine_exercises;
+DROP TABLE routines;
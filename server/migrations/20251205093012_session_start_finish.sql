@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE sessions
+ADD COLUMN started_at TIMESTAMP,
+ADD COLUMN finished_at TIMESTAMP;
+
+UPDATE sessions
+SET started_at = created_at - (COALESCE(duration, 0) * INTERVAL '1 second'),
+    finished_at = created_at;
+
+ALTER TABLE sessions
+ALTER COLUMN started_at SET NOT NULL,
+ALTER COLUMN started_at SET DEFAULT NOW();
+
+-- A session is in progress until finished_at is set; each user may only have one.
+CREATE UNIQUE INDEX sessions_one_active_per_user_idx ON sessions (user_id) WHERE finished_at IS NULL;
+
+-- +goose Down
+DROP INDEX sessions_one_active_per_user_idx;
+
+ALTER TABLE sessions
+DROP COLUMN started_at,
+DROP COLUMN finished_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE personal_records (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id BIGINT NOT NULL REFERENCES exercises(id),
+    workout_set_id BIGINT NOT NULL REFERENCES workout_sets(id) ON DELETE CASCADE,
+    record_type TEXT NOT NULL CHECK (record_type IN ('max_weight', 'reps_at_weight', 'estimated_1rm', 'set_volume')),
+    value DECIMAL(10,2) NOT NULL,
+    weight DECIMAL(6,2) NOT NULL,
+    reps INT NOT NULL,
+    achieved_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX personal_records_user_exercise_idx ON personal_records (user_id, exercise_id, achieved_at);
+
+-- +goose Down
+DROP TABLE personal_records;
@@ -0,0 +1,324 @@
+-- +goose Up
+ALTER TABLE exercises
+ADD COLUMN equipment TEXT;
+
+-- Exercises without an owner form the shared catalog visible to every user.
+CREATE UNIQUE INDEX exercises_global_name_idx ON exercises (lower(name)) WHERE user_id IS NULL;
+
+INSERT INTO exercises (name, target_muscle, equipment, description, picture_url)
+SELECT name, target_muscle, equipment, '', ''
+FROM (VALUES
+    ('Barbell Bench Press', 'Chest', 'barbell'),
+    ('Dumbbell Bench Press', 'Chest', 'dumbbell'),
+    ('Smith Machine Bench Press', 'Chest', 'smith machine'),
+    ('Incline Barbell Bench Press', 'Chest', 'barbell'),
+    ('Incline Dumbbell Bench Press', 'Chest', 'dumbbell'),
+    ('Incline Smith Machine Bench Press', 'Chest', 'smith machine'),
+    ('Decline Barbell Bench Press', 'Chest', 'barbell'),
+    ('Decline Dumbbell Bench Press', 'Chest', 'dumbbell'),
+    ('Close-Grip Barbell Bench Press', 'Chest', 'barbell'),
+    ('Paused Barbell Bench Press', 'Chest', 'barbell'),
+    ('Floor Press', 'Chest', 'barbell'),
+    ('Dumbbell Floor Press', 'Chest', 'dumbbell'),
+    ('Machine Chest Press', 'Chest', 'machine'),
+    ('Incline Machine Chest Press', 'Chest', 'machine'),
+    ('Hammer Strength Chest Press', 'Chest', 'machine'),
+    ('Dumbbell Fly', 'Chest', 'dumbbell'),
+    ('Incline Dumbbell Fly', 'Chest', 'dumbbell'),
+    ('Cable Fly', 'Chest', 'cable'),
+    ('Low-to-High Cable Fly', 'Chest', 'cable'),
+    ('High-to-Low Cable Fly', 'Chest', 'cable'),
+    ('Pec Deck', 'Chest', 'machine'),
+    ('Push-Up', 'Chest', 'bodyweight'),
+    ('Incline Push-Up', 'Chest', 'bodyweight'),
+    ('Decline Push-Up', 'Chest', 'bodyweight'),
+    ('Weighted Push-Up', 'Chest', 'bodyweight'),
+    ('Chest Dip', 'Chest', 'bodyweight'),
+    ('Dumbbell Pullover', 'Chest', 'dumbbell'),
+    ('Svend Press', 'Chest', 'other'),
+    ('Landmine Press', 'Chest', 'barbell'),
+    ('Cable Crossover', 'Chest', 'cable'),
+    ('Barbell Row', 'Back', 'barbell'),
+    ('Pendlay Row', 'Back', 'barbell'),
+    ('Dumbbell Row', 'Back', 'dumbbell'),
+    ('Chest-Supported Dumbbell Row', 'Back', 'dumbbell'),
+    ('T-Bar Row', 'Back', 'barbell'),
+    ('Seal Row', 'Back', 'barbell'),
+    ('Seated Cable Row', 'Back', 'cable'),
+    ('Wide-Grip Seated Cable Row', 'Back', 'cable'),
+    ('Single-Arm Cable Row', 'Back', 'cable'),
+    ('Machine Row', 'Back', 'machine'),
+    ('Chest-Supported Machine Row', 'Back', 'machine'),
+    ('Inverted Row', 'Back', 'bodyweight'),
+    ('Pull-Up', 'Back', 'bodyweight'),
+    ('Weighted Pull-Up', 'Back', 'bodyweight'),
+    ('Chin-Up', 'Back', 'bodyweight'),
+    ('Weighted Chin-Up', 'Back', 'bodyweight'),
+    ('Neutral-Grip Pull-Up', 'Back', 'bodyweight'),
+    ('Assisted Pull-Up', 'Back', 'machine'),
+    ('Lat Pulldown', 'Back', 'cable'),
+    ('Close-Grip Lat Pulldown', 'Back', 'cable'),
+    ('Neutral-Grip Lat Pulldown', 'Back', 'cable'),
+    ('Single-Arm Lat Pulldown', 'Back', 'cable'),
+    ('Straight-Arm Pulldown', 'Back', 'cable'),
+    ('Machine Pullover', 'Back', 'machine'),
+    ('Meadows Row', 'Back', 'barbell'),
+    ('Kroc Row', 'Back', 'dumbbell'),
+    ('Rack Pull', 'Back', 'barbell'),
+    ('Band Pull-Apart', 'Back', 'band'),
+    ('Kettlebell Row', 'Back', 'kettlebell'),
+    ('Renegade Row', 'Back', 'dumbbell'),
+    ('Overhead Press', 'Shoulders', 'barbell'),
+    ('Seated Barbell Overhead Press', 'Shoulders', 'barbell'),
+    ('Dumbbell Shoulder Press', 'Shoulders', 'dumbbell'),
+    ('Seated Dumbbell Shoulder Press', 'Shoulders', 'dumbbell'),
+    ('Arnold Press', 'Shoulders', 'dumbbell'),
+    ('Machine Shoulder Press', 'Shoulders', 'machine'),
+    ('Smith Machine Shoulder Press', 'Shoulders', 'smith machine'),
+    ('Push Press', 'Shoulders', 'barbell'),
+    ('Behind-the-Neck Press', 'Shoulders', 'barbell'),
+    ('Kettlebell Overhead Press', 'Shoulders', 'kettlebell'),
+    ('Dumbbell Lateral Raise', 'Shoulders', 'dumbbell'),
+    ('Cable Lateral Raise', 'Shoulders', 'cable'),
+    ('Machine Lateral Raise', 'Shoulders', 'machine'),
+    ('Lean-Away Lateral Raise', 'Shoulders', 'dumbbell'),
+    ('Dumbbell Front Raise', 'Shoulders', 'dumbbell'),
+    ('Cable Front Raise', 'Shoulders', 'cable'),
+    ('Plate Front Raise', 'Shoulders', 'other'),
+    ('Barbell Front Raise', 'Shoulders', 'barbell'),
+    ('Reverse Pec Deck', 'Shoulders', 'machine'),
+    ('Dumbbell Rear Delt Fly', 'Shoulders', 'dumbbell'),
+    ('Cable Rear Delt Fly', 'Shoulders', 'cable'),
+    ('Face Pull', 'Shoulders', 'cable'),
+    ('Band Face Pull', 'Shoulders', 'band'),
+    ('Upright Row', 'Shoulders', 'barbell'),
+    ('Cable Upright Row', 'Shoulders', 'cable'),
+    ('Dumbbell Upright Row', 'Shoulders', 'dumbbell'),
+    ('Cuban Press', 'Shoulders', 'dumbbell'),
+    ('Z Press', 'Shoulders', 'barbell'),
+    ('Handstand Push-Up', 'Shoulders', 'bodyweight'),
+    ('Pike Push-Up', 'Shoulders', 'bodyweight'),
+    ('Barbell Curl', 'Biceps', 'barbell'),
+    ('EZ-Bar Curl', 'Biceps', 'ez bar'),
+    ('Dumbbell Curl', 'Biceps', 'dumbbell'),
+    ('Alternating Dumbbell Curl', 'Biceps', 'dumbbell'),
+    ('Hammer Curl', 'Biceps', 'dumbbell'),
+    ('Cross-Body Hammer Curl', 'Biceps', 'dumbbell'),
+    ('Incline Dumbbell Curl', 'Biceps', 'dumbbell'),
+    ('Preacher Curl', 'Biceps', 'ez bar'),
+    ('Dumbbell Preacher Curl', 'Biceps', 'dumbbell'),
+    ('Machine Preacher Curl', 'Biceps', 'machine'),
+    ('Concentration Curl', 'Biceps', 'dumbbell'),
+    ('Cable Curl', 'Biceps', 'cable'),
+    ('Rope Hammer Curl', 'Biceps', 'cable'),
+    ('Bayesian Cable Curl', 'Biceps', 'cable'),
+    ('Spider Curl', 'Biceps', 'dumbbell'),
+    ('Drag Curl', 'Biceps', 'barbell'),
+    ('Reverse Barbell Curl', 'Biceps', 'barbell'),
+    ('Zottman Curl', 'Biceps', 'dumbbell'),
+    ('Machine Bicep Curl', 'Biceps', 'machine'),
+    ('Band Curl', 'Biceps', 'band'),
+    ('Tricep Pushdown', 'Triceps', 'cable'),
+    ('Rope Tricep Pushdown', 'Triceps', 'cable'),
+    ('Single-Arm Cable Pushdown', 'Triceps', 'cable'),
+    ('Overhead Cable Tricep Extension', 'Triceps', 'cable'),
+    ('Overhead Dumbbell Tricep Extension', 'Triceps', 'dumbbell'),
+    ('Skull Crusher', 'Triceps', 'ez bar'),
+    ('Dumbbell Skull Crusher', 'Triceps', 'dumbbell'),
+    ('JM Press', 'Triceps', 'barbell'),
+    ('Tricep Dip', 'Triceps', 'bodyweight'),
+    ('Bench Dip', 'Triceps', 'bodyweight'),
+    ('Weighted Dip', 'Triceps', 'bodyweight'),
+    ('Machine Dip', 'Triceps', 'machine'),
+    ('Tricep Kickback', 'Triceps', 'dumbbell'),
+    ('Cable Kickback', 'Triceps', 'cable'),
+    ('Diamond Push-Up', 'Triceps', 'bodyweight'),
+    ('Machine Tricep Extension', 'Triceps', 'machine'),
+    ('Tate Press', 'Triceps', 'dumbbell'),
+    ('Board Press', 'Triceps', 'barbell'),
+    ('Barbell Wrist Curl', 'Forearms', 'barbell'),
+    ('Dumbbell Wrist Curl', 'Forearms', 'dumbbell'),
+    ('Reverse Wrist Curl', 'Forearms', 'barbell'),
+    ('Behind-the-Back Wrist Curl', 'Forearms', 'barbell'),
+    ('Farmer''s Carry', 'Forearms', 'dumbbell'),
+    ('Plate Pinch', 'Forearms', 'other'),
+    ('Dead Hang', 'Forearms', 'bodyweight'),
+    ('Wrist Roller', 'Forearms', 'other'),
+    ('Back Squat', 'Quadriceps', 'barbell'),
+    ('High-Bar Back Squat', 'Quadriceps', 'barbell'),
+    ('Low-Bar Back Squat', 'Quadriceps', 'barbell'),
+    ('Front Squat', 'Quadriceps', 'barbell'),
+    ('Paused Back Squat', 'Quadriceps', 'barbell'),
+    ('Box Squat', 'Quadriceps', 'barbell'),
+    ('Safety Bar Squat', 'Quadriceps', 'barbell'),
+    ('Zercher Squat', 'Quadriceps', 'barbell'),
+    ('Goblet Squat', 'Quadriceps', 'dumbbell'),
+    ('Kettlebell Goblet Squat', 'Quadriceps', 'kettlebell'),
+    ('Smith Machine Squat', 'Quadriceps', 'smith machine'),
+    ('Hack Squat', 'Quadriceps', 'machine'),
+    ('Pendulum Squat', 'Quadriceps', 'machine'),
+    ('Belt Squat', 'Quadriceps', 'machine'),
+    ('Leg Press', 'Quadriceps', 'machine'),
+    ('Single-Leg Leg Press', 'Quadriceps', 'machine'),
+    ('Leg Extension', 'Quadriceps', 'machine'),
+    ('Single-Leg Leg Extension', 'Quadriceps', 'machine'),
+    ('Bulgarian Split Squat', 'Quadriceps', 'dumbbell'),
+    ('Barbell Bulgarian Split Squat', 'Quadriceps', 'barbell'),
+    ('Walking Lunge', 'Quadriceps', 'dumbbell'),
+    ('Barbell Walking Lunge', 'Quadriceps', 'barbell'),
+    ('Reverse Lunge', 'Quadriceps', 'dumbbell'),
+    ('Forward Lunge', 'Quadriceps', 'dumbbell'),
+    ('Step-Up', 'Quadriceps', 'dumbbell'),
+    ('Sissy Squat', 'Quadriceps', 'bodyweight'),
+    ('Bodyweight Squat', 'Quadriceps', 'bodyweight'),
+    ('Pistol Squat', 'Quadriceps', 'bodyweight'),
+    ('Overhead Squat', 'Quadriceps', 'barbell'),
+    ('Heel-Elevated Goblet Squat', 'Quadriceps', 'dumbbell'),
+    ('Spanish Squat', 'Quadriceps', 'band'),
+    ('Wall Sit', 'Quadriceps', 'bodyweight'),
+    ('Romanian Deadlift', 'Hamstrings', 'barbell'),
+    ('Dumbbell Romanian Deadlift', 'Hamstrings', 'dumbbell'),
+    ('Single-Leg Romanian Deadlift', 'Hamstrings', 'dumbbell'),
+    ('Stiff-Leg Deadlift', 'Hamstrings', 'barbell'),
+    ('Good Morning', 'Hamstrings', 'barbell'),
+    ('Seated Leg Curl', 'Hamstrings', 'machine'),
+    ('Lying Leg Curl', 'Hamstrings', 'machine'),
+    ('Standing Single-Leg Curl', 'Hamstrings', 'machine'),
+    ('Nordic Hamstring Curl', 'Hamstrings', 'bodyweight'),
+    ('Glute-Ham Raise', 'Hamstrings', 'bodyweight'),
+    ('Stability Ball Leg Curl', 'Hamstrings', 'other'),
+    ('Cable Pull-Through', 'Hamstrings', 'cable'),
+    ('Kettlebell Swing', 'Hamstrings', 'kettlebell'),
+    ('Slider Leg Curl', 'Hamstrings', 'other'),
+    ('Barbell Hip Thrust', 'Glutes', 'barbell'),
+    ('Machine Hip Thrust', 'Glutes', 'machine'),
+    ('Single-Leg Hip Thrust', 'Glutes', 'bodyweight'),
+    ('Glute Bridge', 'Glutes', 'bodyweight'),
+    ('Barbell Glute Bridge', 'Glutes', 'barbell'),
+    ('Cable Glute Kickback', 'Glutes', 'cable'),
+    ('Machine Glute Kickback', 'Glutes', 'machine'),
+    ('Hip Abduction Machine', 'Glutes', 'machine'),
+    ('Banded Lateral Walk', 'Glutes', 'band'),
+    ('Sumo Deadlift', 'Glutes', 'barbell'),
+    ('Frog Pump', 'Glutes', 'bodyweight'),
+    ('Curtsy Lunge', 'Glutes', 'dumbbell'),
+    ('Kas Glute Bridge', 'Glutes', 'barbell'),
+    ('Reverse Hyperextension', 'Glutes', 'machine'),
+    ('B-Stance Hip Thrust', 'Glutes', 'barbell'),
+    ('Cable Hip Abduction', 'Glutes', 'cable'),
+    ('Hip Adduction Machine', 'Adductors', 'machine'),
+    ('Copenhagen Plank', 'Adductors', 'bodyweight'),
+    ('Cable Hip Adduction', 'Adductors', 'cable'),
+    ('Cossack Squat', 'Adductors', 'bodyweight'),
+    ('Sumo Squat', 'Adductors', 'dumbbell'),
+    ('Standing Calf Raise', 'Calves', 'machine'),
+    ('Seated Calf Raise', 'Calves', 'machine'),
+    ('Smith Machine Calf Raise', 'Calves', 'smith machine'),
+    ('Leg Press Calf Raise', 'Calves', 'machine'),
+    ('Single-Leg Dumbbell Calf Raise', 'Calves', 'dumbbell'),
+    ('Donkey Calf Raise', 'Calves', 'machine'),
+    ('Bodyweight Calf Raise', 'Calves', 'bodyweight'),
+    ('Tibialis Raise', 'Calves', 'other'),
+    ('Conventional Deadlift', 'Lower Back', 'barbell'),
+    ('Deficit Deadlift', 'Lower Back', 'barbell'),
+    ('Paused Deadlift', 'Lower Back', 'barbell'),
+    ('Trap Bar Deadlift', 'Lower Back', 'trap bar'),
+    ('Back Extension', 'Lower Back', 'bodyweight'),
+    ('Weighted Back Extension', 'Lower Back', 'other'),
+    ('45-Degree Back Extension', 'Lower Back', 'bodyweight'),
+    ('Superman', 'Lower Back', 'bodyweight'),
+    ('Jefferson Curl', 'Lower Back', 'barbell'),
+    ('Block Pull', 'Lower Back', 'barbell'),
+    ('Barbell Shrug', 'Traps', 'barbell'),
+    ('Dumbbell Shrug', 'Traps', 'dumbbell'),
+    ('Trap Bar Shrug', 'Traps', 'trap bar'),
+    ('Smith Machine Shrug', 'Traps', 'smith machine'),
+    ('Cable Shrug', 'Traps', 'cable'),
+    ('Machine Shrug', 'Traps', 'machine'),
+    ('Snatch-Grip High Pull', 'Traps', 'barbell'),
+    ('Y-Raise', 'Traps', 'dumbbell'),
+    ('Crunch', 'Abs', 'bodyweight'),
+    ('Cable Crunch', 'Abs', 'cable'),
+    ('Machine Crunch', 'Abs', 'machine'),
+    ('Decline Crunch', 'Abs', 'bodyweight'),
+    ('Reverse Crunch', 'Abs', 'bodyweight'),
+    ('Hanging Leg Raise', 'Abs', 'bodyweight'),
+    ('Hanging Knee Raise', 'Abs', 'bodyweight'),
+    ('Captain''s Chair Leg Raise', 'Abs', 'other'),
+    ('Lying Leg Raise', 'Abs', 'bodyweight'),
+    ('Ab Wheel Rollout', 'Abs', 'other'),
+    ('Barbell Rollout', 'Abs', 'barbell'),
+    ('Plank', 'Abs', 'bodyweight'),
+    ('Weighted Plank', 'Abs', 'bodyweight'),
+    ('Dead Bug', 'Abs', 'bodyweight'),
+    ('Hollow Body Hold', 'Abs', 'bodyweight'),
+    ('V-Up', 'Abs', 'bodyweight'),
+    ('Sit-Up', 'Abs', 'bodyweight'),
+    ('Toes-to-Bar', 'Abs', 'bodyweight'),
+    ('Dragon Flag', 'Abs', 'bodyweight'),
+    ('L-Sit', 'Abs', 'bodyweight'),
+    ('Stir the Pot', 'Abs', 'other'),
+    ('Mountain Climber', 'Abs', 'bodyweight'),
+    ('Russian Twist', 'Obliques', 'bodyweight'),
+    ('Side Plank', 'Obliques', 'bodyweight'),
+    ('Cable Woodchopper', 'Obliques', 'cable'),
+    ('Pallof Press', 'Obliques', 'cable'),
+    ('Dumbbell Side Bend', 'Obliques', 'dumbbell'),
+    ('Landmine Rotation', 'Obliques', 'barbell'),
+    ('Bicycle Crunch', 'Obliques', 'bodyweight'),
+    ('Suitcase Carry', 'Obliques', 'dumbbell'),
+    ('Windshield Wiper', 'Obliques', 'bodyweight'),
+    ('Oblique Crunch', 'Obliques', 'bodyweight'),
+    ('Hanging Knee Tuck', 'Hip Flexors', 'bodyweight'),
+    ('Cable Hip Flexion', 'Hip Flexors', 'cable'),
+    ('Psoas March', 'Hip Flexors', 'band'),
+    ('Power Clean', 'Full Body', 'barbell'),
+    ('Hang Clean', 'Full Body', 'barbell'),
+    ('Clean and Jerk', 'Full Body', 'barbell'),
+    ('Snatch', 'Full Body', 'barbell'),
+    ('Hang Snatch', 'Full Body', 'barbell'),
+    ('Power Snatch', 'Full Body', 'barbell'),
+    ('Clean Pull', 'Full Body', 'barbell'),
+    ('Thruster', 'Full Body', 'barbell'),
+    ('Dumbbell Thruster', 'Full Body', 'dumbbell'),
+    ('Kettlebell Clean', 'Full Body', 'kettlebell'),
+    ('Kettlebell Snatch', 'Full Body', 'kettlebell'),
+    ('Turkish Get-Up', 'Full Body', 'kettlebell'),
+    ('Burpee', 'Full Body', 'bodyweight'),
+    ('Man Maker', 'Full Body', 'dumbbell'),
+    ('Sled Push', 'Full Body', 'other'),
+    ('Sled Pull', 'Full Body', 'other'),
+    ('Tire Flip', 'Full Body', 'other'),
+    ('Sandbag Carry', 'Full Body', 'other'),
+    ('Atlas Stone Lift', 'Full Body', 'other'),
+    ('Dumbbell Snatch', 'Full Body', 'dumbbell'),
+    ('Wall Ball', 'Full Body', 'other'),
+    ('Medicine Ball Slam', 'Full Body', 'other'),
+    ('Battle Ropes', 'Full Body', 'other'),
+    ('Yoke Carry', 'Full Body', 'other'),
+    ('Log Press', 'Full Body', 'other'),
+    ('Treadmill Run', 'Cardio', 'machine'),
+    ('Outdoor Run', 'Cardio', 'bodyweight'),
+    ('Rowing Machine', 'Cardio', 'machine'),
+    ('Stationary Bike', 'Cardio', 'machine'),
+    ('Assault Bike', 'Cardio', 'machine'),
+    ('Elliptical', 'Cardio', 'machine'),
+    ('Stair Climber', 'Cardio', 'machine'),
+    ('Jump Rope', 'Cardio', 'other'),
+    ('Ski Erg', 'Cardio', 'machine'),
+    ('Box Jump', 'Cardio', 'bodyweight'),
+    ('Jumping Jack', 'Cardio', 'bodyweight'),
+    ('Swimming', 'Cardio', 'other')
+) AS catalog (name, target_muscle, equipment);
+
+-- +goose Down
+DELETE FROM exercises e
+WHERE e.user_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM workouts w WHERE w.exercise_id = e.id)
+    AND NOT EXISTS (SELECT 1 FROM routine_exercises re WHERE re.exercise_id = e.id)
+    AND NOT EXISTS (SELECT 1 FROM personal_records pr WHERE pr.exercise_id = e.id);
+
+DROP INDEX exercises_global_name_idx;
+
+ALTER TABLE exercises
+DROP COLUMN equipment;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX exercises_name_trgm_idx ON exercises USING GIN (name gin_trgm_ops);
+CREATE INDEX exercises_user_id_idx ON exercises (user_id);
+CREATE INDEX workouts_exercise_id_idx ON workouts (exercise_id);
+
+-- +goose Down
+DROP INDEX workouts_exercise_id_idx;
+DROP INDEX exercises_user_id_idx;
+DROP INDEX exercises_name_trgm_idx;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE exercises ADD COLUMN archived_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE exercises DROP COLUMN archived_at;
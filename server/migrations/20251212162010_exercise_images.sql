@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE exercises
+    ADD COLUMN image_key TEXT,
+    ADD COLUMN thumbnail_key TEXT;
+
+-- +goose Down
+ALTER TABLE exercises
+    DROP COLUMN thumbnail_key,
+    DROP COLUMN image_key;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Failed login counters, keyed by username or client IP.
+CREATE TABLE login_failures (
+    scope TEXT NOT NULL CHECK (scope IN ('username', 'ip')),
+    key TEXT NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+-- +goose Down
+DROP TABLE login_failures;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Weights stay in kilograms; the preferred unit is only applied at the API.
+-- Bodyweight is in kilograms too. week_start uses 0 for Sunday.
+CREATE TABLE user_preferences (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    weight_unit TEXT NOT NULL DEFAULT 'kg' CHECK (weight_unit IN ('kg', 'lb')),
+    bodyweight DECIMAL(8,3) CHECK (bodyweight > 0),
+    default_rest_seconds INT NOT NULL DEFAULT 90 CHECK (default_rest_seconds BETWEEN 0 AND 3600),
+    week_start SMALLINT NOT NULL DEFAULT 1 CHECK (week_start BETWEEN 0 AND 6),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- A third decimal lets pound values survive the round trip through kilograms.
+ALTER TABLE workout_sets ALTER COLUMN weight TYPE DECIMAL(8,3);
+ALTER TABLE routine_sets ALTER COLUMN target_weight TYPE DECIMAL(8,3);
+ALTER TABLE personal_records
+    ALTER COLUMN weight TYPE DECIMAL(8,3),
+    ALTER COLUMN value TYPE DECIMAL(12,3);
+
+-- +goose Down
+ALTER TABLE personal_records
+    ALTER COLUMN weight TYPE DECIMAL(6,2),
+    ALTER COLUMN value TYPE DECIMAL(10,2);
+ALTER TABLE routine_sets ALTER COLUMN target_weight TYPE DECIMAL(6,2);
+ALTER TABLE workout_sets ALTER COLUMN weight TYPE DECIMAL(6,2);
+
+DROP TABLE user_preferences;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Weight is stored in kilograms, body fat in percent and circumferences in
+-- centimetres. body_part is only set for circumferences.
+CREATE TABLE measurements (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('weight', 'body_fat', 'circumference')),
+    body_part TEXT,
+    value DECIMAL(8,3) NOT NULL CHECK (value > 0),
+    measured_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((type = 'circumference') = (body_part IS NOT NULL))
+);
+
+CREATE INDEX measurements_user_type_measured_at_idx ON measurements (user_id, type, body_part, measured_at);
+
+-- bodyweight_on returns the latest weight logged on or before the given day,
+-- so queries can relate a session to the user's bodyweight at the time, e.g.
+-- bodyweight_on(s.user_id, s.started_at::date).
+-- +goose StatementBegin
+CREATE FUNCTION bodyweight_on(p_user_id BIGINT, p_day DATE) RETURNS DECIMAL AS $$
+    SELECT value
+    FROM measurements
+    WHERE user_id = p_user_id
+        AND type = 'weight'
+        AND measured_at < p_day + 1
+    ORDER BY measured_at DESC
+    LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION bodyweight_on(BIGINT, DATE);
+DROP TABLE measurements;
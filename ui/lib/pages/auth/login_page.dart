@@ -2,7 +2,6 @@ import 'package:flutter/material.dart';
 import 'dart:async';
 import '../main_tabs.dart';
 import '../../env.dart';
-import 'package:flutter_secure_storage/flutter_secure_storage.dart';
 import '../../utils/api.dart' as api;
 
 class LoginPage extends StatefulWidget {
@@ -17,22 +16,33 @@ class _LoginPageState extends State<LoginPage> {
   final _usernameController = TextEditingController();
   final _passwordController = TextEditingController();
   final apiUrl = Env.instance.apiUserUrl;
-  final storage = FlutterSecureStorage();
 
   bool _isLoading = false;
   String? _errorMessage;
 
   Future<bool> _authenticate(String username, String password) async {
     try {
-      final response = await api.sendPostRequest('$apiUrl/login', {
+      var response = await api.sendPostRequest('$apiUrl/login', {
         'username': username,
         'password': password,
       });
+      // Accounts with two-factor authentication first get a challenge that
+      // is exchanged for tokens together with a code.
+      if (response['twoFactorRequired'] == true) {
+        final code = await _askTwoFactorCode();
+        if (code == null || code.isEmpty) {
+          return false;
+        }
+        response = await api.sendPostRequest('$apiUrl/login/2fa', {
+          'challengeToken': response['challengeToken'],
+          'code': code,
+        });
+      }
       if (!response.containsKey('token')) {
         return false;
       }
-      // Store the token into secure storage.
-      await storage.write(key: 'auth_token', value: response['token']);
+      // Store the tokens into secure storage.
+      await api.storeTokens(response);
 
       return true;
     } catch (e) {
@@ -40,6 +50,34 @@ class _LoginPageState extends State<LoginPage> {
     }
   }
 
+  Future<String?> _askTwoFactorCode() {
+    final controller = TextEditingController();
+    return showDialog<String>(
+      context: context,
+      barrierDismissible: false,
+      builder: (context) => AlertDialog(
+        title: const Text('Two-factor authentication'),
+        content: TextField(
+          controller: controller,
+          autofocus: true,
+          decoration: const InputDecoration(
+            labelText: 'Authenticator or recovery code',
+          ),
+        ),
+        actions: [
+          TextButton(
+            onPressed: () => Navigator.pop(context),
+            child: const Text('Cancel'),
+          ),
+          TextButton(
+            onPressed: () => Navigator.pop(context, controller.text.trim()),
+            child: const Text('Verify'),
+          ),
+        ],
+      ),
+    ).whenComplete(controller.dispose);
+  }
+
   Future<void> _login() async {
     if (_formKey.currentState!.validate()) {
       setState(() {
@@ -4,22 +4,69 @@ import 'dart:core';
 import 'package:flutter_secure_storage/flutter_secure_storage.dart';
 import 'package:http/http.dart' as http;
 
+import '../env.dart';
+
 final secureStorage = FlutterSecureStorage();
 
+Future<void> storeTokens(Map<String, dynamic> response) async {
+  await secureStorage.write(key: 'auth_token', value: response['token']);
+  await secureStorage.write(
+    key: 'refresh_token',
+    value: response['refreshToken'],
+  );
+}
+
+// Exchanges the stored refresh token for a new token pair. Returns false when
+// the session can no longer be renewed and the user has to log in again.
+Future<bool> _refreshTokens() async {
+  String? refreshToken = await secureStorage.read(key: 'refresh_token');
+  if (refreshToken == null) {
+    return false;
+  }
+  final response = await http.post(
+    Uri.parse('${Env.instance.apiUserUrl}/refresh'),
+    headers: {'Content-Type': 'application/json'},
+    body: jsonEncode({'refreshToken': refreshToken}),
+  );
+  if (response.statusCode != 200) {
+    await secureStorage.delete(key: 'auth_token');
+    await secureStorage.delete(key: 'refresh_token');
+    return false;
+  }
+  await storeTokens(jsonDecode(response.body));
+  return true;
+}
+
+// Sends an authenticated request, renewing the access token once if it expired.
+Future<http.Response> _sendProtected(
+  Future<http.Response> Function(Map<String, String> headers) send,
+) async {
+  Future<http.Response> attempt() async {
+    String? token = await secureStorage.read(key: 'auth_token');
+    if (token == null) {
+      throw Exception('No auth token found in secure storage');
+    }
+    return send({
+      "Authorization": "Bearer $token",
+      "Content-Type": "application/json",
+    });
+  }
+
+  final response = await attempt();
+  if (response.statusCode == 401 && await _refreshTokens()) {
+    return attempt();
+  }
+  return response;
+}
+
 Future<Map<String, dynamic>> sendProtectedGetRequest(
   String url,
   Map<String, String> queryParams,
 ) async {
-  String? token = await secureStorage.read(key: 'auth_token');
-  if (token == null) {
-    throw Exception('No auth token found in secure storage');
-  }
-  Map<String, String> headers = {
-    "Authorization": "Bearer $token",
-    "Content-Type": "application/json",
-  };
   final uri = Uri.parse(url).replace(queryParameters: queryParams);
-  final response = await http.get(uri, headers: headers);
+  final response = await _sendProtected(
+    (headers) => http.get(uri, headers: headers),
+  );
   if (response.statusCode != 200) {
     throw Exception('Failed to get data: ${response.statusCode}');
   }
@@ -30,18 +77,9 @@ Future<Map<String, dynamic>> sendProtectedPostRequest(
   String url,
   Map<String, dynamic> payload,
 ) async {
-  String? token = await secureStorage.read(key: 'auth_token');
-  if (token == null) {
-    throw Exception('No auth token found in secure storage');
-  }
-  Map<String, String> headers = {
-    "Authorization": "Bearer $token",
-    "Content-Type": "application/json",
-  };
-  final response = await http.post(
-    Uri.parse(url),
-    headers: headers,
-    body: jsonEncode(payload),
+  final response = await _sendProtected(
+    (headers) =>
+        http.post(Uri.parse(url), headers: headers, body: jsonEncode(payload)),
   );
   if (response.statusCode != 200) {
     throw Exception('Failed to get data: ${response.statusCode}');